@@ -0,0 +1,155 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// RedisTLSConfig is the TLS configuration to reach a redis instance over
+// TCP. It is read from the database_config.json INSTANCES, and can be
+// overridden by the go_redis_opts. Shared by translib/db, and CVL.
+type RedisTLSConfig struct {
+	Enabled            bool
+	CACertPath         string // PEM CA bundle. Empty == system roots
+	CertPath           string // PEM client certificate (mutual TLS)
+	KeyPath            string // PEM client private key (mutual TLS)
+	ServerName         string // Empty == hostname of the instance
+	InsecureSkipVerify bool
+}
+
+// redisTLSConfigCache holds the *tls.Config built for a RedisTLSConfig, to
+// avoid reading the certificate files on every redis client.
+var redisTLSConfigCache = make(map[RedisTLSConfig]*tls.Config)
+
+// redisTLSOverride is the go_redis_opts TLS override.
+var redisTLSOverride RedisTLSConfig
+
+var mutexRedisTLSConfig sync.Mutex
+
+// RedisTLSConfigOf returns the RedisTLSConfig from the "tls_*" fields of a
+// database_config.json instance. TLS is enabled by "tls_enabled": true.
+func RedisTLSConfigOf(inst map[string]interface{}) RedisTLSConfig {
+	var config RedisTLSConfig
+	config.Enabled, _ = inst["tls_enabled"].(bool)
+	config.CACertPath, _ = inst["tls_ca_cert_path"].(string)
+	config.CertPath, _ = inst["tls_cert_path"].(string)
+	config.KeyPath, _ = inst["tls_key_path"].(string)
+	config.ServerName, _ = inst["tls_server_name"].(string)
+	config.InsecureSkipVerify, _ = inst["tls_insecure_skip_verify"].(bool)
+	return config
+}
+
+// SetRedisTLSOverride sets the go_redis_opts TLS override.
+func SetRedisTLSOverride(override RedisTLSConfig) {
+	mutexRedisTLSConfig.Lock()
+	redisTLSOverride = override
+	mutexRedisTLSConfig.Unlock()
+}
+
+// RedisTLSOverride returns the go_redis_opts TLS override.
+func RedisTLSOverride() RedisTLSConfig {
+	mutexRedisTLSConfig.Lock()
+	defer mutexRedisTLSConfig.Unlock()
+	return redisTLSOverride
+}
+
+// ClearRedisTLSConfigCache clears the cached *tls.Config, to pick up rotated
+// certificates.
+func ClearRedisTLSConfigCache() {
+	mutexRedisTLSConfig.Lock()
+	redisTLSConfigCache = make(map[RedisTLSConfig]*tls.Config)
+	mutexRedisTLSConfig.Unlock()
+}
+
+// Merge returns the config, with the fields set in the override replaced.
+func (config RedisTLSConfig) Merge(override RedisTLSConfig) RedisTLSConfig {
+	if override.Enabled {
+		config.Enabled = true
+	}
+	if override.CACertPath != "" {
+		config.CACertPath = override.CACertPath
+	}
+	if override.CertPath != "" {
+		config.CertPath = override.CertPath
+	}
+	if override.KeyPath != "" {
+		config.KeyPath = override.KeyPath
+	}
+	if override.ServerName != "" {
+		config.ServerName = override.ServerName
+	}
+	if override.InsecureSkipVerify {
+		config.InsecureSkipVerify = true
+	}
+	return config
+}
+
+// TLSConfig returns the *tls.Config to reach the redis at the addr. The
+// ServerName defaults to the host of the addr.
+func (config RedisTLSConfig) TLSConfig(addr string) (*tls.Config, error) {
+	if config.ServerName == "" {
+		if host, _, e := net.SplitHostPort(addr); e == nil {
+			config.ServerName = host
+		}
+	}
+
+	mutexRedisTLSConfig.Lock()
+	defer mutexRedisTLSConfig.Unlock()
+
+	if tlsConfig, ok := redisTLSConfigCache[config]; ok {
+		return tlsConfig, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CACertPath != "" {
+		pem, e := os.ReadFile(config.CACertPath)
+		if e != nil {
+			return nil, fmt.Errorf("redis TLS CA %s: %v", config.CACertPath, e)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("redis TLS CA %s: no certificates",
+				config.CACertPath)
+		}
+	}
+
+	if config.CertPath != "" || config.KeyPath != "" {
+		cert, e := tls.LoadX509KeyPair(config.CertPath, config.KeyPath)
+		if e != nil {
+			return nil, fmt.Errorf("redis TLS client certificate %s: %v",
+				config.CertPath, e)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	redisTLSConfigCache[config] = tlsConfig
+	return tlsConfig, nil
+}
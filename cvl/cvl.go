@@ -115,13 +115,14 @@ type modelTableInfo struct {
 	mustExpr         map[string][]*mustInfo
 	whenExpr         map[string][]*whenInfo
 	tablesForMustExp map[string]cmn.CVLOperation
-	refFromTables    []tblFieldPair      //list of table or table/field referring to this table
-	custValidation   map[string][]string // Map for custom validation node and function name
-	dfltLeafVal      map[string]string   //map of leaf names and default value
-	mandatoryNodes   map[string]bool     //map of leaf names and mandatory flag & leaf-list with min-elements > 0
-	dependentOnTable string              // Name of table on which it is dependent
-	dependentTables  []string            // list of dependent tables
-	has_static_key   bool                // True, if LIST has sonic-extension:tbl-key
+	refFromTables    []tblFieldPair                      //list of table or table/field referring to this table
+	custValidation   map[string][]string                 // Map for custom validation node and function name
+	dfltLeafVal      map[string]string                   //map of leaf names and default value
+	mandatoryNodes   map[string]bool                     //map of leaf names and mandatory flag & leaf-list with min-elements > 0
+	leafType         map[string]*yparser.YParserLeafType //map of leaf names and YANG type
+	dependentOnTable string                              // Name of table on which it is dependent
+	dependentTables  []string                            // list of dependent tables
+	has_static_key   bool                                // True, if LIST has sonic-extension:tbl-key
 }
 
 // CVLErrorInfo Struct for CVL Error Info
@@ -471,6 +472,7 @@ func storeModelInfo(modelFile string, module *yparser.YParserModule) {
 		tInfo.mapLeaf = lInfo.MapLeaf
 		tInfo.custValidation = lInfo.CustValidation
 		tInfo.mandatoryNodes = lInfo.MandatoryNodes
+		tInfo.leafType = lInfo.LeafType
 		tInfo.dependentOnTable = lInfo.DependentOnTable
 
 		//store default values used in must and when exp
@@ -110,6 +110,14 @@ type ValidationTimeStats struct {
 	Peak time.Duration
 }
 
+// CVLFieldType YANG type of a field in a Redis table
+type CVLFieldType struct {
+	Base       string   //YANG built-in type, after resolving typedefs and leafrefs
+	Typedefs   []string //typedef names, from field's own type to the built-in type
+	IsLeafList bool     //leaf-list field, stored with '@' suffix in Redis
+	Default    string   //default value, if any
+}
+
 // CVLDepDataForDelete Structure for dependent entry to be deleted
 type CVLDepDataForDelete struct {
 	RefKey string                       //Ref Key which is getting deleted
@@ -1254,6 +1262,29 @@ func (c *CVL) GetAllReferringTables(tableName string) map[string][]string {
 	return refTbls
 }
 
+// GetFieldType Returns the YANG type of a field in given Redis table. The field
+// name may have the '@' suffix for leaf-list. Returns false if the table
+// or the field is not found in the schema.
+func GetFieldType(tableName, field string) (CVLFieldType, bool) {
+	field = strings.TrimSuffix(field, "@")
+	for _, yangList := range modelInfo.redisTableToYangList[tableName] {
+		tblInfo, exists := modelInfo.tableInfo[yangList]
+		if !exists {
+			continue
+		}
+		if lType, exists := tblInfo.leafType[field]; exists && lType != nil {
+			return CVLFieldType{
+				Base:       lType.Base,
+				Typedefs:   lType.Typedefs,
+				IsLeafList: lType.IsLeafList,
+				Default:    tblInfo.dfltLeafVal[field],
+			}, true
+		}
+	}
+
+	return CVLFieldType{}, false
+}
+
 func ReconfigureRedisOptions(opt redis.Options) {
 	UpdateRedisOptions(&opt)
 
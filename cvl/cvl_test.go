@@ -3996,3 +3996,32 @@ func TestValidationTimeStats(t *testing.T) {
 		t.Errorf("TestValidationTimeStats : clearing stats failed")
 	}
 }
+
+func TestGetFieldType(t *testing.T) {
+	ft, ok := cvl.GetFieldType("ACL_RULE", "PRIORITY")
+	if !ok || ft.Base != "uint16" || ft.IsLeafList {
+		t.Errorf("GetFieldType(ACL_RULE, PRIORITY) = %v, %v", ft, ok)
+	}
+
+	ft, ok = cvl.GetFieldType("ACL_RULE", "SRC_IP")
+	if !ok || ft.Base != "string" || len(ft.Typedefs) == 0 || ft.Typedefs[0] != "ipv4-prefix" {
+		t.Errorf("GetFieldType(ACL_RULE, SRC_IP) = %v, %v", ft, ok)
+	}
+
+	ft, ok = cvl.GetFieldType("ACL_TABLE", "ports@")
+	if !ok || ft.Base != "string" || !ft.IsLeafList {
+		t.Errorf("GetFieldType(ACL_TABLE, ports@) = %v, %v", ft, ok)
+	}
+
+	ft, ok = cvl.GetFieldType("PORT", "mtu")
+	if !ok || ft.Base != "uint32" || ft.Default != "9100" {
+		t.Errorf("GetFieldType(PORT, mtu) = %v, %v", ft, ok)
+	}
+
+	if _, ok = cvl.GetFieldType("ACL_RULE", "UNKNOWN"); ok {
+		t.Errorf("GetFieldType(ACL_RULE, UNKNOWN) succeeded")
+	}
+	if _, ok = cvl.GetFieldType("UNKNOWN_TABLE", "PRIORITY"); ok {
+		t.Errorf("GetFieldType(UNKNOWN_TABLE, PRIORITY) succeeded")
+	}
+}
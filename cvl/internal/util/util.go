@@ -41,11 +41,14 @@ static void ly_set_log_callback(int enable) {
 */
 import "C"
 import (
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	fileLog "log"
+	"net"
 	"os"
 	"os/signal"
 	"runtime"
@@ -54,6 +57,7 @@ import (
 	"sync"
 	"syscall"
 
+	cmn "github.com/Azure/sonic-mgmt-common/cvl/common"
 	set "github.com/Workiva/go-datastructures/set"
 	"github.com/go-redis/redis/v7"
 	log "github.com/golang/glog"
@@ -594,6 +598,26 @@ func GetDbPassword(dbName string) string {
 	return password
 }
 
+// GetDbUsername Get DB (redis ACL) username
+func GetDbUsername(dbName string) string {
+	inst := getDbInst(dbName)
+	if username, ok := inst["username"].(string); ok {
+		return username
+	}
+	return ""
+}
+
+// GetDbTLSConfig Get DB TLS config, from the "tls_*" fields of the instance,
+// and the go_redis_opts override (shared with translib/db).
+// Returns nil if TLS is not enabled.
+func GetDbTLSConfig(dbName string) (*tls.Config, error) {
+	config := cmn.RedisTLSConfigOf(getDbInst(dbName)).Merge(cmn.RedisTLSOverride())
+	if !config.Enabled {
+		return nil, nil
+	}
+	return config.TLSConfig(GetDbTcpAddr(dbName))
+}
+
 // GetDbTcpAddr Get DB TCP endpoint
 func GetDbTcpAddr(dbName string) string {
 	inst := getDbInst(dbName)
@@ -721,6 +745,22 @@ func getRedisOptions(dbName string) *redis.Options {
 	opt.Addr = dbAddr
 	opt.Password = GetDbPassword(dbName)
 	opt.DB = GetDbId(dbName)
+	if opt.Username == "" {
+		opt.Username = GetDbUsername(dbName)
+	}
+
+	// TLS only applies to TCP. Fail the connections, rather than fall back
+	// to plain text, if the TLS config cannot be loaded.
+	if dbNetwork == "tcp" {
+		if tlsConfig, err := GetDbTLSConfig(dbName); err != nil {
+			CVL_LEVEL_LOG(ERROR, "Redis TLS config for %s: %v", dbName, err)
+			opt.Dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return nil, err
+			}
+		} else if tlsConfig != nil {
+			opt.TLSConfig = tlsConfig
+		}
+	}
 
 	return &opt
 }
@@ -225,6 +225,33 @@ struct lys_leaf_ref_path* lys_get_leafrefs(struct lys_node_leaf *node) {
 	}
 }
 
+//Get the built-in base type of a leaf, following the leafref targets
+int lys_get_leaf_base_type(struct lys_node_leaf *node) {
+	struct lys_type *type = &node->type;
+
+	while ((type->base == LY_TYPE_LEAFREF) &&
+		(type->info.lref.target != NULL)) {
+		type = &type->info.lref.target->type;
+	}
+
+	return type->base;
+}
+
+//Get the name of the typedef at given depth of the type derivation chain
+const char *lys_get_leaf_typedef(struct lys_node_leaf *node, int depth) {
+	struct lys_type *type = &node->type;
+	int idx = 0;
+
+	for (; (type != NULL) && (type->der != NULL); idx++) {
+		if (idx == depth) {
+			return type->der->name;
+		}
+		type = &type->der->type;
+	}
+
+	return NULL;
+}
+
 */
 import "C"
 
@@ -249,6 +276,13 @@ type WhenExpression struct {
 	NodeNames []string //node names under when condition
 }
 
+// YParserLeafType YANG type details of a leaf or leaf-list
+type YParserLeafType struct {
+	Base       string   //built-in type, after resolving typedefs and leafrefs
+	Typedefs   []string //type derivation chain, from leaf's own type to built-in
+	IsLeafList bool     //leaf-list node
+}
+
 // YParserListInfo Important schema information to be loaded at bootup time
 type YParserListInfo struct {
 	ListName        string
@@ -268,8 +302,9 @@ type YParserListInfo struct {
 	CustValidation   map[string][]string
 	WhenExpr         map[string][]*WhenExpression //multiple when expression for choice/case etc
 	MandatoryNodes   map[string]bool
-	DependentOnTable string //for table on which it is dependent
-	Key              string //Static key, value comes from sonic-extension:tbl-key
+	LeafType         map[string]*YParserLeafType //type of each leaf/leaf-list
+	DependentOnTable string                      //for table on which it is dependent
+	Key              string                      //Static key, value comes from sonic-extension:tbl-key
 }
 
 type YParserLeafValue struct {
@@ -781,6 +816,9 @@ func getModelChildInfo(l *YParserListInfo, node *C.struct_lys_node,
 
 			leafName := C.GoString(sleaf.name)
 
+			l.LeafType[leafName] = getLeafType(sleaf,
+				sChild.nodetype == C.LYS_LEAFLIST)
+
 			if sChild.nodetype == C.LYS_LEAF {
 				if sleaf.dflt != nil {
 					l.DfltLeafVal[leafName] = C.GoString(sleaf.dflt)
@@ -880,6 +918,64 @@ func getModelChildInfo(l *YParserListInfo, node *C.struct_lys_node,
 	}
 }
 
+// getLeafType returns the type details of a leaf or leaf-list
+func getLeafType(sleaf *C.struct_lys_node_leaf, isLeafList bool) *YParserLeafType {
+	lType := &YParserLeafType{IsLeafList: isLeafList}
+
+	switch C.lys_get_leaf_base_type(sleaf) {
+	case C.LY_TYPE_BINARY:
+		lType.Base = "binary"
+	case C.LY_TYPE_BITS:
+		lType.Base = "bits"
+	case C.LY_TYPE_BOOL:
+		lType.Base = "boolean"
+	case C.LY_TYPE_DEC64:
+		lType.Base = "decimal64"
+	case C.LY_TYPE_EMPTY:
+		lType.Base = "empty"
+	case C.LY_TYPE_ENUM:
+		lType.Base = "enumeration"
+	case C.LY_TYPE_IDENT:
+		lType.Base = "identityref"
+	case C.LY_TYPE_INST:
+		lType.Base = "instance-identifier"
+	case C.LY_TYPE_LEAFREF:
+		lType.Base = "leafref"
+	case C.LY_TYPE_STRING:
+		lType.Base = "string"
+	case C.LY_TYPE_UNION:
+		lType.Base = "union"
+	case C.LY_TYPE_INT8:
+		lType.Base = "int8"
+	case C.LY_TYPE_INT16:
+		lType.Base = "int16"
+	case C.LY_TYPE_INT32:
+		lType.Base = "int32"
+	case C.LY_TYPE_INT64:
+		lType.Base = "int64"
+	case C.LY_TYPE_UINT8:
+		lType.Base = "uint8"
+	case C.LY_TYPE_UINT16:
+		lType.Base = "uint16"
+	case C.LY_TYPE_UINT32:
+		lType.Base = "uint32"
+	case C.LY_TYPE_UINT64:
+		lType.Base = "uint64"
+	default:
+		lType.Base = "unknown"
+	}
+
+	for depth := 0; ; depth++ {
+		tName := C.lys_get_leaf_typedef(sleaf, C.int(depth))
+		if tName == nil {
+			break
+		}
+		lType.Typedefs = append(lType.Typedefs, C.GoString(tName))
+	}
+
+	return lType
+}
+
 // GetModelListInfo Get model info for YANG list and its subtree
 func GetModelListInfo(module *YParserModule) []*YParserListInfo {
 	var list []*YParserListInfo
@@ -927,6 +1023,7 @@ func GetModelListInfo(module *YParserModule) []*YParserListInfo {
 			l.WhenExpr = make(map[string][]*WhenExpression)
 			l.DfltLeafVal = make(map[string]string)
 			l.MandatoryNodes = make(map[string]bool)
+			l.LeafType = make(map[string]*YParserLeafType)
 
 			//Add keys
 			keys := (*[10]*C.struct_lys_node_leaf)(unsafe.Pointer(slist.keys))
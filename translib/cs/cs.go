@@ -24,6 +24,8 @@ package cs
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -42,10 +44,16 @@ const (
 	cs_STATE_ACTIVE
 	cs_STATE_CONFIRM_TIMER
 	cs_STATE_ROLLBACK_REPLACE
+	cs_STATE_SCHEDULED
 )
 
 const (
-	ccDbTxCmdsLim int = 10000
+	ccDbTxCmdsLim   int = 100000 // Max cmds in the Candidate Config DB
+	ccDbTxChunkSize int = 5000   // Max cmds per pipeline on commit
+	csMaxSessions   int = 16     // Max concurrent Config Sessions
+
+	csLockTTL  time.Duration = 60 * time.Second       // Lease of the table locks
+	csLockWait time.Duration = 800 * time.Millisecond // For the table locks
 )
 
 type configSession struct {
@@ -71,8 +79,10 @@ type configSession struct {
 	resumeTime time.Time
 	exitTime   time.Time
 
-	// lastActiveTime could be used for (Future) ConfigSession Idle Timeout
+	// lastActiveTime is used for the ConfigSession Idle Timeout. idleWarned
+	// is set once the terminal is warned of the impending Idle Timeout.
 	lastActiveTime time.Time
+	idleWarned     bool
 
 	//Commit timer
 	commitTimer *time.Timer
@@ -82,20 +92,66 @@ type configSession struct {
 	// Config Session commit state
 	commitState configSessionState
 
-	// Current cfg file name for rollback
-	rollbackCfg string
+	// Origin of the commit in the checkpoint history ("commit" if empty)
+	origin string
+
+	// Inverse change-set of the commit pending confirmation (the entries
+	// changed by the commit, with their values before it)
+	rollbackSet []db.TxDiffEntry
+
+	// Commit (pending confirmation) for the post-commit hooks
+	commitInfo *CommitInfo
+
+	// Scheduled commit (cs_STATE_SCHEDULED): time, label, and the timer
+	applyAt    time.Time
+	applyLabel string
+	applyTimer *time.Timer
 
 	// channel to end timer routine.
 	commitCh chan<- bool
+
+	// csCommitSeq at the start of the session. The keys committed by the
+	// other sessions after it, conflict with the keys of this session.
+	startSeq uint64
+
+	// The write locks of the tables written by the session (only while
+	// committing, and till confirmed)
+	locks *db.TableLocks
+
+	// The number of the ccDB commands persisted (See persistCS)
+	persistedCmds int
+
+	// The number of the requests using the ccDB (See acquireCCDB), and
+	// whether the session is reserved (See reserveCS) to close, replace, or
+	// commit the ccDB.
+	users    int
+	reserved bool
 }
 
 var csMutex sync.Mutex
 
-// uCS is the unnamed Config Session (the only one supported in first cut)
-var uCS *configSession
+// csCond is signalled when the last request using the ccDB of a Config
+// Session releases it.
+var csCond = sync.NewCond(&csMutex)
+
+// csSessions are the Config Sessions, by name. (The unnamed Config Session's
+// name is "")
+var csSessions = make(map[string]*configSession)
 
 var csTokenCtr uint32
 
+// csCommitRec is the record of the keys committed by a Config Session.
+type csCommitRec struct {
+	seq   uint64
+	token string
+	keys  map[string]bool // "TABLE|key"
+}
+
+// csCommitSeq is the sequence number of the last commit, and csCommitLog
+// the commits which may conflict with the current sessions.
+var csCommitSeq uint64
+var csCommitLog []csCommitRec
+
 func (cs configSession) String() string {
 	return fmt.Sprintf("{ name: %v, token: %v, state: %v,\n"+
 		"  username: %v, roles: %v, pid: %v, ccDB: %v,\n"+
@@ -126,46 +182,112 @@ func (cs *configSession) IsPidActive() bool {
 	return true
 }
 
+// lookupCS returns the Config Session of which cs is a copy. Caller holds
+// the csMutex.
+func lookupCS(cs *configSession) *configSession {
+	if cs == nil {
+		return nil
+	}
+	if ucs := csSessions[cs.name]; ucs != nil && ucs.token == cs.token {
+		return ucs
+	}
+	return nil
+}
+
+// acquireCCDB returns the ccDB of the Config Session of which cs is a copy,
+// for use by a request, and the func to release it. The ccDB is not closed,
+// or replaced, till released. It fails with TranslibBusy, if the session is
+// reserved (Eg: being committed).
+func (cs *configSession) acquireCCDB() (*db.DB, func(), error) {
+	csMutex.Lock()
+	defer csMutex.Unlock()
+
+	ucs := lookupCS(cs)
+	if ucs == nil {
+		return nil, nil, tlerr.TranslibInvalidSession{}
+	}
+	if ucs.reserved {
+		glog.Infof("acquireCCDB[%s]: Reserved", ucs.token)
+		return nil, nil, tlerr.TranslibBusy{}
+	}
+
+	ucs.users++
+	release := func() {
+		csMutex.Lock()
+		defer csMutex.Unlock()
+		if ucs.users--; ucs.users == 0 {
+			csCond.Broadcast()
+		}
+	}
+	return ucs.ccDB, release, nil
+}
+
+// reserveCS reserves the Config Session, to close, replace, or commit its
+// ccDB, after the requests using it release it. It fails with TranslibBusy,
+// if the session is already reserved. Caller holds the csMutex, which is
+// released while waiting for the requests.
+func reserveCS(ucs *configSession) error {
+	if ucs.reserved {
+		glog.Infof("reserveCS[%s]: Reserved", ucs.token)
+		return tlerr.TranslibBusy{}
+	}
+
+	ucs.reserved = true
+	for ucs.users != 0 {
+		glog.Infof("reserveCS[%s]: Waiting for %d request(s)", ucs.token,
+			ucs.users)
+		csCond.Wait()
+	}
+	return nil
+}
+
+// unreserveCS releases the reservation of the Config Session. Caller holds
+// the csMutex.
+func unreserveCS(ucs *configSession) {
+	ucs.reserved = false
+}
+
 func (cs *configSession) UpdateLastActiveTime() {
 	csMutex.Lock()
 	defer csMutex.Unlock()
 
-	if !(cs == nil || uCS == nil) {
-		uCS.lastActiveTime = time.Now()
+	if ucs := lookupCS(cs); ucs != nil {
+		ucs.lastActiveTime = time.Now()
+		ucs.idleWarned = false
 	}
 }
 
 func (cs *configSession) StartCommitTimer(timeout int) *time.Timer {
 	csMutex.Lock()
 	defer csMutex.Unlock()
-	if !(cs == nil || uCS == nil) {
-		uCS.commitTimer = time.NewTimer(time.Duration(timeout) * time.Second)
-		return uCS.commitTimer
+	if ucs := lookupCS(cs); ucs != nil {
+		ucs.commitTimer = time.NewTimer(time.Duration(timeout) * time.Second)
+		return ucs.commitTimer
 	}
 	return nil
 }
 
-func (cs *configSession) SetRollbackCfg(cfg string) {
+func (cs *configSession) SetCommitState(state configSessionState) {
 	csMutex.Lock()
 	defer csMutex.Unlock()
-	if !(cs == nil || uCS == nil) {
-		uCS.rollbackCfg = cfg
+	if ucs := lookupCS(cs); ucs != nil {
+		ucs.commitState = state
 	}
 }
 
-func (cs *configSession) SetCommitState(state configSessionState) {
+func (cs *configSession) SetCommitCh(ch chan<- bool) {
 	csMutex.Lock()
 	defer csMutex.Unlock()
-	if !(cs == nil || uCS == nil) {
-		uCS.commitState = state
+	if ucs := lookupCS(cs); ucs != nil {
+		ucs.commitCh = ch
 	}
 }
 
-func (cs *configSession) SetCommitCh(ch chan<- bool) {
+func (cs *configSession) setCommitInfo(info *CommitInfo) {
 	csMutex.Lock()
 	defer csMutex.Unlock()
-	if !(cs == nil || uCS == nil) {
-		uCS.commitCh = ch
+	if ucs := lookupCS(cs); ucs != nil {
+		ucs.commitInfo = info
 	}
 }
 
@@ -175,37 +297,25 @@ func newCS(name string, username string, roles []string, pid int32) (*configSess
 	csMutex.Lock()
 	defer csMutex.Unlock()
 
-	// Does the uCS exist already?
-	if uCS != nil {
+	// Does the session exist already?
+	if csSessions[name] != nil {
 		return nil, tlerr.TranslibBusy{}
 	}
 
-	ccDB, err := db.NewDB(db.Options{DBNo: db.ConfigDB,
-		IsSession:               true,
-		TxCmdsLim:               ccDbTxCmdsLim,
-		ForceNewRedisConnection: true,
-	})
-	if err != nil {
-		glog.Errorf("newCS: db.NewDB err %s", err)
-		return nil, err
+	if len(csSessions) >= csMaxSessions {
+		glog.Errorf("newCS: %d sessions exist", len(csSessions))
+		return nil, tlerr.TranslibBusy{}
 	}
 
-	if err = csStartTx(ccDB); err != nil {
-		ccDB.DeleteDB()
+	ccDB, err := newCCDB(username)
+	if err != nil {
 		return nil, err
 	}
 
 	token := fmt.Sprintf("%d-%d", time.Now().Unix(),
 		atomic.AddUint32(&csTokenCtr, 1))
 
-	if err = db.ConfigDBTryLock(token); err != nil {
-		glog.Errorf("newCS: db.ConfigDBTryLock err %s", err)
-		csAbortTx(ccDB)
-		ccDB.DeleteDB()
-		return nil, err
-	}
-
-	uCS = &configSession{
+	ucs := &configSession{
 		name:     name,
 		token:    token,
 		state:    cs_STATE_ACTIVE,
@@ -213,13 +323,37 @@ func newCS(name string, username string, roles []string, pid int32) (*configSess
 		roles:    roles,
 		pid:      pid,
 		ccDB:     ccDB,
+		startSeq: csCommitSeq,
 	}
 
-	uCS.startTime = time.Now()
-	uCS.lastActiveTime = uCS.startTime
+	ucs.startTime = time.Now()
+	ucs.lastActiveTime = ucs.startTime
+	csSessions[name] = ucs
+	persistCS(ucs)
 
 	glog.Infof("newCS[%s]: %s %s %v %d", token, name, username, roles, pid)
-	return uCS, nil
+	return ucs, nil
+}
+
+// newCCDB opens the Candidate Config DB, and starts its Transaction.
+func newCCDB(username string) (*db.DB, error) {
+	ccDB, err := db.NewDB(db.Options{DBNo: db.ConfigDB,
+		IsSession:               true,
+		TxCmdsLim:               ccDbTxCmdsLim,
+		TxChunkSize:             ccDbTxChunkSize,
+		ForceNewRedisConnection: true,
+		User:                    username,
+	})
+	if err != nil {
+		glog.Errorf("newCCDB: db.NewDB err %s", err)
+		return nil, err
+	}
+
+	if err = csStartTx(ccDB); err != nil {
+		ccDB.DeleteDB()
+		return nil, err
+	}
+	return ccDB, nil
 }
 
 func resumeCS(name string, roles []string, pid int32) (*configSession, error) {
@@ -228,20 +362,23 @@ func resumeCS(name string, roles []string, pid int32) (*configSession, error) {
 	csMutex.Lock()
 	defer csMutex.Unlock()
 
-	if uCS == nil || uCS.state != cs_STATE_SUSPENDED {
-		return uCS, tlerr.TranslibBusy{}
+	ucs := csSessions[name]
+	if ucs == nil || ucs.state != cs_STATE_SUSPENDED {
+		return ucs, tlerr.TranslibBusy{}
 	}
 
-	uCS.state = cs_STATE_ACTIVE
+	ucs.state = cs_STATE_ACTIVE
 
 	// Update the Pid, and Roles
-	uCS.roles = roles
-	uCS.pid = pid
-	uCS.resumeTime = time.Now()
-	uCS.lastActiveTime = uCS.resumeTime
-
-	glog.Infof("resumeCS[%s]: %s %s %v", uCS.token, name, roles, pid)
-	return uCS, nil
+	ucs.roles = roles
+	ucs.pid = pid
+	ucs.resumeTime = time.Now()
+	ucs.lastActiveTime = ucs.resumeTime
+	ucs.idleWarned = false
+	persistCS(ucs)
+
+	glog.Infof("resumeCS[%s]: %s %s %v", ucs.token, name, roles, pid)
+	return ucs, nil
 }
 
 func suspendCS(name string) (*configSession, error) {
@@ -250,62 +387,108 @@ func suspendCS(name string) (*configSession, error) {
 	csMutex.Lock()
 	defer csMutex.Unlock()
 
-	if uCS == nil || uCS.state != cs_STATE_ACTIVE {
-		return uCS, tlerr.TranslibBusy{}
+	ucs := csSessions[name]
+	if ucs == nil || ucs.state != cs_STATE_ACTIVE || ucs.reserved {
+		return ucs, tlerr.TranslibBusy{}
 	}
 
-	uCS.state = cs_STATE_SUSPENDED
-	uCS.pid = 0
-	uCS.exitTime = time.Now()
-	uCS.lastActiveTime = uCS.exitTime
+	ucs.state = cs_STATE_SUSPENDED
+	ucs.pid = 0
+	ucs.exitTime = time.Now()
+	ucs.lastActiveTime = ucs.exitTime
+	persistCS(ucs)
 
-	glog.Infof("suspendCS[%s]: %s", uCS.token, name)
-	return uCS, nil
+	glog.Infof("suspendCS[%s]: %s", ucs.token, name)
+	return ucs, nil
 }
 
 // commitCS commits the Transaction. A primary error, and a list of secondary
 // (warning?) errors are returned. In the absence of a primary error,
 // secondary errors indicate that the commit was successful, but a
 // secondary operation (Eg: Unlocking the DB) was not successful)
+// The CONFIG_DB is locked while committing (and till confirmed, if
+// isConfirmNeeded). The commit fails with CsCommitConflict, if the keys of
+// the session were modified since the session read them, or by the commit
+// of another session since this session started.
 func commitCS(name string, label string, isConfirmNeeded bool) (error, error, error) {
 	glog.Infof("commitCS: name: %s, label: %s", name, label)
 
 	csMutex.Lock()
 	defer csMutex.Unlock()
 
+	ucs := csSessions[name]
+	if ucs == nil || ucs.state != cs_STATE_ACTIVE {
+		glog.Infof("commitCS: %s: Not active", name)
+		return tlerr.TranslibBusy{}, nil, nil
+	}
+
+	if err := reserveCS(ucs); err != nil {
+		return err, nil, nil
+	}
+	defer unreserveCS(ucs)
+
+	// The session may be suspended, while waiting for the requests.
+	if ucs.state != cs_STATE_ACTIVE {
+		glog.Infof("commitCS: %s: Not active", name)
+		return tlerr.TranslibBusy{}, nil, nil
+	}
+
+	return commitUCS(ucs, label, isConfirmNeeded)
+}
+
+// commitUCS commits the Transaction of the Config Session, like commitCS().
+// Caller holds the csMutex, and has reserved the session. The csMutex is
+// released while locking the tables, and committing the ccDB (redis I/O). The
+// reservation keeps the ccDB from being used, or modified meanwhile, and the
+// table locks keep the other sessions from committing the same tables.
+func commitUCS(ucs *configSession, label string, isConfirmNeeded bool) (error, error, error) {
 	var err, errSc, errSh error
-	var token string
+	var keys map[string]bool
+	var locks *db.TableLocks
+	var rollbackSet []db.TxDiffEntry
 
-	if uCS == nil || uCS.state != cs_STATE_ACTIVE {
-		err = tlerr.TranslibBusy{}
-	} else if err = csCommitTx(uCS.ccDB); err != nil {
-		// On Commit Failure, keep the Session active so the admin can
-		// review their changes. They can abort the session after review.
-		glog.Errorf("commitCS: csCommitTx err %s", err)
+	token := ucs.token
+	csMutex.Unlock()
+	locks, err = lockCS(ucs)
+	csMutex.Lock()
+	if err != nil {
+		glog.Errorf("commitCS[%s]: lockCS err %s", token, err)
+		goto commitUCSExit
+	}
+	ucs.locks = locks
+
+	// The other sessions which committed the tables, are logged by now.
+	if keys, err = checkCommitLogCS(ucs); err != nil {
+		// Keep the Session active so the admin can review their changes.
+		glog.Errorf("commitCS[%s]: %s", token, err)
 	} else {
+		csMutex.Unlock()
+		rollbackSet, err = commitTxCS(ucs, isConfirmNeeded)
+		csMutex.Lock()
+	}
 
-		uCS.commitTime = time.Now()
-		token = uCS.token
-		if isConfirmNeeded {
-			uCS.commitState = cs_STATE_CONFIRM_TIMER
-			uCS.ccDB.Opts.IsCommitted = true
-		} else {
-			// Cp History
-			errSh = createCpHistory(label)
-			uCS.ccDB.DeleteDB()
-			uCS.state = cs_STATE_None
-			// Db Unlock
-			if errSc = db.ConfigDBUnlock(uCS.token); errSc != nil {
-				glog.Warningf("commitCS: db.ConfigDBUnlock errSc %+v", errSc)
-			}
-			uCS = nil
+	if err != nil {
+		if errU := unlockCS(ucs); errU != nil {
+			glog.Warningf("commitCS[%s]: unlockCS err %+v", token, errU)
 		}
+		goto commitUCSExit
 	}
 
-	if err == nil && !isConfirmNeeded {
-		uCS = nil
+	logCommitCS(ucs, keys)
+
+	ucs.rollbackSet = rollbackSet
+	ucs.commitTime = time.Now()
+	if isConfirmNeeded {
+		ucs.commitState = cs_STATE_CONFIRM_TIMER
+		ucs.ccDB.Opts.IsCommitted = true
+		persistCS(ucs)
+	} else {
+		// Cp History
+		errSh = createCpHistory(ucs, label)
+		errSc = removeCS(ucs)
 	}
 
+commitUCSExit:
 	glog.Infof("commitCS[%s]: end", token)
 
 	return err, errSc, errSh
@@ -319,73 +502,288 @@ func deleteCS(name string) (error, error) {
 	csMutex.Lock()
 	defer csMutex.Unlock()
 
-	if uCS == nil {
+	ucs := csSessions[name]
+	if ucs == nil {
 		return tlerr.TranslibBusy{}, nil
 	}
-	switch uCS.state {
+	switch ucs.state {
 	case cs_STATE_ACTIVE, cs_STATE_SUSPENDED:
 		break
 	default:
 		return tlerr.TranslibBusy{}, nil
 	}
 
+	if err := reserveCS(ucs); err != nil {
+		return err, nil
+	}
+
+	return abortCS(ucs)
+}
+
+// abortCS aborts the Transaction, and removes the Config Session. Caller
+// holds the csMutex, and has reserved the session.
+func abortCS(ucs *configSession) (error, error) {
 	//Skip AbortTx when commit is in commit timer state.
 	//CommitTx is done while moving to commit timer state.
 	var err error
-	if uCS.commitState != cs_STATE_CONFIRM_TIMER {
-		err = csAbortTx(uCS.ccDB)
+	if ucs.commitState != cs_STATE_CONFIRM_TIMER {
+		err = csAbortTx(ucs.ccDB)
 		if err != nil {
-			glog.Errorf("deleteCS: csAbortTx err %s", err)
+			glog.Errorf("abortCS: csAbortTx err %s", err)
 		}
 	}
 
-	errU := db.ConfigDBUnlock(uCS.token)
+	errU := removeCS(ucs)
 	if errU != nil {
-		glog.Errorf("deleteCS: db.ConfigDBUnlock err %s", errU)
+		glog.Errorf("abortCS: unlockCS err %s", errU)
 	}
 
-	uCS.ccDB.DeleteDB()
-	uCS.state = cs_STATE_None
-
-	glog.Infof("deleteCS[%s]: %s err %s errU %s", uCS.token, name, err, errU)
-
-	uCS = nil
+	glog.Infof("abortCS[%s]: %s err %s errU %s", ucs.token, ucs.name, err, errU)
 
 	return err, errU
 }
 
-func createCpHistory(label string) error {
+func createCpHistory(ucs *configSession, label string) error {
 	// Cp History
 	var errSh error
-	if errSh = CreateCpHistEntry(label, uCS.token, uCS.username,
-		"commit", uCS.commitTime.UnixNano()); errSh != nil {
+	origin := ucs.origin
+	if len(origin) == 0 {
+		origin = cs_ORIGIN_COMMIT
+	}
+	if errSh = CreateCpHistEntry(label, ucs.token, ucs.username,
+		origin, ucs.commitTime.UnixNano()); errSh != nil {
 		glog.Warningf("commitCS: CreateCpHistEntry errSh %+v", errSh)
 	}
 	return errSh
 }
 
-func cleanCS() error {
+// cleanCS removes the (committed) Config Session of which cs is a copy.
+func cleanCS(cs *configSession) error {
 
 	csMutex.Lock()
 	defer csMutex.Unlock()
 
-	uCS.ccDB.DeleteDB()
-	uCS.state = cs_STATE_None
+	ucs := lookupCS(cs)
+	if ucs == nil {
+		return nil
+	}
+	if err := reserveCS(ucs); err != nil {
+		glog.Errorf("cleanCS[%s]: reserveCS err %s", ucs.token, err)
+		return err
+	}
+	return removeCS(ucs)
+}
+
+// removeCS closes the ccDB, releases the table locks (if locked), and removes
+// the Config Session (and its persisted copy). Caller holds the csMutex, and
+// has reserved the session.
+func removeCS(ucs *configSession) error {
+	if ucs.applyTimer != nil {
+		ucs.applyTimer.Stop()
+		ucs.applyTimer = nil
+	}
+	ucs.ccDB.DeleteDB()
+	ucs.state = cs_STATE_None
+
 	// Db Unlock
-	var errSc error
-	if errSc = db.ConfigDBUnlock(uCS.token); errSc != nil {
-		glog.Warningf("commitCS: db.ConfigDBUnlock errSc %+v", errSc)
+	errSc := unlockCS(ucs)
+	if errSc != nil {
+		glog.Warningf("removeCS: unlockCS errSc %+v", errSc)
+	}
+
+	if csSessions[ucs.name] == ucs {
+		delete(csSessions, ucs.name)
 	}
-	uCS = nil
+	unpersistCS(ucs)
+	trimCommitLogCS()
 	return errSc
 }
 
+// lockCS acquires the write locks of the tables written by the Config
+// Session, for its commit. Caller has reserved the session, and does not hold
+// the csMutex, as it may wait for the locks.
+func lockCS(ucs *configSession) (*db.TableLocks, error) {
+	var specs []db.TableLockSpec
+	tables := make(map[string]bool)
+	for _, wk := range ucs.ccDB.TxKeys() {
+		if !tables[wk.Ts.Name] {
+			tables[wk.Ts.Name] = true
+			specs = append(specs, db.TableLockSpec{Ts: wk.Ts,
+				Mode: db.WriteLock})
+		}
+	}
+
+	locks := db.NewTableLocks(ucs.token, csLockTTL)
+	if err := locks.Lock(csLockWait, specs...); err != nil {
+		return nil, err
+	}
+	return locks, nil
+}
+
+// unlockCS releases the table locks of the Config Session, if held. Caller
+// holds the csMutex.
+func unlockCS(ucs *configSession) error {
+	if ucs.locks == nil {
+		return nil
+	}
+	err := ucs.locks.Unlock()
+	ucs.locks = nil
+	return err
+}
+
+// commitTxCS commits the ccDB of the Config Session, if its keys are not
+// modified in the CONFIG_DB since the session read them. If isConfirmNeeded,
+// it returns the inverse change-set of the transaction, so that the commit
+// can be rolled back if not confirmed. Caller has reserved the session, and
+// locked its tables, and does not hold the csMutex.
+func commitTxCS(ucs *configSession, isConfirmNeeded bool) ([]db.TxDiffEntry, error) {
+	var rollbackSet []db.TxDiffEntry
+
+	if err := checkTxConflictsCS(ucs); err != nil {
+		// Keep the Session active so the admin can review their changes.
+		glog.Errorf("commitCS[%s]: %s", ucs.token, err)
+		return nil, err
+	}
+
+	if isConfirmNeeded {
+		diff, err := ucs.ccDB.TxDiff()
+		if err != nil {
+			glog.Errorf("commitCS[%s]: TxDiff err %s", ucs.token, err)
+			return nil, tlerr.New("Failed to capture the changes for rollback")
+		}
+		rollbackSet = diff
+	}
+
+	if err := csCommitTx(ucs.ccDB); err != nil {
+		// On Commit Failure, keep the Session active so the admin can
+		// review their changes. They can abort the session after review.
+		glog.Errorf("commitCS: csCommitTx err %s", err)
+		return nil, err
+	}
+	return rollbackSet, nil
+}
+
+// rollbackCS reverts the commit (pending confirmation) of the Config Session
+// of which cs is a copy, by restoring the entries of its inverse change-set
+// in a CONFIG_DB transaction. The table locks held by the Config Session are
+// released only after the transaction is committed, so that no other session
+// writes the tables between the conflict check and the restore. It fails with
+// CsRollbackConflict, if the entries are modified since the commit.
+func rollbackCS(cs *configSession) error {
+	csMutex.Lock()
+	defer csMutex.Unlock()
+
+	ucs := lookupCS(cs)
+	if ucs == nil {
+		return tlerr.TranslibInvalidSession{}
+	}
+
+	err := applyRollbackSet(ucs.rollbackSet, ucs.ccDB)
+	if err == nil {
+		ucs.rollbackSet = nil
+	}
+
+	if errUl := unlockCS(ucs); errUl != nil {
+		glog.Warningf("rollbackCS[%s]: unlockCS err %+v", ucs.token, errUl)
+	}
+	return err
+}
+
+// checkCommitLogCS returns the keys of the session, or CsCommitConflict with
+// the keys committed by the other sessions since the session started. Caller
+// holds the csMutex.
+func checkCommitLogCS(ucs *configSession) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	for _, wk := range ucs.ccDB.TxKeys() {
+		keys[wk.Ts.Name+"|"+strings.Join(wk.Key.Comp, "|")] = true
+	}
+
+	var cKeys []string
+	for _, rec := range csCommitLog {
+		if rec.seq <= ucs.startSeq || rec.token == ucs.token {
+			continue
+		}
+		for key := range keys {
+			if rec.keys[key] && !containsString(cKeys, key) {
+				glog.Infof("checkCommitLogCS[%s]: %s committed by %s",
+					ucs.token, key, rec.token)
+				cKeys = append(cKeys, key)
+			}
+		}
+	}
+
+	if len(cKeys) != 0 {
+		sort.Strings(cKeys)
+		return nil, CsCommitConflict{Keys: cKeys}
+	}
+
+	return keys, nil
+}
+
+// checkTxConflictsCS returns CsCommitConflict with the keys of the session
+// modified in the CONFIG_DB since the session read them. On success, the keys
+// are left WATCHed for the csCommitTx(). Caller has reserved the session.
+func checkTxConflictsCS(ucs *configSession) error {
+	conflicts, err := ucs.ccDB.CheckTxConflicts()
+	if err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	cKeys := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		cKeys = append(cKeys, c.Entry)
+	}
+	sort.Strings(cKeys)
+	return CsCommitConflict{Keys: cKeys}
+}
+
+// logCommitCS records the keys committed by the session, if other sessions
+// exist. Caller holds the csMutex.
+func logCommitCS(ucs *configSession, keys map[string]bool) {
+	csCommitSeq++
+	if len(csSessions) > 1 && len(keys) != 0 {
+		csCommitLog = append(csCommitLog, csCommitRec{seq: csCommitSeq,
+			token: ucs.token, keys: keys})
+	}
+}
+
+// trimCommitLogCS removes the commits, which precede all the sessions.
+// Caller holds the csMutex.
+func trimCommitLogCS() {
+	minSeq := csCommitSeq
+	for _, ucs := range csSessions {
+		if ucs.startSeq < minSeq {
+			minSeq = ucs.startSeq
+		}
+	}
+
+	i := 0
+	for ; i < len(csCommitLog) && csCommitLog[i].seq <= minSeq; i++ {
+	}
+	if i != 0 {
+		csCommitLog = append([]csCommitRec(nil), csCommitLog[i:]...)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DebugGetCSDB returns the Candidate Config DB of the unnamed Config Session.
 func DebugGetCSDB() *db.DB {
 	csMutex.Lock()
 	defer csMutex.Unlock()
 
-	if uCS != nil {
-		return uCS.ccDB
+	if ucs := csSessions[""]; ucs != nil {
+		return ucs.ccDB
 	}
 
 	return nil
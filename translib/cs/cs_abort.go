@@ -43,7 +43,7 @@ func (sess *Session) Abort() (bool, CsStatus) {
 			if sess.configSession.commitState == cs_STATE_CONFIRM_TIMER {
 				if err = abortSessionCommit(sess); err != nil {
 					success = false
-					status = CsStatusInternalError{Err: errors.New("Config reload failure on commit abort")}
+					status = CsStatusInternalError{Err: errors.New("Config rollback failure on commit abort")}
 					break
 				}
 			}
@@ -23,7 +23,6 @@ package cs
 
 import (
 	"errors"
-	"strconv"
 	"time"
 
 	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
@@ -35,18 +34,13 @@ const (
 	cs_COMMIT_CONFIRM_TIMEOUT_MIN = 30
 )
 
-func saveRunningConfig(username string) (string, error) {
-
-	now := time.Now()
-	dest := "temp://rollback.cfg." + strconv.FormatInt(int64(now.UnixNano()), 10) + ".json"
+type CommitOpts interface {
+}
 
-	options := [...]string{"running-configuration", dest, "", username}
-	queryResult := transformer.HostQuery("cfg_mgmt.save", options)
-	if queryResult.Err != nil {
-		glog.Infof("Error: %s", queryResult.Err.Error())
-		return "", errors.New("Failed to save current config")
-	}
-	return dest, nil
+// COapplyAt option schedules the commit at the Time (Eg: In a maintenance
+// window). It can not be combined with the confirm timeout.
+type COapplyAt struct {
+	Time time.Time
 }
 
 func processCommitConfirm(sess *Session, label string) (bool, CsStatus) {
@@ -65,20 +59,26 @@ func processCommitConfirm(sess *Session, label string) (bool, CsStatus) {
 		sess.configSession.commitTimer.Stop()
 		sess.configSession.commitCh <- true
 	}
-	if errSh := createCpHistory(label); errSh != nil {
+	if errSh := createCpHistory(&sess.configSession, label); errSh != nil {
 		status = CsStatusCommitFailure{Err: errSh}
 	} else {
 		status = CsStatusCommitSuccess{}
 		success = true
 	}
 
+	if success && sess.configSession.commitInfo != nil {
+		info := *sess.configSession.commitInfo
+		info.Label = label
+		runPostCommitHooks(&info)
+	}
+
 	//Clean up to unlock db
-	cleanCS()
+	cleanCS(&sess.configSession)
 
 	return success, status
 }
 
-func processCommitTimer(sess *Session, timeout int, rollbackCfg string) (bool, CsStatus) {
+func processCommitTimer(sess *Session, timeout int) (bool, CsStatus) {
 
 	commitTimer := sess.StartCommitTimer(timeout)
 	if commitTimer == nil {
@@ -102,55 +102,83 @@ func processCommitTimer(sess *Session, timeout int, rollbackCfg string) (bool, C
 			//Start rollback process
 
 			sess.SetCommitState(cs_STATE_ROLLBACK_REPLACE)
-			glog.Infof("Commit:[%s]:Timer timeout %s, Reload initiated.", sess.token, timeouttime)
-			if err := configReload(sess, rollbackCfg); err != nil {
-				glog.Errorf("Commit[%s]: Reload failure on commit timer expiry: %s",
+			glog.Infof("Commit:[%s]:Timer timeout %s, Rollback initiated.", sess.token, timeouttime)
+			if err := rollbackCommit(sess); err != nil {
+				glog.Errorf("Commit[%s]: Rollback failure on commit timer expiry: %s",
 					sess.token, err.Error())
 			}
 
 			//Clean up
-			cleanCS()
+			cleanCS(&sess.configSession)
 		}
 
 	}()
 	return true, CsStatusCommitSuccess{}
 }
 
-func configReload(sess *Session, rollbackCfg string) error {
+// rollbackCommit reverts the changes of the commit pending confirmation, by
+// applying the inverse change-set captured at the commit.
+func rollbackCommit(sess *Session) error {
 
-	msg := [...]string{"Configure session commit aborted. Configuration 'reload' in progress."}
+	msg := [...]string{"Configure session commit aborted. Configuration rollback in progress."}
 	queryResult := transformer.HostQuery("infra_host.broadcast_msg", msg)
 	if queryResult.Err != nil {
 		glog.Errorf("Commit:[%s] Broadcast message failure", sess.token)
 	}
 
-	options := [...]string{rollbackCfg, "running-configuration", "OVERWRITE", sess.username}
-	queryResult = transformer.HostQuery("cfg_mgmt.save", options)
-	if queryResult.Err != nil {
-		return errors.New(queryResult.Err.Error())
-	}
-	return nil
+	return rollbackCS(&sess.configSession)
 }
 
 func abortSessionCommit(sess *Session) error {
 	//rollback if commit timer is running.
 	sess.configSession.commitTimer.Stop()
 	sess.configSession.commitCh <- true
-	if err := configReload(sess, sess.rollbackCfg); err != nil {
-		glog.Errorf("Commit[%s]: Configuration reload failure on commit abort : %s",
+	if err := rollbackCommit(sess); err != nil {
+		glog.Errorf("Commit[%s]: Configuration rollback failure on commit abort : %s",
 			sess.token, err.Error())
-		return errors.New("Configuration reload failure on commit timeout rollback")
+		return errors.New("Configuration rollback failure on commit abort")
 	}
 	return nil
 }
 
+// processCommitSchedule schedules the commit at applyAt. The pre-commit hooks
+// are run at the commit.
+func processCommitSchedule(sess *Session, label string, timeout int,
+	applyAt time.Time) (bool, CsStatus) {
+
+	if timeout >= cs_COMMIT_CONFIRM_TIMEOUT_MIN {
+		err := tlerr.New("Commit confirm timeout is not supported with a scheduled commit")
+		return false, CsStatusCommitFailure{Err: err}
+	}
+	if !applyAt.After(time.Now()) {
+		err := tlerr.InvalidArgs("Scheduled commit time %v has passed", applyAt)
+		return false, CsStatusCommitFailure{Err: err}
+	}
+
+	if err := scheduleCS(sess.name, label, applyAt); err != nil {
+		return false, CsStatusCommitFailure{Err: err}
+	}
+	return true, CsStatusCommitSuccess{}
+}
+
 func (sess *Session) Commit(label string,
-	timeout int, confirm bool) (bool, CsStatus) {
-	glog.Infof("Commit:[%s]:Begin: label: %s, timeout: %d", sess.token, label, timeout)
+	timeout int, confirm bool, opts ...CommitOpts) (bool, CsStatus) {
+	glog.Infof("Commit:[%s]:Begin: label: %s, timeout: %d, %#v", sess.token,
+		label, timeout, opts)
 
 	var success bool
 	var status CsStatus
 
+	var applyAt time.Time
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case COapplyAt:
+			applyAt = opt.Time
+		default:
+			glog.Warningf("Commit: Invalid Option. %v", opt)
+		}
+	}
+
 	if sess.IsConfigSession() {
 		if isCpLabelExist(label) {
 			errSh := tlerr.InvalidArgs("label: %s already exist. Choose another label", label)
@@ -163,7 +191,6 @@ func (sess *Session) Commit(label string,
 		case cs_STATE_ACTIVE:
 
 			var commitConfirmTimer bool
-			var rollbackCfg string
 			var err error
 
 			if confirm {
@@ -179,15 +206,34 @@ func (sess *Session) Commit(label string,
 				break
 			}
 
+			if sess.configSession.commitState == cs_STATE_SCHEDULED {
+				glog.Infof("Commit:[%s] Commit scheduled.", sess.token)
+				err := tlerr.New("Commit scheduled at %v",
+					sess.configSession.applyAt)
+				success = false
+				status = CsStatusCommitFailure{Err: err}
+				break
+			}
+
+			if !applyAt.IsZero() {
+				success, status = processCommitSchedule(sess, label, timeout,
+					applyAt)
+				break
+			}
+
+			var info *CommitInfo
+			if info, err = newCommitInfo(sess, label); err == nil {
+				err = runPreCommitHooks(info)
+			}
+			if err != nil {
+				success = false
+				status = CsStatusCommitFailure{Err: err}
+				break
+			}
+
 			if timeout >= cs_COMMIT_CONFIRM_TIMEOUT_MIN {
-				//read current config db for rollback
+				// The changes are captured at commit, for rollback.
 				commitConfirmTimer = true
-				//save current running config for rollback.
-				if rollbackCfg, err = saveRunningConfig(sess.username); err != nil {
-					status = CsStatusCommitFailure{Err: err}
-					break
-				}
-				sess.SetRollbackCfg(rollbackCfg)
 			}
 			var errSc, errSh error
 			if err, errSc, errSh = commitCS(sess.name, label, commitConfirmTimer); err != nil {
@@ -200,9 +246,12 @@ func (sess *Session) Commit(label string,
 			// If session timer given start the timer and return success.
 
 			if commitConfirmTimer {
-				success, status = processCommitTimer(sess, timeout, rollbackCfg)
+				// The post-commit hooks are run on confirm
+				sess.configSession.setCommitInfo(info)
+				success, status = processCommitTimer(sess, timeout)
 
 			} else {
+				runPostCommitHooks(info)
 				if (errSc == nil) && (errSh == nil) {
 					status = CsStatusCommitSuccess{}
 				} else {
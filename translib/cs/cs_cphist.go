@@ -24,8 +24,12 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/Azure/sonic-mgmt-common/translib/transformer"
+	"github.com/Azure/sonic-mgmt-common/translib/db"
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
 	"github.com/golang/glog"
 )
 
@@ -43,14 +47,38 @@ type CpHistEntry struct {
 	User   string `json:"user"`
 	Origin string `json:"origin"`
 	Time   int64  `json:"time"`
+	Pinned bool   `json:"pinned,omitempty"` // Never rotated out
+	Size   int64  `json:"size,omitempty"`   // Of the checkpoint file
+}
+
+// CheckpointInfo is the checkpoint history entry, with its file details.
+type CheckpointInfo struct {
+	CpHistEntry
+	Path       string
+	Compressed bool
 }
 
 type CpHistEntries struct {
 	CpHistEntries []CpHistEntry `json:"cphistentries"`
 }
 
+// MAX_HIST is the default CpRetention.MaxCount
 const MAX_HIST = 10
 
+// CpRetention is the checkpoint retention policy. The oldest checkpoints,
+// which are not pinned, are rotated out while any of the limits is exceeded.
+// The latest checkpoint is never rotated out. (Zero: No limit)
+type CpRetention struct {
+	MaxCount int           // Number of checkpoints
+	MaxAge   time.Duration // Age of a checkpoint
+	MaxSize  int64         // Total size of the checkpoint files
+}
+
+var cpRetention = CpRetention{MaxCount: MAX_HIST}
+
+// cpHistMutex protects the cpHistEnts, cpRetention, and cpStore
+var cpHistMutex sync.Mutex
+
 var cpHistEnts CpHistEntries
 var is_hist_loaded bool
 
@@ -62,6 +90,9 @@ func init() {
 }
 
 func isCpLabelExist(label string) bool {
+	cpHistMutex.Lock()
+	defer cpHistMutex.Unlock()
+
 	if len(label) == 0 {
 		return false
 	}
@@ -78,21 +109,84 @@ func (c *CpHistEntries) AddCpHistEntry(entry CpHistEntry) {
 }
 
 func SaveCpConfig(cpName string, user string) error {
-	q_result := transformer.HostQuery("cphist_mgmt.cp_cfg_save", cpName, user)
-	if q_result.Err != nil {
-		glog.Errorf("check point config save Query failed: err=%+v", q_result.Err)
-		return q_result.Err
-	}
-	return nil
+	return cpStore.SaveConfig(cpName, user)
 }
 
 func DeleteCpConfig(cpName string, user string) error {
-	q_result := transformer.HostQuery("cphist_mgmt.cp_cfg_remove", cpName, user)
-	if q_result.Err != nil {
-		glog.Errorf("check point config delete Query failed: err=%+v", q_result.Err)
-		return q_result.Err
+	return cpStore.RemoveConfig(cpName, user)
+}
+
+// SetCpRetention sets the checkpoint retention policy. It is applied on the
+// next checkpoint creation.
+func SetCpRetention(r CpRetention) {
+	glog.Infof("SetCpRetention: %+v", r)
+
+	cpHistMutex.Lock()
+	defer cpHistMutex.Unlock()
+
+	cpRetention = r
+}
+
+// GetCpRetention returns the checkpoint retention policy.
+func GetCpRetention() CpRetention {
+	cpHistMutex.Lock()
+	defer cpHistMutex.Unlock()
+
+	return cpRetention
+}
+
+// PinCheckpoint pins (or unpins) the labelled checkpoint (by label, or id), so
+// that it is never rotated out.
+func PinCheckpoint(labelOrID string, pin bool, user string) error {
+	glog.Infof("PinCheckpoint: %s pin: %v user: %s", labelOrID, pin, user)
+
+	cpHistMutex.Lock()
+	defer cpHistMutex.Unlock()
+
+	if !is_hist_loaded {
+		LoadCpHistEntries(checkPointHistPath)
+		is_hist_loaded = true
 	}
-	return nil
+
+	for i := range cpHistEnts.CpHistEntries {
+		ent := &cpHistEnts.CpHistEntries[i]
+		if len(labelOrID) == 0 || (ent.Label != labelOrID && ent.Id != labelOrID) {
+			continue
+		}
+		if pin && len(ent.Label) == 0 {
+			return tlerr.InvalidArgs("Only a labelled checkpoint can be pinned")
+		}
+		ent.Pinned = pin
+		return UpdateCpHistFile(user)
+	}
+
+	return tlerr.NotFound("Checkpoint %s not found", labelOrID)
+}
+
+// ListCheckpoints returns the checkpoints, oldest first.
+func ListCheckpoints() ([]CheckpointInfo, error) {
+	cpHistMutex.Lock()
+	defer cpHistMutex.Unlock()
+
+	if !is_hist_loaded {
+		LoadCpHistEntries(checkPointHistPath)
+		is_hist_loaded = true
+	}
+
+	cpInfos := make([]CheckpointInfo, 0, len(cpHistEnts.CpHistEntries))
+	for _, ent := range cpHistEnts.CpHistEntries {
+		cpInfo := CheckpointInfo{CpHistEntry: ent}
+		cpInfo.Path = (&db.CommitIdDbDs{CommitID: ent.fileName()}).Path()
+		cpInfo.Compressed = strings.HasSuffix(cpInfo.Path, db.CheckpointGzExt)
+		if fi, err := os.Stat(cpInfo.Path); err == nil {
+			cpInfo.Size = fi.Size()
+		} else {
+			glog.Warningf("ListCheckpoints: %s: %v", cpInfo.Path, err)
+		}
+		cpInfos = append(cpInfos, cpInfo)
+	}
+
+	return cpInfos, nil
 }
 
 func CreateCpHistEntry(cpName string, id string, user string, origin string, time int64) error {
@@ -105,22 +199,14 @@ func CreateCpHistEntry(cpName string, id string, user string, origin string, tim
 		fileName = id
 	}
 
+	cpHistMutex.Lock()
+	defer cpHistMutex.Unlock()
+
 	if !is_hist_loaded {
 		LoadCpHistEntries(checkPointHistPath)
 		is_hist_loaded = true
 	}
 
-	if len(cpHistEnts.CpHistEntries) >= MAX_HIST {
-		var delFileName string
-		if len(cpHistEnts.CpHistEntries[0].Label) > 0 {
-			delFileName = cpHistEnts.CpHistEntries[0].Label
-		} else {
-			delFileName = cpHistEnts.CpHistEntries[0].Id
-		}
-		DeleteCpConfig(delFileName, cpHistEnts.CpHistEntries[0].User)
-		cpHistEnts.DeleteFirstCpHistEntry()
-	}
-
 	err := SaveCpConfig(fileName, user)
 	if err != nil {
 		return err
@@ -132,7 +218,11 @@ func CreateCpHistEntry(cpName string, id string, user string, origin string, tim
 	entry.User = user
 	entry.Origin = origin
 	entry.Time = time
+	if fi, err := os.Stat((&db.CommitIdDbDs{CommitID: fileName}).Path()); err == nil {
+		entry.Size = fi.Size()
+	}
 	cpHistEnts.AddCpHistEntry(entry)
+	applyCpRetention()
 	err = UpdateCpHistFile(user)
 	if err != nil {
 		return err
@@ -150,7 +240,7 @@ func (c *CpHistEntries) DeleteCpHistEntry(entry CpHistEntry) {
 		if v == entry {
 			//c.CpHistEntries = append(c.CpHistEntries[0:idx], c.CpHistEntries[idx+1:]...)
 			c.CpHistEntries[idx] = c.CpHistEntries[len(c.CpHistEntries)-1]
-			c.CpHistEntries[len(c.CpHistEntries)-1] = CpHistEntry{"", "", "", "", 0}
+			c.CpHistEntries[len(c.CpHistEntries)-1] = CpHistEntry{}
 			c.CpHistEntries = c.CpHistEntries[:len(c.CpHistEntries)-1]
 		}
 
@@ -184,11 +274,40 @@ func UpdateCpHistFile(user string) error {
 		glog.Errorf("converting to json data failed: err=%+v", err)
 		return err
 	}
-	js := string(jData)
-	q_result := transformer.HostQuery("cphist_mgmt.cp_hist_save", user, js)
-	if q_result.Err != nil {
-		glog.Errorf("check point config history save Query failed: err=%+v", q_result.Err)
-		return q_result.Err
+	return cpStore.SaveHist(user, jData)
+}
+
+// applyCpRetention rotates out the oldest checkpoints (not pinned), while the
+// cpRetention limits are exceeded. Caller holds the cpHistMutex.
+func applyCpRetention() {
+	r := cpRetention
+	ents := cpHistEnts.CpHistEntries
+	count := len(ents)
+	var total int64
+	for _, ent := range ents {
+		total += ent.Size
 	}
-	return nil
+
+	now := time.Now()
+	kept := make([]CpHistEntry, 0, len(ents))
+	for i, ent := range ents {
+		overCount := r.MaxCount > 0 && count > r.MaxCount
+		overSize := r.MaxSize > 0 && total > r.MaxSize
+		expired := r.MaxAge > 0 && now.Sub(time.Unix(0, ent.Time)) > r.MaxAge
+
+		if ent.Pinned || (i == len(ents)-1) || !(overCount || overSize || expired) {
+			kept = append(kept, ent)
+			continue
+		}
+
+		glog.Infof("applyCpRetention: Rotating out %s (count %d, size %d)",
+			ent.fileName(), count, total)
+		if err := DeleteCpConfig(ent.fileName(), ent.User); err != nil {
+			glog.Warningf("applyCpRetention: %s: err %v", ent.fileName(), err)
+		}
+		count--
+		total -= ent.Size
+	}
+
+	cpHistEnts.CpHistEntries = kept
 }
@@ -0,0 +1,222 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2022 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// Config Session Checkpoint Storage
+
+package cs
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/sonic-mgmt-common/translib/db"
+	"github.com/Azure/sonic-mgmt-common/translib/transformer"
+	"github.com/golang/glog"
+)
+
+// CpStore is the storage backend of the checkpoints (the saved running
+// config files), and the checkpoint history.
+type CpStore interface {
+	// Dir returns the directory of the checkpoint files, and the history.
+	Dir() string
+
+	// SaveConfig saves the running config as the checkpoint cpName.
+	SaveConfig(cpName string, user string) error
+
+	// RemoveConfig removes the checkpoint cpName.
+	RemoveConfig(cpName string, user string) error
+
+	// SaveHist saves the checkpoint history (json).
+	SaveHist(user string, data []byte) error
+}
+
+var cpStore CpStore = hostCpStore{}
+
+// SetCpStore sets the checkpoint storage backend, and (re)loads the
+// checkpoint history from it.
+func SetCpStore(store CpStore) {
+	glog.Infof("SetCpStore: %s", store.Dir())
+
+	cpHistMutex.Lock()
+	defer cpHistMutex.Unlock()
+
+	cpStore = store
+	checkPointPath = store.Dir()
+	checkPointHistPath = checkPointPath + "cp_hist.json"
+	db.SetCheckpointsDir(checkPointPath)
+
+	cpHistEnts = CpHistEntries{}
+	LoadCpHistEntries(checkPointHistPath)
+	is_hist_loaded = true
+}
+
+// hostCpStore is the default CpStore. The host service saves the checkpoints
+// in the /etc/sonic/checkpoints/ directory.
+type hostCpStore struct {
+}
+
+func (s hostCpStore) Dir() string {
+	return "/etc/sonic/checkpoints/"
+}
+
+func (s hostCpStore) SaveConfig(cpName string, user string) error {
+	q_result := transformer.HostQuery("cphist_mgmt.cp_cfg_save", cpName, user)
+	if q_result.Err != nil {
+		glog.Errorf("check point config save Query failed: err=%+v", q_result.Err)
+		return q_result.Err
+	}
+	return nil
+}
+
+func (s hostCpStore) RemoveConfig(cpName string, user string) error {
+	q_result := transformer.HostQuery("cphist_mgmt.cp_cfg_remove", cpName, user)
+	if q_result.Err != nil {
+		glog.Errorf("check point config delete Query failed: err=%+v", q_result.Err)
+		return q_result.Err
+	}
+	return nil
+}
+
+func (s hostCpStore) SaveHist(user string, data []byte) error {
+	q_result := transformer.HostQuery("cphist_mgmt.cp_hist_save", user, string(data))
+	if q_result.Err != nil {
+		glog.Errorf("check point config history save Query failed: err=%+v", q_result.Err)
+		return q_result.Err
+	}
+	return nil
+}
+
+// dirCpStore saves the checkpoints in a directory, optionally compressed
+// (gzip).
+type dirCpStore struct {
+	dir      string
+	compress bool
+}
+
+// NewDirCpStore returns a CpStore, which saves the checkpoints (compressed,
+// if compress) in the dir.
+func NewDirCpStore(dir string, compress bool) CpStore {
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	return &dirCpStore{dir: dir, compress: compress}
+}
+
+func (s *dirCpStore) Dir() string {
+	return s.dir
+}
+
+// SaveConfig saves the running config in the config_db.json format.
+func (s *dirCpStore) SaveConfig(cpName string, user string) error {
+	tblM, err := getConfig(nil)
+	if err != nil {
+		return err
+	}
+
+	config := make(map[string]map[string]map[string]interface{}, len(tblM))
+	for ts, table := range tblM {
+		keys, _ := table.GetKeys()
+		entries := make(map[string]map[string]interface{}, len(keys))
+		for _, key := range keys {
+			v, _ := table.GetEntry(key)
+			fields := make(map[string]interface{}, len(v.Field))
+			for f, fv := range v.Field {
+				if strings.HasSuffix(f, "@") {
+					fields[strings.TrimSuffix(f, "@")] = strings.Split(fv, ",")
+				} else {
+					fields[f] = fv
+				}
+			}
+			entries[strings.Join(key.Comp, "|")] = fields
+		}
+		config[ts.Name] = entries
+	}
+
+	data, err := json.MarshalIndent(config, "", "    ")
+	if err != nil {
+		glog.Errorf("dirCpStore.SaveConfig: %s: json err %v", cpName, err)
+		return err
+	}
+
+	path, stale := s.dir+cpName+db.CheckpointExt, s.dir+cpName+db.CheckpointGzExt
+	if s.compress {
+		path, stale = stale, path
+	}
+	if err = s.writeFile(path, data, s.compress); err != nil {
+		return err
+	}
+	os.Remove(stale)
+
+	glog.Infof("dirCpStore.SaveConfig: %s: user %s", path, user)
+	return nil
+}
+
+func (s *dirCpStore) RemoveConfig(cpName string, user string) error {
+	var err error
+	for _, path := range []string{s.dir + cpName + db.CheckpointExt,
+		s.dir + cpName + db.CheckpointGzExt} {
+		if e := os.Remove(path); e != nil && !os.IsNotExist(e) {
+			glog.Errorf("dirCpStore.RemoveConfig: %s: err %v", path, e)
+			err = e
+		}
+	}
+	return err
+}
+
+func (s *dirCpStore) SaveHist(user string, data []byte) error {
+	return s.writeFile(s.dir+filepath.Base(checkPointHistPath), data, false)
+}
+
+// writeFile writes the data to a temporary file, which is renamed to the path.
+func (s *dirCpStore) writeFile(path string, data []byte, compress bool) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		glog.Errorf("dirCpStore: MkdirAll %s: err %v", s.dir, err)
+		return err
+	}
+
+	f, err := os.CreateTemp(s.dir, ".cp_tmp_")
+	if err != nil {
+		glog.Errorf("dirCpStore: CreateTemp %s: err %v", s.dir, err)
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if compress {
+		zw := gzip.NewWriter(f)
+		if _, err = zw.Write(data); err == nil {
+			err = zw.Close()
+		}
+	} else {
+		_, err = f.Write(data)
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+
+	if err != nil {
+		glog.Errorf("dirCpStore: %s: err %v", path, err)
+	}
+	return err
+}
@@ -0,0 +1,142 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2022 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// Config Session Diff (Candidate Config vs Running Config)
+
+package cs
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/Azure/sonic-mgmt-common/translib/transformer"
+	"github.com/golang/glog"
+)
+
+type DiffOp string
+
+const (
+	DiffOpAdd    DiffOp = "add"    // Field added
+	DiffOpDelete DiffOp = "delete" // Field deleted
+	DiffOpModify DiffOp = "modify" // Field value modified
+)
+
+// DiffEntry is a field changed in the Candidate Config of the session.
+type DiffEntry struct {
+	Table  string
+	Key    string // Eg: "Ethernet0"
+	Field  string
+	Op     DiffOp
+	Before string // Value in the running config (DiffOpModify, DiffOpDelete)
+	After  string // Value in the candidate config (DiffOpAdd, DiffOpModify)
+
+	// SONiC YANG path of the Field, and the OpenConfig YANG xpaths (without
+	// the list keys) mapped to it. (DOyangPath only)
+	Path     string
+	OcXpaths []string
+}
+
+type DiffOpts interface {
+}
+
+// DOyangPath option renders the diff as YANG paths too.
+type DOyangPath struct {
+}
+
+// Diff returns the pending changes of the session, in the order the keys were
+// first written (fields sorted by name).
+func (sess *Session) Diff(opts ...DiffOpts) ([]DiffEntry, error) {
+	glog.Infof("Diff:[%s]:Begin: %#v", sess.token, opts)
+
+	var doYangPath bool
+	for _, opt := range opts {
+		switch opt.(type) {
+		case DOyangPath:
+			doYangPath = true
+		default:
+			glog.Warningf("Diff: Invalid Option. %v", opt)
+		}
+	}
+
+	if !sess.IsConfigSession() || sess.ccDB == nil {
+		glog.Errorf("Diff: Invalid Session")
+		return nil, tlerr.TranslibInvalidSession{}
+	}
+
+	txDiff, err := sess.ccDB.TxDiff()
+	if err != nil {
+		glog.Errorf("Diff:[%s]: TxDiff err %s", sess.token, err)
+		return nil, err
+	}
+
+	var diff []DiffEntry
+	for _, txd := range txDiff {
+		fields := make(map[string]bool)
+		for f := range txd.Before.Field {
+			fields[f] = true
+		}
+		for f := range txd.After.Field {
+			fields[f] = true
+		}
+
+		names := make([]string, 0, len(fields))
+		for f := range fields {
+			names = append(names, f)
+		}
+		sort.Strings(names)
+
+		key := strings.Join(txd.Key.Comp, "|")
+		for _, f := range names {
+			before, inBefore := txd.Before.Field[f]
+			after, inAfter := txd.After.Field[f]
+
+			de := DiffEntry{Table: txd.Ts.Name, Key: key, Field: f,
+				Before: before, After: after}
+			switch {
+			case !inBefore:
+				de.Op = DiffOpAdd
+			case !inAfter:
+				de.Op = DiffOpDelete
+			case before != after:
+				de.Op = DiffOpModify
+			default:
+				continue
+			}
+
+			if doYangPath {
+				// NULL is the placeholder field of an entry with no fields.
+				yf := f
+				if f == "NULL" {
+					yf = ""
+				}
+				if de.Path, de.OcXpaths, err = transformer.GetDbYangPaths(
+					txd.Ts.Name, txd.Key, yf); err != nil {
+					glog.Warningf("Diff:[%s]: %s|%s %s: No YANG path: %s",
+						sess.token, txd.Ts.Name, key, f, err)
+				}
+			}
+
+			diff = append(diff, de)
+		}
+	}
+
+	glog.Infof("Diff:[%s]:End: %d changes", sess.token, len(diff))
+	return diff, nil
+}
@@ -51,18 +51,27 @@ func (sess *Session) GetConfigDB(opts *db.Options) (*db.DB, bool, func(),
 	} else {
 
 		glog.Infof("GetConfigDB: Session DB")
-		d = sess.ccDB
+		var release func()
+		if d, release, err = sess.configSession.acquireCCDB(); err != nil {
+			glog.Warningf("GetConfigDB[%s]: %s", sess.token, err)
+			return nil, false, nil, err
+		}
 		isCS = true
 		cleanup = func() {
+			defer release()
+
 			sess.configSession.UpdateLastActiveTime()
 
-			// Rollback the stale savepoint if exists (happens when the app module panics)
+			// Rollback the stale savepoints if exist (happens when the app module panics)
 			if d.HasSP() {
-				glog.Infof("Attempting to rollback the stale savepoint...")
-				if rbErr := d.Rollback2SP(); rbErr != nil {
+				glog.Infof("Attempting to rollback the stale savepoints %q...", d.SPNames())
+				if rbErr := d.RollbackAllSP(); rbErr != nil {
 					glog.Errorf("Failed to rollback the stale savepoint: %v", rbErr)
 				}
 			}
+
+			// Persist the changes, to be restored on a restart
+			sess.configSession.persist()
 		}
 
 	}
@@ -0,0 +1,189 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2022 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// Config Session Commit Hooks
+
+package cs
+
+import (
+	"sync"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+)
+
+// CommitInfo is the commit of a Config Session, passed to the commit hooks.
+type CommitInfo struct {
+	Name    string // Session name ("": The unnamed session)
+	Token   string
+	User    string
+	Label   string // Checkpoint label (May be empty)
+	Origin  string // "commit", "rollback", "scheduled"
+	Changes []DiffEntry
+}
+
+// PreCommitHook is called before the commit. A non-nil error vetoes the
+// commit, with the error as the reason. Eg: "Do not shut the management
+// interface", "A change ticket label is required".
+type PreCommitHook func(info *CommitInfo) error
+
+// PostCommitHook is called after the commit. (After the confirm, for the
+// commit pending confirmation)
+type PostCommitHook func(info *CommitInfo)
+
+type commitHook struct {
+	name string
+	pre  PreCommitHook
+	post PostCommitHook
+}
+
+var csHookMutex sync.Mutex
+
+// csHooks are the commit hooks, in the order of registration.
+var csHooks []commitHook
+
+// RegisterPreCommitHook registers the pre-commit hook, by a unique name.
+func RegisterPreCommitHook(name string, hook PreCommitHook) error {
+	return registerCommitHook(commitHook{name: name, pre: hook})
+}
+
+// RegisterPostCommitHook registers the post-commit hook, by a unique name.
+func RegisterPostCommitHook(name string, hook PostCommitHook) error {
+	return registerCommitHook(commitHook{name: name, post: hook})
+}
+
+// UnregisterCommitHook unregisters the (pre, or post) commit hook.
+func UnregisterCommitHook(name string) {
+	glog.Infof("UnregisterCommitHook: %s", name)
+
+	csHookMutex.Lock()
+	defer csHookMutex.Unlock()
+
+	for i := range csHooks {
+		if csHooks[i].name == name {
+			csHooks = append(csHooks[:i:i], csHooks[i+1:]...)
+			return
+		}
+	}
+}
+
+func registerCommitHook(hook commitHook) error {
+	glog.Infof("registerCommitHook: %s", hook.name)
+
+	if len(hook.name) == 0 || (hook.pre == nil && hook.post == nil) {
+		return tlerr.InvalidArgs("Commit hook name, and func are required")
+	}
+
+	csHookMutex.Lock()
+	defer csHookMutex.Unlock()
+
+	for i := range csHooks {
+		if csHooks[i].name == hook.name {
+			return tlerr.InvalidArgs("Commit hook %s already registered",
+				hook.name)
+		}
+	}
+
+	csHooks = append(csHooks, hook)
+	return nil
+}
+
+func getCommitHooks() []commitHook {
+	csHookMutex.Lock()
+	defer csHookMutex.Unlock()
+
+	return append([]commitHook(nil), csHooks...)
+}
+
+// newCommitInfo returns the commit of the session (with its pending
+// changes), or nil if no commit hooks are registered.
+func newCommitInfo(sess *Session, label string) (*CommitInfo, error) {
+	if len(getCommitHooks()) == 0 {
+		return nil, nil
+	}
+
+	changes, err := sess.Diff()
+	if err != nil {
+		return nil, err
+	}
+
+	origin := sess.origin
+	if len(origin) == 0 {
+		origin = cs_ORIGIN_COMMIT
+	}
+
+	return &CommitInfo{Name: sess.name, Token: sess.token,
+		User: sess.username, Label: label, Origin: origin,
+		Changes: changes}, nil
+}
+
+// runPreCommitHooks returns CsCommitVetoed from the first hook to veto.
+func runPreCommitHooks(info *CommitInfo) error {
+	if info == nil {
+		return nil
+	}
+
+	for _, hook := range getCommitHooks() {
+		if hook.pre == nil {
+			continue
+		}
+		if err := hook.callPre(info); err != nil {
+			glog.Warningf("Commit:[%s]: Vetoed by %s: %s", info.Token,
+				hook.name, err)
+			return CsCommitVetoed{Hook: hook.name, Reason: err.Error()}
+		}
+	}
+	return nil
+}
+
+func runPostCommitHooks(info *CommitInfo) {
+	if info == nil {
+		return
+	}
+
+	for _, hook := range getCommitHooks() {
+		if hook.post != nil {
+			hook.callPost(info)
+		}
+	}
+}
+
+// callPre runs the pre-commit hook. A panic is a veto.
+func (hook *commitHook) callPre(info *CommitInfo) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			glog.Errorf("Commit:[%s]: Pre-commit hook %s: panic: %v",
+				info.Token, hook.name, r)
+			err = tlerr.New("Pre-commit hook %s: Internal error", hook.name)
+		}
+	}()
+	return hook.pre(info)
+}
+
+// callPost runs the post-commit hook. A panic is logged, and the remaining
+// hooks are called.
+func (hook *commitHook) callPost(info *CommitInfo) {
+	defer func() {
+		if r := recover(); r != nil {
+			glog.Errorf("Commit:[%s]: Post-commit hook %s: panic: %v",
+				info.Token, hook.name, r)
+		}
+	}()
+	hook.post(info)
+}
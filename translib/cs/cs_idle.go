@@ -0,0 +1,155 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2022 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// Config Session Idle Timeout
+
+package cs
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// CsIdleConfig is the Idle Timeout of the Config Sessions. The sessions (not
+// pending a commit confirm) idle for the Timeout are aborted by the reaper.
+type CsIdleConfig struct {
+	Timeout  time.Duration // Zero: Disabled
+	Warning  time.Duration // Warn the terminal, this much before the Timeout
+	Interval time.Duration // Reaper period (Zero: Timeout/10, 1s - 1m)
+}
+
+// csSystemUser is the sender of the messages to the terminal of a session.
+const csSystemUser = "system"
+
+var csIdleMutex sync.Mutex
+var csIdleCfg CsIdleConfig
+var csIdleStop chan struct{}
+
+// SetIdleTimeout sets the Idle Timeout, and (re)starts the reaper.
+func SetIdleTimeout(cfg CsIdleConfig) {
+	glog.Infof("SetIdleTimeout: %+v", cfg)
+
+	csIdleMutex.Lock()
+	defer csIdleMutex.Unlock()
+
+	if csIdleStop != nil {
+		close(csIdleStop)
+		csIdleStop = nil
+	}
+
+	if cfg.Timeout <= 0 {
+		csIdleCfg = CsIdleConfig{}
+		return
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = cfg.Timeout / 10
+		if cfg.Interval < time.Second {
+			cfg.Interval = time.Second
+		} else if cfg.Interval > time.Minute {
+			cfg.Interval = time.Minute
+		}
+	}
+
+	csIdleCfg = cfg
+	csIdleStop = make(chan struct{})
+	go idleReaper(cfg, csIdleStop)
+}
+
+// GetIdleTimeout returns the Idle Timeout.
+func GetIdleTimeout() CsIdleConfig {
+	csIdleMutex.Lock()
+	defer csIdleMutex.Unlock()
+
+	return csIdleCfg
+}
+
+func idleReaper(cfg CsIdleConfig, stop <-chan struct{}) {
+	glog.Infof("idleReaper: Begin: %+v", cfg)
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			glog.Infof("idleReaper: End")
+			return
+		case now := <-ticker.C:
+			reapIdleSessions(cfg, now)
+		}
+	}
+}
+
+// reapIdleSessions warns the terminals of the sessions about to be idle for
+// the Timeout, and aborts the ones idle for it.
+func reapIdleSessions(cfg CsIdleConfig, now time.Time) {
+	var warn, abort []Session
+
+	csMutex.Lock()
+	for _, ucs := range csSessions {
+		if ucs.commitState != cs_STATE_None {
+			// Pending commit confirm has its own timer
+			continue
+		}
+
+		if ucs.users != 0 || ucs.reserved {
+			// In use, so not idle
+			continue
+		}
+
+		idle := now.Sub(ucs.lastActiveTime)
+		if idle >= cfg.Timeout {
+			// Copy before the abort, to still reach its terminal. (The
+			// reserveCS does not wait, as no request uses the ccDB.)
+			abort = append(abort, Session{configSession: *ucs})
+			reserveCS(ucs)
+			if _, errU := abortCS(ucs); errU != nil {
+				glog.Warningf("reapIdleSessions[%s]: unlock err %v", ucs.token, errU)
+			}
+		} else if cfg.Warning > 0 && !ucs.idleWarned &&
+			idle >= cfg.Timeout-cfg.Warning {
+			ucs.idleWarned = true
+			warn = append(warn, Session{configSession: *ucs})
+		}
+	}
+	csMutex.Unlock()
+
+	for i := range warn {
+		sess := &warn[i]
+		remaining := cfg.Timeout - now.Sub(sess.lastActiveTime)
+		sess.SendMesg(fmt.Sprintf("The configure session will be aborted "+
+			"if idle for %v more", remaining.Round(time.Second)), csSystemUser)
+	}
+
+	for i := range abort {
+		sess := &abort[i]
+		idle := now.Sub(sess.lastActiveTime).Round(time.Second)
+
+		sess.SendMesg(fmt.Sprintf("The configure session was aborted "+
+			"after being idle for %v", idle), csSystemUser)
+
+		glog.Warningf("AUDIT: config session %q (token %s) of user %s "+
+			"aborted after being idle for %v", sess.name, sess.token,
+			sess.username, idle)
+	}
+}
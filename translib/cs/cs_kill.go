@@ -54,7 +54,7 @@ func (sess *Session) Kill(killActive bool, user string) CsStatus {
 			//rollback if commit timer is running.
 			if sess.configSession.commitState == cs_STATE_CONFIRM_TIMER {
 				if err = abortSessionCommit(sess); err != nil {
-					status = CsStatusInternalError{Err: errors.New("Config reload failure on session clear")}
+					status = CsStatusInternalError{Err: errors.New("Config rollback failure on session clear")}
 					break
 				}
 			}
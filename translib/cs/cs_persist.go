@@ -0,0 +1,372 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2022 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// Config Session Persistence
+
+package cs
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/db"
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+)
+
+// csPersistTs is the STATE_DB table (keyed by the token) of the persisted
+// Config Sessions. A session is persisted on every change, and restored on
+// the restart of the process.
+var csPersistTs = db.TableSpec{Name: "CONFIG_SESSION_TABLE"}
+
+// csPersistTxTs is the STATE_DB table (keyed by the token) of the pending
+// commands of the persisted Config Sessions. The field "<n>" is the n-th
+// command; only the first tx_len (of csPersistTs) are valid. The commands are
+// persisted incrementally, as they are added.
+var csPersistTxTs = db.TableSpec{Name: "CONFIG_SESSION_TX_TABLE"}
+
+const (
+	csPersistConfirm   = "confirm"   // commit_state: Commit pending confirmation
+	csPersistScheduled = "scheduled" // commit_state: Commit scheduled at apply_at
+)
+
+// RestoreSessions restores the persisted Config Sessions (of the previous
+// instance of the process) in the suspended state, so that the owners can
+// resume, review or abort them. The commits pending confirmation are rolled
+// back, and the scheduled commits rescheduled. It is to be called on startup.
+// The sessions which fail to restore (Eg: The CONFIG_DB is busy) are kept
+// persisted, and reported in the error. Calling it again retries them.
+// The conflicts of a restored session are detected from the restore.
+func RestoreSessions() error {
+	glog.Infof("RestoreSessions: Begin")
+
+	csMutex.Lock()
+	defer csMutex.Unlock()
+
+	pdb, err := db.NewDB(db.Options{DBNo: db.StateDB})
+	if err != nil {
+		glog.Errorf("RestoreSessions: db.NewDB err %s", err)
+		return err
+	}
+	defer pdb.DeleteDB()
+
+	keys, err := pdb.GetKeys(&csPersistTs)
+	if err != nil {
+		glog.Errorf("RestoreSessions: GetKeys err %s", err)
+		return err
+	}
+
+	var restored int
+	var failed []string
+	for _, key := range keys {
+		token := key.Get(0)
+		if findCSByToken(token) != nil {
+			// Restored already
+			continue
+		}
+
+		var ucs *configSession
+		value, err := pdb.GetEntry(&csPersistTs, key)
+		if err == nil {
+			ucs, err = restoreCS(pdb, token, value)
+		}
+
+		if err != nil {
+			glog.Errorf("RestoreSessions[%s]: err %s", token, err)
+			failed = append(failed, token)
+		} else if ucs != nil {
+			csSessions[ucs.name] = ucs
+			restored++
+		} else {
+			deletePersistedCS(pdb, token)
+		}
+	}
+
+	glog.Infof("RestoreSessions: End: %d of %d, failed %v", restored,
+		len(keys), failed)
+
+	if len(failed) != 0 {
+		return tlerr.New("Failed to restore the config sessions %s",
+			strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// persist saves the Config Session of which cs is a copy.
+func (cs *configSession) persist() {
+	csMutex.Lock()
+	defer csMutex.Unlock()
+
+	if ucs := lookupCS(cs); ucs != nil {
+		persistCS(ucs)
+	}
+}
+
+// persistCS saves the Config Session attributes, and the pending commands of
+// its Transaction (or the rollback set, when the commit is pending
+// confirmation). Only the commands added (or changed by a rollback to a
+// savepoint) since the last persistCS are saved. Caller holds the csMutex.
+func persistCS(ucs *configSession) error {
+	var pdb *db.DB
+
+	cmds, from, err := ucs.ccDB.TxCmdsSince(ucs.persistedCmds)
+	if err != nil {
+		glog.Errorf("persistCS[%s]: Tx err %s", ucs.token, err)
+		return err
+	}
+
+	txValue := db.Value{Field: make(map[string]string, len(cmds))}
+	for i := range cmds {
+		var cmd []byte
+		if cmd, err = json.Marshal(cmds[i]); err != nil {
+			glog.Errorf("persistCS[%s]: Tx err %s", ucs.token, err)
+			ucs.persistedCmds = from
+			return err
+		}
+		txValue.Field[strconv.Itoa(from+i)] = string(cmd)
+	}
+	txLen := from + len(cmds)
+
+	value := db.Value{Field: map[string]string{
+		"name":             ucs.name,
+		"username":         ucs.username,
+		"roles":            strings.Join(ucs.roles, ","),
+		"start_time":       formatTimeCS(ucs.startTime),
+		"last_active_time": formatTimeCS(ucs.lastActiveTime),
+		"tx_len":           strconv.Itoa(txLen),
+	}}
+	if len(ucs.origin) != 0 {
+		value.Field["origin"] = ucs.origin
+	}
+	if ucs.ccDB.Opts.DisableCVLCheck {
+		value.Field["disable_cvl"] = "true"
+	}
+	if ucs.commitState == cs_STATE_CONFIRM_TIMER {
+		var rs []byte
+		if rs, err = json.Marshal(ucs.rollbackSet); err != nil {
+			glog.Errorf("persistCS[%s]: rollbackSet err %s", ucs.token, err)
+			return err
+		}
+		value.Field["commit_state"] = csPersistConfirm
+		value.Field["rollback"] = string(rs)
+	} else if ucs.commitState == cs_STATE_SCHEDULED {
+		value.Field["commit_state"] = csPersistScheduled
+		value.Field["apply_at"] = formatTimeCS(ucs.applyAt)
+		value.Field["apply_label"] = ucs.applyLabel
+	}
+
+	if pdb, err = db.NewDB(db.Options{DBNo: db.StateDB}); err != nil {
+		glog.Errorf("persistCS[%s]: db.NewDB err %s", ucs.token, err)
+		ucs.persistedCmds = from
+		return err
+	}
+	defer pdb.DeleteDB()
+
+	key := db.Key{Comp: []string{ucs.token}}
+
+	// The commands past tx_len are stale; they are removed on a best-effort
+	// basis.
+	if txLen < ucs.persistedCmds {
+		stale := db.Value{Field: make(map[string]string)}
+		for i := txLen; i < ucs.persistedCmds; i++ {
+			stale.Field[strconv.Itoa(i)] = ""
+		}
+		if errD := pdb.DeleteEntryFields(&csPersistTxTs, key,
+			stale); errD != nil {
+			glog.Warningf("persistCS[%s]: DeleteEntryFields err %s",
+				ucs.token, errD)
+		}
+	}
+
+	if len(txValue.Field) != 0 {
+		if err = pdb.ModEntry(&csPersistTxTs, key, txValue); err != nil {
+			glog.Errorf("persistCS[%s]: ModEntry err %s", ucs.token, err)
+			ucs.persistedCmds = from
+			return err
+		}
+	}
+
+	if err = pdb.SetEntry(&csPersistTs, key, value); err != nil {
+		glog.Errorf("persistCS[%s]: SetEntry err %s", ucs.token, err)
+		ucs.persistedCmds = from
+		return err
+	}
+
+	ucs.persistedCmds = txLen
+	return nil
+}
+
+// unpersistCS removes the persisted Config Session. Caller holds the csMutex.
+func unpersistCS(ucs *configSession) error {
+	pdb, err := db.NewDB(db.Options{DBNo: db.StateDB})
+	if err != nil {
+		glog.Errorf("unpersistCS[%s]: db.NewDB err %s", ucs.token, err)
+		return err
+	}
+	defer pdb.DeleteDB()
+
+	return deletePersistedCS(pdb, ucs.token)
+}
+
+// deletePersistedCS removes the persisted Config Session of the token.
+func deletePersistedCS(pdb *db.DB, token string) error {
+	key := db.Key{Comp: []string{token}}
+	err := pdb.DeleteEntry(&csPersistTs, key)
+	if err != nil {
+		glog.Warningf("deletePersistedCS[%s]: DeleteEntry err %s", token, err)
+	}
+	if errT := pdb.DeleteEntry(&csPersistTxTs, key); errT != nil {
+		glog.Warningf("deletePersistedCS[%s]: DeleteEntry Tx err %s", token,
+			errT)
+		if err == nil {
+			err = errT
+		}
+	}
+	return err
+}
+
+// persistedTxCmds returns the persisted pending commands of the token.
+func persistedTxCmds(pdb *db.DB, token string, value db.Value) (
+	[]db.TxCmd, error) {
+
+	txLen, err := strconv.Atoi(value.Get("tx_len"))
+	if err != nil || txLen == 0 {
+		return nil, err
+	}
+
+	txValue, err := pdb.GetEntry(&csPersistTxTs, db.Key{Comp: []string{token}})
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]db.TxCmd, txLen)
+	for i := range cmds {
+		cmd, ok := txValue.Field[strconv.Itoa(i)]
+		if !ok {
+			return nil, tlerr.New("Missing command %d of %d", i, txLen)
+		}
+		if err = json.Unmarshal([]byte(cmd), &cmds[i]); err != nil {
+			return nil, err
+		}
+	}
+	return cmds, nil
+}
+
+// restoreCS returns the suspended Config Session, with its Transaction
+// replayed in a new ccDB, from the persisted value (in pdb). The commit
+// pending confirmation is rolled back instead, and nil is returned. Caller
+// holds the csMutex.
+func restoreCS(pdb *db.DB, token string, value db.Value) (*configSession,
+	error) {
+	name := value.Get("name")
+	username := value.Get("username")
+
+	if ucs := csSessions[name]; ucs != nil {
+		glog.Errorf("restoreCS[%s]: %s exists as %s", token, name, ucs.token)
+		return nil, tlerr.TranslibBusy{}
+	}
+
+	ccDB, err := newCCDB(username)
+	if err != nil {
+		return nil, err
+	}
+	ccDB.Opts.DisableCVLCheck = (value.Get("disable_cvl") == "true")
+
+	if value.Get("commit_state") == csPersistConfirm {
+		// The table locks of the commit may still have a live lease.
+		var rollbackSet []db.TxDiffEntry
+		if err = json.Unmarshal([]byte(value.Get("rollback")),
+			&rollbackSet); err == nil {
+			err = db.ClearTableLocks(token)
+		}
+		if err == nil {
+			err = applyRollbackSet(rollbackSet, ccDB)
+		}
+		csAbortTx(ccDB)
+		ccDB.DeleteDB()
+
+		if err == nil {
+			glog.Warningf("AUDIT: config session %q (token %s) of user %s: "+
+				"commit rolled back, not confirmed before the restart", name,
+				token, username)
+		}
+		return nil, err
+	}
+
+	cmds, err := persistedTxCmds(pdb, token, value)
+	if err == nil {
+		err = ccDB.ReplayTxCmds(cmds)
+	}
+	if err != nil {
+		glog.Errorf("restoreCS[%s]: Tx err %s", token, err)
+		csAbortTx(ccDB)
+		ccDB.DeleteDB()
+		return nil, err
+	}
+
+	var roles []string
+	if r := value.Get("roles"); len(r) != 0 {
+		roles = strings.Split(r, ",")
+	}
+
+	ucs := &configSession{
+		name:     name,
+		token:    token,
+		state:    cs_STATE_SUSPENDED,
+		username: username,
+		roles:    roles,
+		ccDB:     ccDB,
+		origin:   value.Get("origin"),
+		// The commit log is lost on the restart. The commits made since the
+		// session read the keys are detected by the CheckTxConflicts(), with
+		// the keys as read before the restart (restored by ReplayTxCmds).
+		startSeq: csCommitSeq,
+
+		persistedCmds: len(cmds),
+	}
+
+	ucs.startTime = parseTimeCS(value.Get("start_time"))
+	ucs.lastActiveTime = parseTimeCS(value.Get("last_active_time"))
+	ucs.exitTime = time.Now()
+
+	if value.Get("commit_state") == csPersistScheduled {
+		ucs.commitState = cs_STATE_SCHEDULED
+		ucs.applyAt = parseTimeCS(value.Get("apply_at"))
+		ucs.applyLabel = value.Get("apply_label")
+		startApplyTimerCS(ucs)
+	}
+
+	glog.Infof("restoreCS[%s]: %s %s %v: %d cmds", token, name, username,
+		roles, len(cmds))
+	return ucs, nil
+}
+
+func formatTimeCS(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func parseTimeCS(s string) time.Time {
+	ns, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
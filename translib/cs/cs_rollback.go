@@ -0,0 +1,222 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2022 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// Config Session Rollback to a Checkpoint
+
+package cs
+
+import (
+	"github.com/Azure/sonic-mgmt-common/translib/db"
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/Azure/sonic-mgmt-common/translib/utils"
+	"github.com/golang/glog"
+)
+
+// Checkpoint History Entry Origins
+const (
+	cs_ORIGIN_COMMIT    = "commit"
+	cs_ORIGIN_ROLLBACK  = "rollback"
+	cs_ORIGIN_SCHEDULED = "scheduled"
+)
+
+// cpChange is an entry to be written to the running config, to revert it
+// to a checkpoint. (len(value.Field) == 0: Delete)
+type cpChange struct {
+	ts    *db.TableSpec
+	key   db.Key
+	value db.Value
+}
+
+// RollbackToCheckpoint reverts the running config to a checkpoint (by label,
+// or commit-id) in a new Config Session by the name of sess. The diff between
+// the running config and the checkpoint is applied to the Candidate Config
+// (validated by CVL), and committed with the history origin "rollback", and
+// the label. If the timeout is at least cs_COMMIT_CONFIRM_TIMEOUT_MIN, it
+// has to be confirmed by a Commit(label, 0, true) on the session, else the
+// rollback is reverted.
+// Only the tables with a YANG model are reverted.
+func (sess *Session) RollbackToCheckpoint(labelOrID string, label string,
+	timeout int) (bool, CsStatus) {
+
+	glog.Infof("RollbackToCheckpoint:[%s]:Begin: %s label: %s, timeout: %d",
+		sess.name, labelOrID, label, timeout)
+
+	var success bool
+	var status CsStatus
+
+	if sess.IsConfigSession() {
+		glog.Errorf("RollbackToCheckpoint: Session %s exists", sess.name)
+		return false, CsStatusInvalidSession{Tag: ErrTagActive}
+	}
+
+	cpEnt, ok := findCpHistEntry(labelOrID)
+	if !ok {
+		err := tlerr.NotFound("Checkpoint %s not found", labelOrID)
+		return false, CsStatusCommitFailure{Err: err}
+	}
+
+	changes, err := checkpointChanges(cpEnt.fileName())
+	if err != nil {
+		return false, CsStatusCommitFailure{Err: err}
+	}
+
+	cs, err := newCS(sess.name, sess.username, sess.roles, sess.pid)
+	if cs == nil || err != nil {
+		glog.Errorf("RollbackToCheckpoint: New Session: err: %v", err)
+		return false, CsStatusInternalError{Err: err}
+	}
+
+	csMutex.Lock()
+	cs.origin = cs_ORIGIN_ROLLBACK
+	csMutex.Unlock()
+
+	for _, c := range changes {
+		if len(c.value.Field) == 0 {
+			err = cs.ccDB.DeleteEntry(c.ts, c.key)
+		} else {
+			err = cs.ccDB.SetEntry(c.ts, c.key, c.value)
+		}
+		if err != nil {
+			glog.Errorf("RollbackToCheckpoint: %s %v: err: %v", c.ts.Name,
+				c.key, err)
+			deleteCS(sess.name)
+			return false, CsStatusCommitFailure{Err: err}
+		}
+	}
+
+	sess.configSession = *cs
+	if success, status = sess.Commit(label, timeout, false); !success {
+		deleteCS(sess.name)
+	}
+
+	glog.Infof("RollbackToCheckpoint:[%s]:End: %d changes", sess.token,
+		len(changes))
+	return success, status
+}
+
+// findCpHistEntry returns the checkpoint history entry by label, or id.
+func findCpHistEntry(labelOrID string) (CpHistEntry, bool) {
+	cpHistMutex.Lock()
+	defer cpHistMutex.Unlock()
+
+	if !is_hist_loaded {
+		LoadCpHistEntries(checkPointHistPath)
+		is_hist_loaded = true
+	}
+
+	for _, ent := range cpHistEnts.CpHistEntries {
+		if len(labelOrID) != 0 && (ent.Label == labelOrID || ent.Id == labelOrID) {
+			return ent, true
+		}
+	}
+	return CpHistEntry{}, false
+}
+
+// fileName returns the name of the checkpoint config file.
+func (ent *CpHistEntry) fileName() string {
+	if len(ent.Label) > 0 {
+		return ent.Label
+	}
+	return ent.Id
+}
+
+// checkpointChanges returns the changes to the running config to revert it to
+// the checkpoint: Deletes in the child table first order, followed by the
+// Sets in the parent table first order.
+func checkpointChanges(cpName string) ([]cpChange, error) {
+	running, err := getConfig(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cp, err := getConfig(&db.CommitIdDbDs{CommitID: cpName})
+	if err != nil {
+		return nil, err
+	}
+
+	tblNames := make([]string, 0, len(running)+len(cp))
+	for ts := range running {
+		tblNames = append(tblNames, ts.Name)
+	}
+	for ts := range cp {
+		if _, ok := running[ts]; !ok {
+			tblNames = append(tblNames, ts.Name)
+		}
+	}
+
+	// The tables without a YANG model are dropped.
+	sorted, err := utils.SortAsPerTblDeps(tblNames)
+	if err != nil {
+		return nil, err
+	}
+
+	var deletes, sets []cpChange
+	for i := range sorted {
+		ts := db.TableSpec{Name: sorted[i]}
+		rTable, rOk := running[ts]
+		cpTable, cpOk := cp[ts]
+
+		var rKeys, cpKeys []db.Key
+		if rOk {
+			rKeys, _ = rTable.GetKeys()
+		}
+		if cpOk {
+			cpKeys, _ = cpTable.GetKeys()
+		}
+
+		inCp := make(map[string]bool, len(cpKeys))
+		for _, key := range cpKeys {
+			var rv db.Value
+			v, _ := cpTable.GetEntry(key)
+			inCp[key.String()] = true
+			if rOk {
+				rv, _ = rTable.GetEntry(key)
+			}
+			if !rv.Equals(&v) {
+				sets = append(sets, cpChange{ts: &ts, key: key, value: v})
+			}
+		}
+
+		var tDeletes []cpChange
+		for _, key := range rKeys {
+			if !inCp[key.String()] {
+				tDeletes = append(tDeletes, cpChange{ts: &ts, key: key})
+			}
+		}
+		// Child tables first
+		deletes = append(tDeletes, deletes...)
+	}
+
+	glog.Infof("checkpointChanges: %s: %d deletes, %d sets", cpName,
+		len(deletes), len(sets))
+	return append(deletes, sets...), nil
+}
+
+// getConfig reads the CONFIG_DB of the Datastore (running config, if nil).
+func getConfig(ds db.DBDatastore) (map[db.TableSpec]db.Table, error) {
+	d, err := db.NewDB(db.Options{DBNo: db.ConfigDB, IsWriteDisabled: true,
+		ForceNewRedisConnection: true, Datastore: ds})
+	if err != nil {
+		glog.Errorf("getConfig: db.NewDB err %s", err)
+		return nil, err
+	}
+	defer d.DeleteDB()
+
+	return d.GetConfig(nil, nil)
+}
@@ -0,0 +1,223 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2022 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// Config Session Scheduled Commit
+
+package cs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+)
+
+// CancelScheduledCommit cancels the scheduled commit of the session. The
+// Candidate Config can be modified, and committed again.
+func (sess *Session) CancelScheduledCommit() (bool, CsStatus) {
+	glog.Infof("CancelScheduledCommit:[%s]:Begin:", sess.token)
+
+	if !sess.IsConfigSession() {
+		glog.Errorf("CancelScheduledCommit: Invalid Session")
+		return false, CsStatusInvalidSession{}
+	}
+
+	csMutex.Lock()
+	defer csMutex.Unlock()
+
+	ucs := lookupCS(&sess.configSession)
+	if ucs == nil || ucs.commitState != cs_STATE_SCHEDULED {
+		err := tlerr.New("No commit scheduled")
+		return false, CsStatusCommitFailure{Err: err}
+	}
+
+	unscheduleCS(ucs)
+	persistCS(ucs)
+
+	glog.Infof("CancelScheduledCommit:[%s]:End:", sess.token)
+	return true, CsStatusSuccess{}
+}
+
+// ScheduledTime returns the time of the scheduled commit, or the zero time if
+// no commit is scheduled.
+func (sess *Session) ScheduledTime() time.Time {
+	if sess.commitState != cs_STATE_SCHEDULED {
+		return time.Time{}
+	}
+	return sess.applyAt
+}
+
+// scheduleCS schedules the commit of the Config Session at the time. The
+// Candidate Config can not be modified, till the commit is cancelled.
+func scheduleCS(name string, label string, at time.Time) error {
+	glog.Infof("scheduleCS: %s label: %s at: %v", name, label, at)
+
+	csMutex.Lock()
+	defer csMutex.Unlock()
+
+	ucs := csSessions[name]
+	if ucs == nil || ucs.state != cs_STATE_ACTIVE ||
+		ucs.commitState != cs_STATE_None || ucs.reserved {
+		return tlerr.TranslibBusy{}
+	}
+
+	ucs.commitState = cs_STATE_SCHEDULED
+	ucs.applyAt = at
+	ucs.applyLabel = label
+	startApplyTimerCS(ucs)
+	persistCS(ucs)
+
+	return nil
+}
+
+// startApplyTimerCS starts the timer of the scheduled commit (immediately,
+// if the time has passed). Caller holds the csMutex.
+func startApplyTimerCS(ucs *configSession) {
+	token := ucs.token
+	ucs.applyTimer = time.AfterFunc(time.Until(ucs.applyAt), func() {
+		applyScheduledCS(token)
+	})
+}
+
+// unscheduleCS cancels the scheduled commit. Caller holds the csMutex.
+func unscheduleCS(ucs *configSession) {
+	if ucs.applyTimer != nil {
+		ucs.applyTimer.Stop()
+		ucs.applyTimer = nil
+	}
+	ucs.commitState = cs_STATE_None
+	ucs.applyAt = time.Time{}
+	ucs.applyLabel = ""
+}
+
+// applyScheduledCS commits the Config Session scheduled for now. The
+// pre-commit hooks are run first. Then, with the session reserved (the
+// requests using the ccDB are waited for, and new ones rejected), the
+// Candidate Config is re-validated (CVL) against the running config, and
+// committed, if the keys are not modified since the session read them. The
+// commit is recorded in the checkpoint history with the origin "scheduled".
+// On failure, the commit is cancelled (the session is kept for review).
+func applyScheduledCS(token string) {
+	glog.Infof("applyScheduledCS[%s]: Begin", token)
+
+	var err, errSc, errSh error
+	var info *CommitInfo
+
+	csMutex.Lock()
+	ucs := findCSByToken(token)
+	if ucs == nil || ucs.commitState != cs_STATE_SCHEDULED {
+		// Cancelled
+		csMutex.Unlock()
+		glog.Infof("applyScheduledCS[%s]: Not scheduled", token)
+		return
+	}
+	ucs.applyTimer = nil
+	label := ucs.applyLabel
+	sess := Session{configSession: *ucs}
+	csMutex.Unlock()
+
+	// The hooks may use the cs API. (The scheduled session can not be
+	// modified, only cancelled, meanwhile)
+	if info, err = newCommitInfo(&sess, label); err == nil {
+		err = runPreCommitHooks(info)
+	}
+
+	csMutex.Lock()
+	if ucs = lookupCS(&sess.configSession); ucs == nil ||
+		ucs.commitState != cs_STATE_SCHEDULED {
+		csMutex.Unlock()
+		glog.Infof("applyScheduledCS[%s]: Cancelled", token)
+		return
+	}
+
+	if err == nil {
+		err = reserveCS(ucs)
+	}
+	if err == nil {
+		if ucs.commitState != cs_STATE_SCHEDULED {
+			// Cancelled, while waiting for the requests
+			unreserveCS(ucs)
+			csMutex.Unlock()
+			glog.Infof("applyScheduledCS[%s]: Cancelled", token)
+			return
+		}
+		if err = revalidateCS(ucs); err == nil {
+			ucs.origin = cs_ORIGIN_SCHEDULED
+			ucs.commitState = cs_STATE_None
+			err, errSc, errSh = commitUCS(ucs, label, false)
+		}
+		unreserveCS(ucs)
+	}
+	if err != nil {
+		unscheduleCS(ucs)
+		ucs.origin = ""
+		persistCS(ucs)
+	}
+	csMutex.Unlock()
+
+	var mesg string
+	if err != nil {
+		glog.Warningf("AUDIT: config session %q (token %s) of user %s: "+
+			"scheduled commit failed: %s", sess.name, token, sess.username, err)
+		mesg = fmt.Sprintf("The scheduled commit failed: %s", err)
+	} else {
+		glog.Warningf("AUDIT: config session %q (token %s) of user %s: "+
+			"scheduled commit applied, label %q (errSc %v errSh %v)",
+			sess.name, token, sess.username, label, errSc, errSh)
+		mesg = "The scheduled commit was applied"
+		runPostCommitHooks(info)
+	}
+	sess.SendMesg(mesg, csSystemUser)
+
+	glog.Infof("applyScheduledCS[%s]: End", token)
+}
+
+// revalidateCS replays the Transaction of the Config Session in a new ccDB,
+// validating (CVL) it against the current running config. The new ccDB keeps
+// the values of the keys as read by the session, so that the commit detects
+// their modifications since. Caller holds the csMutex, and has reserved the
+// session, so that no request uses the ccDB being replaced.
+func revalidateCS(ucs *configSession) error {
+	cmds, err := ucs.ccDB.TxCmds()
+	if err != nil {
+		return err
+	}
+
+	ccDB, err := newCCDB(ucs.username)
+	if err != nil {
+		return err
+	}
+	ccDB.Opts.DisableCVLCheck = ucs.ccDB.Opts.DisableCVLCheck
+
+	if err = ccDB.ReplayTxCmds(cmds); err == nil {
+		err = ccDB.CopyTxOrig(ucs.ccDB)
+	}
+	if err != nil {
+		glog.Errorf("revalidateCS[%s]: err %s", ucs.token, err)
+		csAbortTx(ccDB)
+		ccDB.DeleteDB()
+		return err
+	}
+
+	csAbortTx(ucs.ccDB)
+	ucs.ccDB.DeleteDB()
+	ucs.ccDB = ccDB
+	return nil
+}
@@ -23,6 +23,7 @@ package cs
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/golang/glog"
@@ -67,7 +68,13 @@ func GetSession(name string, token string, username string, roles []string,
 
 	csMutex.Lock()
 	defer csMutex.Unlock()
-	ucs := uCS
+
+	var ucs *configSession
+	if gsoName {
+		ucs = csSessions[name]
+	} else if len(token) != 0 {
+		ucs = findCSByToken(token)
+	}
 
 	session = Session{configSession: configSession{name: name, token: token,
 		username: username, roles: roles, pid: pid}}
@@ -80,15 +87,7 @@ func GetSession(name string, token string, username string, roles []string,
 
 	} else if ucs != nil {
 
-		if gsoName && ucs.name != name {
-			glog.Warningf("GetSession: name mismatch %s != %s", name, ucs.name)
-			err = CsStatusInvalidSession{Tag: ErrTagNameNotFound}
-
-		} else if (!gsoName) && (ucs.token != token) {
-			glog.Warningf("GetSession: token mismatch %s != %s", token, ucs.token)
-			err = CsStatusInvalidSession{Tag: ErrTagTokenNotFound}
-
-		} else if gsoStrict && (ucs.username != username) {
+		if gsoStrict && (ucs.username != username) {
 			glog.Warningf("GetSession: user mismatch %s != %s", username,
 				ucs.username)
 			err = CsStatusInvalidSession{Tag: ErrTagInvalidUser}
@@ -113,6 +112,8 @@ func GetSession(name string, token string, username string, roles []string,
 		glog.Warningf("GetSession[%s][%s]: Token. No Session", name, token)
 		err = CsStatusInvalidSession{Tag: ErrTagTokenNotFound}
 	}
+	// Else, no session by that name. An empty Session is returned, so that
+	// a new session can be started by that name.
 
 	return session, err
 }
@@ -123,14 +124,30 @@ func GetAllSessions() ([]Session, error) {
 	defer csMutex.Unlock()
 
 	var allSess []Session
-	if uCS != nil && uCS.state != cs_STATE_None {
-		sess := Session{configSession: *uCS}
-		allSess = append(allSess, sess)
+	for _, ucs := range csSessions {
+		if ucs.state != cs_STATE_None {
+			allSess = append(allSess, Session{configSession: *ucs})
+		}
 	}
 
+	sort.Slice(allSess, func(i, j int) bool {
+		return allSess[i].startTime.Before(allSess[j].startTime)
+	})
+
 	return allSess, nil
 }
 
+// findCSByToken returns the Config Session with the token. Caller holds the
+// csMutex.
+func findCSByToken(token string) *configSession {
+	for _, ucs := range csSessions {
+		if ucs.token == token {
+			return ucs
+		}
+	}
+	return nil
+}
+
 func (sess *Session) IsConfigSession() bool {
 	// For now, any state other than cs_STATE_None means this is an actual
 	// Config Session instead of just a container for a cs_irpc.go(CsXYZReq)
@@ -139,6 +156,12 @@ func (sess *Session) IsConfigSession() bool {
 	return sess.configSession.state != cs_STATE_None
 }
 
+// IsEditable returns true if the candidate configuration of this session can
+// be modified, i.e. it is a Config Session, which is not being committed.
+func (sess *Session) IsEditable() bool {
+	return sess.IsConfigSession() && sess.commitState == cs_STATE_None
+}
+
 func (sess *Session) IsPidActive() bool {
 	if sess == nil {
 		return false
@@ -172,6 +195,8 @@ func (sess *Session) GetState() string {
 		state = "PENDING CONFIRM"
 	} else if sess.commitState == cs_STATE_ROLLBACK_REPLACE {
 		state = "ROLLBACK IN PROGRESS"
+	} else if sess.commitState == cs_STATE_SCHEDULED {
+		state = "COMMIT SCHEDULED"
 	} else if sess.IsActive() {
 		state = "ACTIVE"
 	} else {
@@ -22,9 +22,11 @@
 package cs
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/Azure/sonic-mgmt-common/translib/db"
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
 )
 
 func TestCSGetSession(t *testing.T) {
@@ -411,3 +413,145 @@ func TestCSTxNoSession(t *testing.T) {
 		t.Errorf("CommitTx() fails e: %v", e)
 	}
 }
+
+func TestCSDiff(t *testing.T) {
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+
+	t.Cleanup(func() { deleteCS(sName) })
+
+	sess, e := GetSession(sName, "", user, uR, pid, GSOstrict{}, GSOname{})
+	if e != nil {
+		t.Fatalf("GetSession() GSOstrict|name{} fails e: %v", e)
+	}
+
+	if diff, e := sess.Diff(); e != nil || len(diff) != 0 {
+		t.Fatalf("Diff() = %v, e: %v, expected no changes", diff, e)
+	}
+
+	ts := db.TableSpec{Name: "CS_DIFF_TST_" + strconv.Itoa(int(pid))}
+	key := db.Key{Comp: []string{"k1"}}
+	sess.ccDB.Opts.DisableCVLCheck = true
+	e = sess.ccDB.SetEntry(&ts, key, db.Value{Field: map[string]string{
+		"f1": "v1", "f2": "v2"}})
+	if e != nil {
+		t.Fatalf("ccDB.SetEntry() fails e: %v", e)
+	}
+
+	diff, e := sess.Diff()
+	if e != nil || len(diff) != 2 {
+		t.Fatalf("Diff() = %v, e: %v, expected 2 changes", diff, e)
+	}
+	for i, f := range []string{"f1", "f2"} {
+		if diff[i].Table != ts.Name || diff[i].Key != "k1" ||
+			diff[i].Field != f || diff[i].Op != DiffOpAdd {
+			t.Errorf("Diff()[%d] = %v, expected add %s", i, diff[i], f)
+		}
+	}
+}
+
+func TestCSCommitHooks(t *testing.T) {
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteCS(sName) })
+
+	ts := db.TableSpec{Name: "CS_HOOK_TST_" + strconv.Itoa(int(pid))}
+	key := db.Key{Comp: []string{"k1"}}
+	t.Cleanup(func() {
+		if d, e := db.NewDB(db.Options{DBNo: db.ConfigDB}); e == nil {
+			d.DeleteTable(&ts)
+			d.DeleteDB()
+		}
+	})
+
+	u.ccDB.Opts.DisableCVLCheck = true
+	if e = u.ccDB.SetEntry(&ts, key, db.Value{Field: map[string]string{
+		"f1": "v1"}}); e != nil {
+		t.Fatalf("ccDB.SetEntry() fails e: %v", e)
+	}
+
+	var committed *CommitInfo
+	if e = RegisterPreCommitHook("tst-ticket", func(info *CommitInfo) error {
+		if len(info.Label) == 0 {
+			return tlerr.InvalidArgs("A change ticket label is required")
+		}
+		return nil
+	}); e != nil {
+		t.Fatalf("RegisterPreCommitHook() fails e: %v", e)
+	}
+	t.Cleanup(func() { UnregisterCommitHook("tst-ticket") })
+
+	if e = RegisterPostCommitHook("tst-post", func(info *CommitInfo) {
+		committed = info
+	}); e != nil {
+		t.Fatalf("RegisterPostCommitHook() fails e: %v", e)
+	}
+	t.Cleanup(func() { UnregisterCommitHook("tst-post") })
+
+	if e = RegisterPostCommitHook("tst-post", func(*CommitInfo) {}); e == nil {
+		t.Errorf("RegisterPostCommitHook() of a duplicate succeeds")
+	}
+
+	sess, e := GetSession(sName, "", user, uR, pid, GSOstrict{}, GSOname{})
+	if e != nil {
+		t.Fatalf("GetSession() GSOstrict|name{} fails e: %v", e)
+	}
+
+	success, status := sess.Commit("", 0, false)
+	failure, ok := status.(CsStatusCommitFailure)
+	if success || !ok {
+		t.Fatalf("Commit() without label = %v, %v, expected vetoed", success,
+			status)
+	}
+	if veto, ok := failure.Err.(CsCommitVetoed); !ok || veto.Hook != "tst-ticket" {
+		t.Fatalf("Commit() without label err %v, expected vetoed", failure.Err)
+	}
+	if committed != nil {
+		t.Fatalf("Post-commit hook called on veto: %v", committed)
+	}
+
+	UnregisterCommitHook("tst-ticket")
+
+	// A panic in a pre-commit hook vetoes, and in a post-commit hook is
+	// only logged.
+	if e = RegisterPreCommitHook("tst-panic", func(*CommitInfo) error {
+		panic("tst-panic")
+	}); e != nil {
+		t.Fatalf("RegisterPreCommitHook() fails e: %v", e)
+	}
+	success, status = sess.Commit("", 0, false)
+	failure, ok = status.(CsStatusCommitFailure)
+	if success || !ok {
+		t.Fatalf("Commit() with panic = %v, %v, expected vetoed", success,
+			status)
+	}
+	if veto, ok := failure.Err.(CsCommitVetoed); !ok || veto.Hook != "tst-panic" {
+		t.Fatalf("Commit() with panic err %v, expected vetoed", failure.Err)
+	}
+	UnregisterCommitHook("tst-panic")
+
+	UnregisterCommitHook("tst-post")
+	if e = RegisterPostCommitHook("tst-post-panic", func(*CommitInfo) {
+		panic("tst-post-panic")
+	}); e != nil {
+		t.Fatalf("RegisterPostCommitHook() fails e: %v", e)
+	}
+	t.Cleanup(func() { UnregisterCommitHook("tst-post-panic") })
+	if e = RegisterPostCommitHook("tst-post", func(info *CommitInfo) {
+		committed = info
+	}); e != nil {
+		t.Fatalf("RegisterPostCommitHook() fails e: %v", e)
+	}
+
+	if success, status = sess.Commit("", 0, false); !success {
+		t.Fatalf("Commit() fails status: %v", status)
+	}
+	if committed == nil || committed.Token != sess.token ||
+		len(committed.Changes) != 1 || committed.Changes[0].Field != "f1" {
+		t.Errorf("Post-commit hook called with %v, expected f1 added", committed)
+	}
+}
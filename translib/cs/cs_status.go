@@ -23,6 +23,7 @@ package cs
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
 )
@@ -121,6 +122,39 @@ func (s CsStatusCommitFailure) Error() string {
 	return s.Status()
 }
 
+// CsCommitConflict indicates that the keys written by the session were
+// modified in the running config, or by the commit of another session, since
+// the session started. (Wrapped in CsStatusCommitFailure)
+type CsCommitConflict struct {
+	Keys []string // Eg: "PORT|Ethernet0"
+}
+
+func (e CsCommitConflict) Error() string {
+	return fmt.Sprintf("Conflicting changes to %s", strings.Join(e.Keys, ", "))
+}
+
+// CsRollbackConflict indicates that the keys changed by the commit (pending
+// confirmation) were modified since, so the commit was not rolled back.
+type CsRollbackConflict struct {
+	Keys []string // Eg: "PORT|Ethernet0"
+}
+
+func (e CsRollbackConflict) Error() string {
+	return fmt.Sprintf("Changed since the commit, not rolled back: %s",
+		strings.Join(e.Keys, ", "))
+}
+
+// CsCommitVetoed indicates that a pre-commit hook vetoed the commit.
+// (Wrapped in CsStatusCommitFailure)
+type CsCommitVetoed struct {
+	Hook   string
+	Reason string
+}
+
+func (e CsCommitVetoed) Error() string {
+	return fmt.Sprintf("Commit vetoed by %s: %s", e.Hook, e.Reason)
+}
+
 // CsStatusCommitWarning indicates completion of CS Commit operation with warnings.
 type CsStatusCommitWarning struct {
 	UnlockFailure     error // ConfigDB unlock failed
@@ -23,7 +23,11 @@ package cs
 
 import (
 	"os"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/db"
 )
 
 var sName = ""
@@ -70,6 +74,51 @@ func TestCSDelete(t *testing.T) {
 	}
 }
 
+func TestCSDeleteInUse(t *testing.T) {
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+
+	t.Cleanup(func() { deleteCS(sName) })
+
+	d, release, e := u.acquireCCDB()
+	if d == nil || e != nil {
+		t.Fatalf("acquireCCDB() fails e: %v", e)
+	}
+
+	deleted := make(chan error, 1)
+	go func() {
+		e, _ := deleteCS(sName)
+		deleted <- e
+	}()
+
+	// The delete waits for the request using the ccDB, which is not closed.
+	select {
+	case e = <-deleted:
+		t.Fatalf("deleteCS() e: %v, while the ccDB is in use", e)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if !d.IsOpen() {
+		t.Fatalf("ccDB closed, while in use")
+	}
+
+	// Meanwhile, the session is reserved.
+	if _, _, e = u.acquireCCDB(); e == nil {
+		t.Errorf("acquireCCDB() succeeds, while the session is reserved")
+	}
+
+	release()
+	select {
+	case e = <-deleted:
+		if e != nil {
+			t.Fatalf("deleteCS() fails e: %v", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("deleteCS() not done, after the ccDB is released")
+	}
+}
+
 func TestCSPidActive(t *testing.T) {
 	u, e := newCS(sName, user, uR, pid)
 	if (u == nil) || (e != nil) {
@@ -89,3 +138,605 @@ func TestCSPidActive(t *testing.T) {
 		t.Fatalf("deleteCS() fails e: %v", e)
 	}
 }
+
+func TestCSConcurrentConflict(t *testing.T) {
+	ts := db.TableSpec{Name: "CS_TST_" + strconv.Itoa(int(pid))}
+	key := db.Key{Comp: []string{"k1"}}
+	value := db.Value{Field: map[string]string{"f1": "v1"}}
+
+	var sessions []*configSession
+	for _, name := range []string{"cs_tst_1", "cs_tst_2"} {
+		name := name
+		u, e := newCS(name, user, uR, pid)
+		if (u == nil) || (e != nil) {
+			t.Fatalf("newCS(%s) fails e: %v", name, e)
+		}
+		t.Cleanup(func() { deleteCS(name) })
+
+		u.ccDB.Opts.DisableCVLCheck = true
+		if e = u.ccDB.SetEntry(&ts, key, value); e != nil {
+			t.Fatalf("ccDB[%s].SetEntry() fails e: %v", name, e)
+		}
+		sessions = append(sessions, u)
+	}
+
+	t.Cleanup(func() {
+		if d, e := db.NewDB(db.Options{DBNo: db.ConfigDB}); e == nil {
+			d.DeleteTable(&ts)
+			d.DeleteDB()
+		}
+	})
+
+	if e, _, _ := commitCS(sessions[0].name, "", false); e != nil {
+		t.Fatalf("commitCS(%s) fails e: %v", sessions[0].name, e)
+	}
+
+	e, _, _ := commitCS(sessions[1].name, "", false)
+	if conflict, ok := e.(CsCommitConflict); !ok || len(conflict.Keys) != 1 {
+		t.Fatalf("commitCS(%s) e: %v, expected CsCommitConflict",
+			sessions[1].name, e)
+	}
+
+	if all, _ := GetAllSessions(); len(all) != 1 || all[0].name != sessions[1].name {
+		t.Errorf("GetAllSessions() = %v, expected %s", all, sessions[1].name)
+	}
+}
+
+func TestCSCommitLockWait(t *testing.T) {
+	ts := db.TableSpec{Name: "CS_LW_TST_" + strconv.Itoa(int(pid))}
+	key := db.Key{Comp: []string{"k1"}}
+
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteCS(sName) })
+
+	u.ccDB.Opts.DisableCVLCheck = true
+	if e = u.ccDB.SetEntry(&ts, key, db.Value{Field: map[string]string{
+		"f1": "v1"}}); e != nil {
+		t.Fatalf("ccDB.SetEntry(%v) fails e: %v", key, e)
+	}
+
+	// Another holder of the table lock, makes the commit wait for it.
+	other := db.NewTableLocks("cs_lw_tst", 0)
+	if e = other.TryLock(db.TableLockSpec{Ts: &ts, Mode: db.WriteLock}); e != nil {
+		t.Fatalf("Lock(%s) fails e: %v", ts.Name, e)
+	}
+	t.Cleanup(func() { other.Unlock() })
+
+	committed := make(chan error, 1)
+	go func() {
+		e, _, _ := commitCS(sName, "", false)
+		committed <- e
+	}()
+	time.Sleep(csLockWait / 4)
+
+	// The csMutex is not held, while waiting for the table lock. The session
+	// is reserved meanwhile.
+	if !csMutex.TryLock() {
+		t.Fatalf("csMutex held, while waiting for the table locks")
+	}
+	reserved := u.reserved
+	csMutex.Unlock()
+	if !reserved {
+		t.Errorf("Session not reserved, while committing")
+	}
+	if _, _, e = u.acquireCCDB(); e == nil {
+		t.Errorf("acquireCCDB() succeeds, while committing")
+	}
+
+	select {
+	case e = <-committed:
+		if e == nil {
+			t.Fatalf("commitCS() succeeds, while the table is locked")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("commitCS() not done, after the lock wait")
+	}
+
+	// The session is kept, and no longer reserved.
+	_, release, e := u.acquireCCDB()
+	if e != nil {
+		t.Fatalf("acquireCCDB() fails e: %v, after the commit failed", e)
+	}
+	release()
+}
+
+func TestCSCommitRollback(t *testing.T) {
+	ts := db.TableSpec{Name: "CS_RB_TST_" + strconv.Itoa(int(pid))}
+	k1 := db.Key{Comp: []string{"k1"}}
+	k2 := db.Key{Comp: []string{"k2"}}
+	orig := db.Value{Field: map[string]string{"f1": "v1"}}
+
+	d, e := db.NewDB(db.Options{DBNo: db.ConfigDB, DisableCVLCheck: true})
+	if e != nil {
+		t.Fatalf("db.NewDB() fails e: %v", e)
+	}
+	e = d.SetEntry(&ts, k1, orig)
+	d.DeleteDB()
+	if e != nil {
+		t.Fatalf("SetEntry(%v) fails e: %v", k1, e)
+	}
+
+	t.Cleanup(func() {
+		if d, e := db.NewDB(db.Options{DBNo: db.ConfigDB}); e == nil {
+			d.DeleteTable(&ts)
+			d.DeleteDB()
+		}
+	})
+
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteCS(sName) })
+
+	// Modify k1, and Create k2
+	u.ccDB.Opts.DisableCVLCheck = true
+	if e = u.ccDB.ModEntry(&ts, k1, db.Value{Field: map[string]string{"f1": "v2"}}); e != nil {
+		t.Fatalf("ccDB.ModEntry(%v) fails e: %v", k1, e)
+	}
+	if e = u.ccDB.SetEntry(&ts, k2, orig); e != nil {
+		t.Fatalf("ccDB.SetEntry(%v) fails e: %v", k2, e)
+	}
+
+	if e, _, _ = commitCS(sName, "", true); e != nil {
+		t.Fatalf("commitCS() fails e: %v", e)
+	}
+
+	if e = rollbackCS(u); e != nil {
+		t.Fatalf("rollbackCS() fails e: %v", e)
+	}
+
+	d, e = db.NewDB(db.Options{DBNo: db.ConfigDB, IsWriteDisabled: true})
+	if e != nil {
+		t.Fatalf("db.NewDB() fails e: %v", e)
+	}
+	defer d.DeleteDB()
+
+	if v, e := d.GetEntry(&ts, k1); e != nil || v.Get("f1") != "v1" {
+		t.Errorf("GetEntry(%v) = %v, e: %v, expected f1: v1", k1, v, e)
+	}
+	if _, e := d.GetEntry(&ts, k2); e == nil {
+		t.Errorf("GetEntry(%v) succeeds, expected deleted", k2)
+	}
+}
+
+func TestCSCommitRollbackConflict(t *testing.T) {
+	ts := db.TableSpec{Name: "CS_RBC_TST_" + strconv.Itoa(int(pid))}
+	k1 := db.Key{Comp: []string{"k1"}}
+	k2 := db.Key{Comp: []string{"k2"}}
+	value := db.Value{Field: map[string]string{"f1": "v1"}}
+
+	t.Cleanup(func() {
+		if d, e := db.NewDB(db.Options{DBNo: db.ConfigDB}); e == nil {
+			d.DeleteTable(&ts)
+			d.DeleteDB()
+		}
+	})
+
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteCS(sName) })
+
+	u.ccDB.Opts.DisableCVLCheck = true
+	for _, k := range []db.Key{k1, k2} {
+		if e = u.ccDB.SetEntry(&ts, k, value); e != nil {
+			t.Fatalf("ccDB.SetEntry(%v) fails e: %v", k, e)
+		}
+	}
+
+	if e, _, _ = commitCS(sName, "", true); e != nil {
+		t.Fatalf("commitCS() fails e: %v", e)
+	}
+
+	// The table written is locked till confirmed.
+	holders, e := db.GetTableLockHolders()
+	locked := false
+	for _, h := range holders {
+		locked = locked || (h.Scope == ts.Name && h.Id == u.token &&
+			h.Mode == db.WriteLock)
+	}
+	if e != nil || !locked {
+		t.Fatalf("GetTableLockHolders() = %v, e: %v, expected %s locked",
+			holders, e, ts.Name)
+	}
+
+	// k1 is modified after the commit (once the locks are released).
+	csMutex.Lock()
+	unlockCS(u)
+	csMutex.Unlock()
+
+	d, e := db.NewDB(db.Options{DBNo: db.ConfigDB, DisableCVLCheck: true})
+	if e != nil {
+		t.Fatalf("db.NewDB() fails e: %v", e)
+	}
+	defer d.DeleteDB()
+	if e = d.ModEntry(&ts, k1, db.Value{Field: map[string]string{"f1": "v3"}}); e != nil {
+		t.Fatalf("ModEntry(%v) fails e: %v", k1, e)
+	}
+
+	e = rollbackCS(u)
+	if conflict, ok := e.(CsRollbackConflict); !ok || len(conflict.Keys) != 1 {
+		t.Fatalf("rollbackCS() e: %v, expected CsRollbackConflict", e)
+	}
+
+	// Nothing is rolled back.
+	if v, e := d.GetEntry(&ts, k1); e != nil || v.Get("f1") != "v3" {
+		t.Errorf("GetEntry(%v) = %v, e: %v, expected f1: v3", k1, v, e)
+	}
+	if _, e := d.GetEntry(&ts, k2); e != nil {
+		t.Errorf("GetEntry(%v) fails e: %v, expected not rolled back", k2, e)
+	}
+}
+
+func TestCSFindCpHistEntry(t *testing.T) {
+	saved, savedLoaded := cpHistEnts, is_hist_loaded
+	t.Cleanup(func() { cpHistEnts, is_hist_loaded = saved, savedLoaded })
+
+	is_hist_loaded = true
+	cpHistEnts = CpHistEntries{CpHistEntries: []CpHistEntry{
+		{Label: "", Id: "100-1", User: user, Origin: cs_ORIGIN_COMMIT},
+		{Label: "golden", Id: "200-2", User: user, Origin: cs_ORIGIN_COMMIT},
+	}}
+
+	for labelOrID, fileName := range map[string]string{
+		"100-1": "100-1", "golden": "golden", "200-2": "golden"} {
+		ent, ok := findCpHistEntry(labelOrID)
+		if !ok || ent.fileName() != fileName {
+			t.Errorf("findCpHistEntry(%s) = %v, %v, expected %s", labelOrID,
+				ent, ok, fileName)
+		}
+	}
+
+	if ent, ok := findCpHistEntry("missing"); ok {
+		t.Errorf("findCpHistEntry(missing) = %v, expected not found", ent)
+	}
+
+	sess, e := GetSession("cs_tst_rb", "", user, uR, pid, GSOstrict{}, GSOname{})
+	if e != nil {
+		t.Fatalf("GetSession() fails e: %v", e)
+	}
+	if success, status := sess.RollbackToCheckpoint("missing", "", 0); success {
+		t.Errorf("RollbackToCheckpoint(missing) succeeds status: %v", status)
+	}
+}
+
+// testCpStore saves an empty config, instead of the running config.
+type testCpStore struct {
+	*dirCpStore
+}
+
+func (s testCpStore) SaveConfig(cpName string, user string) error {
+	return s.writeFile(s.dir+cpName+db.CheckpointGzExt, []byte("{}"), true)
+}
+
+func TestCSCpRetention(t *testing.T) {
+	savedStore, savedRetention := cpStore, GetCpRetention()
+	t.Cleanup(func() {
+		SetCpStore(savedStore)
+		SetCpRetention(savedRetention)
+	})
+
+	SetCpStore(testCpStore{NewDirCpStore(t.TempDir(), true).(*dirCpStore)})
+	SetCpRetention(CpRetention{MaxCount: 2})
+
+	now := time.Now().UnixNano()
+	if e := CreateCpHistEntry("golden", "1-1", user, cs_ORIGIN_COMMIT, now); e != nil {
+		t.Fatalf("CreateCpHistEntry(golden) fails e: %v", e)
+	}
+	if e := PinCheckpoint("1-1", true, user); e != nil {
+		t.Fatalf("PinCheckpoint(1-1) fails e: %v", e)
+	}
+
+	for _, id := range []string{"2-2", "3-3", "4-4"} {
+		if e := CreateCpHistEntry("", id, user, cs_ORIGIN_COMMIT, now); e != nil {
+			t.Fatalf("CreateCpHistEntry(%s) fails e: %v", id, e)
+		}
+	}
+	if e := PinCheckpoint("4-4", true, user); e == nil {
+		t.Errorf("PinCheckpoint(4-4) of unlabelled checkpoint succeeds")
+	}
+
+	cpInfos, e := ListCheckpoints()
+	if e != nil || len(cpInfos) != 2 {
+		t.Fatalf("ListCheckpoints() = %v, e: %v, expected 2", cpInfos, e)
+	}
+	for i, id := range []string{"1-1", "4-4"} {
+		cpInfo := cpInfos[i]
+		if cpInfo.Id != id || !cpInfo.Compressed || cpInfo.Size == 0 {
+			t.Errorf("ListCheckpoints()[%d] = %+v, expected %s", i, cpInfo, id)
+		}
+	}
+	if !cpInfos[0].Pinned {
+		t.Errorf("ListCheckpoints()[0] = %+v, expected pinned", cpInfos[0])
+	}
+
+	if _, e := os.Stat(cpStore.Dir() + "3-3" + db.CheckpointGzExt); !os.IsNotExist(e) {
+		t.Errorf("Checkpoint 3-3 not removed, e: %v", e)
+	}
+}
+
+func TestCSIdleTimeout(t *testing.T) {
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+
+	t.Cleanup(func() { deleteCS(sName) })
+
+	cfg := CsIdleConfig{Timeout: time.Hour, Warning: 10 * time.Minute}
+	now := time.Now()
+
+	// Within the warning window: warned, and kept.
+	csMutex.Lock()
+	u.lastActiveTime = now.Add(-55 * time.Minute)
+	csMutex.Unlock()
+
+	reapIdleSessions(cfg, now)
+
+	csMutex.Lock()
+	warned, kept := u.idleWarned, csSessions[sName] == u
+	csMutex.Unlock()
+	if !warned || !kept {
+		t.Fatalf("reapIdleSessions() warned %v kept %v, expected both", warned, kept)
+	}
+
+	// Past the Timeout: aborted.
+	csMutex.Lock()
+	u.lastActiveTime = now.Add(-time.Hour)
+	csMutex.Unlock()
+
+	reapIdleSessions(cfg, now)
+
+	csMutex.Lock()
+	_, kept = csSessions[sName]
+	csMutex.Unlock()
+	if kept {
+		t.Fatalf("reapIdleSessions() did not abort the idle session")
+	}
+}
+
+func TestCSRestore(t *testing.T) {
+	ts := db.TableSpec{Name: "CS_RS_TST_" + strconv.Itoa(int(pid))}
+	k1 := db.Key{Comp: []string{"k1"}}
+	k2 := db.Key{Comp: []string{"k2"}}
+	value := db.Value{Field: map[string]string{"f1": "v1"}}
+
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteCS(sName) })
+
+	u.ccDB.Opts.DisableCVLCheck = true
+	if e = u.ccDB.SetEntry(&ts, k1, value); e != nil {
+		t.Fatalf("ccDB.SetEntry(%v) fails e: %v", k1, e)
+	}
+	u.persist()
+
+	// Persisted incrementally
+	if e = u.ccDB.SetEntry(&ts, k2, value); e != nil {
+		t.Fatalf("ccDB.SetEntry(%v) fails e: %v", k2, e)
+	}
+	u.persist()
+	if u.persistedCmds != 2 {
+		t.Fatalf("persist() persistedCmds %d, expected 2", u.persistedCmds)
+	}
+
+	// Restart: the session is lost from the memory, but not the STATE_DB.
+	csMutex.Lock()
+	delete(csSessions, sName)
+	u.ccDB.DeleteDB()
+	csMutex.Unlock()
+
+	if e = RestoreSessions(); e != nil {
+		t.Fatalf("RestoreSessions() fails e: %v", e)
+	}
+
+	csMutex.Lock()
+	r := csSessions[sName]
+	csMutex.Unlock()
+	if r == nil || r.token != u.token || r.state != cs_STATE_SUSPENDED ||
+		r.username != user || !r.startTime.Equal(u.startTime) {
+		t.Fatalf("RestoreSessions() = %v, expected suspended %v", r, u.token)
+	}
+
+	for _, k := range []db.Key{k1, k2} {
+		if v, e := r.ccDB.GetEntry(&ts, k); e != nil || v.Get("f1") != "v1" {
+			t.Errorf("Restored ccDB.GetEntry(%v) = %v, e: %v, expected f1: v1",
+				k, v, e)
+		}
+	}
+}
+
+func TestCSScheduledCommit(t *testing.T) {
+	savedStore := cpStore
+	t.Cleanup(func() { SetCpStore(savedStore) })
+	SetCpStore(testCpStore{NewDirCpStore(t.TempDir(), false).(*dirCpStore)})
+
+	ts := db.TableSpec{Name: "CS_SCHED_TST_" + strconv.Itoa(int(pid))}
+	k1 := db.Key{Comp: []string{"k1"}}
+	t.Cleanup(func() {
+		if d, e := db.NewDB(db.Options{DBNo: db.ConfigDB}); e == nil {
+			d.DeleteTable(&ts)
+			d.DeleteDB()
+		}
+	})
+
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteCS(sName) })
+
+	u.ccDB.Opts.DisableCVLCheck = true
+	if e = u.ccDB.SetEntry(&ts, k1, db.Value{Field: map[string]string{
+		"f1": "v1"}}); e != nil {
+		t.Fatalf("ccDB.SetEntry(%v) fails e: %v", k1, e)
+	}
+
+	sess := Session{configSession: *u}
+	applyAt := time.Now().Add(time.Hour)
+	if ok, status := sess.Commit("sched", 0, false, COapplyAt{Time: applyAt}); !ok {
+		t.Fatalf("Commit(COapplyAt) fails status: %v", status)
+	}
+
+	allSess, _ := GetAllSessions()
+	if len(allSess) != 1 || !allSess[0].ScheduledTime().Equal(applyAt) ||
+		allSess[0].IsEditable() {
+		t.Fatalf("GetAllSessions() = %v, expected scheduled at %v", allSess,
+			applyAt)
+	}
+
+	sess = allSess[0]
+	if ok, status := sess.CancelScheduledCommit(); !ok {
+		t.Fatalf("CancelScheduledCommit() fails status: %v", status)
+	}
+
+	allSess, _ = GetAllSessions()
+	if len(allSess) != 1 || !allSess[0].IsEditable() {
+		t.Fatalf("GetAllSessions() = %v, expected editable", allSess)
+	}
+	sess = allSess[0]
+	if ok, status := sess.Commit("sched", 0, false, COapplyAt{Time: applyAt}); !ok {
+		t.Fatalf("Commit(COapplyAt) fails status: %v", status)
+	}
+
+	// A request reads the candidate config, while the time has come.
+	ccDB, release, e := u.acquireCCDB()
+	if e != nil {
+		t.Fatalf("acquireCCDB() fails e: %v", e)
+	}
+	applied := make(chan struct{})
+	go func() {
+		applyScheduledCS(u.token)
+		close(applied)
+	}()
+
+	// The ccDB is not replaced, or closed, till the request releases it.
+	select {
+	case <-applied:
+		t.Fatalf("Scheduled commit applied, while the ccDB is in use")
+	case <-time.After(200 * time.Millisecond):
+	}
+	if _, e = ccDB.GetEntry(&ts, k1); e != nil {
+		t.Fatalf("ccDB.GetEntry(%v) fails e: %v, while in use", k1, e)
+	}
+	release()
+	select {
+	case <-applied:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Scheduled commit not done, after the ccDB is released")
+	}
+
+	csMutex.Lock()
+	_, exists := csSessions[sName]
+	csMutex.Unlock()
+	if exists {
+		t.Fatalf("Scheduled commit not applied")
+	}
+
+	d, e := db.NewDB(db.Options{DBNo: db.ConfigDB, IsWriteDisabled: true})
+	if e != nil {
+		t.Fatalf("db.NewDB() fails e: %v", e)
+	}
+	defer d.DeleteDB()
+	if v, e := d.GetEntry(&ts, k1); e != nil || v.Get("f1") != "v1" {
+		t.Errorf("GetEntry(%v) = %v, e: %v, expected f1: v1", k1, v, e)
+	}
+
+	if ent, ok := findCpHistEntry("sched"); !ok || ent.Origin != cs_ORIGIN_SCHEDULED {
+		t.Errorf("findCpHistEntry(sched) = %v, %v, expected scheduled", ent, ok)
+	}
+}
+
+func TestCSScheduledCommitCVL(t *testing.T) {
+	savedStore := cpStore
+	t.Cleanup(func() { SetCpStore(savedStore) })
+	SetCpStore(testCpStore{NewDirCpStore(t.TempDir(), false).(*dirCpStore)})
+
+	ts := db.TableSpec{Name: "ACL_TABLE"}
+	k1 := db.Key{Comp: []string{"CS_SCHED_" + strconv.Itoa(int(pid))}}
+	t.Cleanup(func() {
+		if d, e := db.NewDB(db.Options{DBNo: db.ConfigDB}); e == nil {
+			d.DeleteEntry(&ts, k1)
+			d.DeleteDB()
+		}
+	})
+
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteCS(sName) })
+
+	// The create is re-validated (CVL) as a create, when applied.
+	if e = u.ccDB.CreateEntry(&ts, k1, db.Value{Field: map[string]string{
+		"type": "L3", "stage": "INGRESS"}}); e != nil {
+		t.Fatalf("ccDB.CreateEntry(%v) fails e: %v", k1, e)
+	}
+
+	sess := Session{configSession: *u}
+	applyAt := time.Now().Add(time.Hour)
+	if ok, status := sess.Commit("sched", 0, false, COapplyAt{Time: applyAt}); !ok {
+		t.Fatalf("Commit(COapplyAt) fails status: %v", status)
+	}
+
+	applyScheduledCS(u.token)
+
+	csMutex.Lock()
+	_, exists := csSessions[sName]
+	csMutex.Unlock()
+	if exists {
+		t.Fatalf("Scheduled commit not applied")
+	}
+
+	d, e := db.NewDB(db.Options{DBNo: db.ConfigDB, IsWriteDisabled: true})
+	if e != nil {
+		t.Fatalf("db.NewDB() fails e: %v", e)
+	}
+	defer d.DeleteDB()
+	if v, e := d.GetEntry(&ts, k1); e != nil || v.Get("stage") != "INGRESS" {
+		t.Errorf("GetEntry(%v) = %v, e: %v, expected stage: INGRESS", k1, v, e)
+	}
+}
+
+func TestCSRestoreRetry(t *testing.T) {
+	u, e := newCS(sName, user, uR, pid)
+	if (u == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteCS(sName) })
+
+	// Restart: the session is lost from the memory, but not the STATE_DB.
+	csMutex.Lock()
+	delete(csSessions, sName)
+	u.ccDB.DeleteDB()
+	csMutex.Unlock()
+
+	// Another session by the name blocks the restore.
+	if u2, e := newCS(sName, user, uR, pid); (u2 == nil) || (e != nil) {
+		t.Fatalf("newCS() fails e: %v", e)
+	}
+	if e = RestoreSessions(); e == nil {
+		t.Fatalf("RestoreSessions() succeeds, expected failure of %s", u.token)
+	}
+
+	if e, _ = deleteCS(sName); e != nil {
+		t.Fatalf("deleteCS() fails e: %v", e)
+	}
+	if e = RestoreSessions(); e != nil {
+		t.Fatalf("RestoreSessions() retry fails e: %v", e)
+	}
+
+	csMutex.Lock()
+	r := csSessions[sName]
+	csMutex.Unlock()
+	if r == nil || r.token != u.token {
+		t.Fatalf("RestoreSessions() retry = %v, expected %v", r, u.token)
+	}
+}
@@ -24,6 +24,7 @@ package cs
 
 import (
 	"github.com/Azure/sonic-mgmt-common/translib/db"
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
 	"github.com/golang/glog"
 )
 
@@ -43,7 +44,7 @@ func (sess *Session) StartTx(d *db.DB, w []db.WatchKeys,
 func (sess *Session) CommitTx(d *db.DB) error {
 	glog.Infof("cs.CommitTx:[%s]: Begin", sess.token)
 	var e error
-	if (uCS != nil) && (d == uCS.ccDB) {
+	if isCSccDB(d) {
 		e = d.ReleaseSP()
 	} else {
 		e = d.CommitTx()
@@ -54,7 +55,7 @@ func (sess *Session) CommitTx(d *db.DB) error {
 func (sess *Session) AbortTx(d *db.DB) error {
 	glog.Infof("cs.AbortTx:[%s]: Begin", sess.token)
 	var e error
-	if (uCS != nil) && (d == uCS.ccDB) {
+	if isCSccDB(d) {
 		e = d.Rollback2SP()
 	} else {
 		e = d.AbortTx()
@@ -62,9 +63,24 @@ func (sess *Session) AbortTx(d *db.DB) error {
 	return e
 }
 
+// isCSccDB returns true if d is the Candidate Config DB of a Config Session.
+func isCSccDB(d *db.DB) bool {
+	csMutex.Lock()
+	defer csMutex.Unlock()
+
+	for _, ucs := range csSessions {
+		if d == ucs.ccDB {
+			return true
+		}
+	}
+	return false
+}
+
+// csStartTx does not WATCH the CONFIG_DB (which is modified by the commits
+// of the other sessions). The conflicts are checked at commit.
 func csStartTx(d *db.DB) error {
 	glog.Infof("csStartTx: Begin")
-	return d.StartSessTx(nil, []*db.TableSpec{&(db.TableSpec{Name: "*"})})
+	return d.StartSessTx(nil, nil)
 }
 
 func csCommitTx(d *db.DB) error {
@@ -76,3 +92,75 @@ func csAbortTx(d *db.DB) error {
 	glog.Infof("csAbortTx: Begin")
 	return d.AbortSessTx()
 }
+
+// applyRollbackSet restores the entries to their values before the commit
+// (deleting the ones created by it), in the reverse order of the commit, in a
+// CONFIG_DB transaction. (validated like the commit of the ccDB) The entries
+// are WATCHed, and none are restored (CsRollbackConflict), if any of them is
+// modified since the commit.
+func applyRollbackSet(rollbackSet []db.TxDiffEntry, ccDB *db.DB) error {
+	glog.Infof("applyRollbackSet: Begin: %d entries", len(rollbackSet))
+
+	d, err := db.NewDB(db.Options{DBNo: db.ConfigDB,
+		ForceNewRedisConnection: true,
+		DisableCVLCheck:         ccDB.Opts.DisableCVLCheck,
+		User:                    ccDB.Opts.User,
+	})
+	if err != nil {
+		glog.Errorf("applyRollbackSet: db.NewDB err %s", err)
+		return err
+	}
+	defer d.DeleteDB()
+
+	w := make([]db.WatchKeys, len(rollbackSet))
+	for i := range rollbackSet {
+		w[i] = db.WatchKeys{Ts: rollbackSet[i].Ts, Key: &rollbackSet[i].Key}
+	}
+
+	if err = d.StartTx(w, nil); err != nil {
+		glog.Errorf("applyRollbackSet: StartTx err %s", err)
+		return err
+	}
+
+	// The entries are to be as committed.
+	var changed []string
+	for i := range rollbackSet {
+		re := &rollbackSet[i]
+		value, errG := d.GetEntry(re.Ts, re.Key)
+		if _, ok := errG.(tlerr.TranslibRedisClientEntryNotExist); ok {
+			value = db.Value{}
+		} else if errG != nil {
+			glog.Errorf("applyRollbackSet: %s: GetEntry err %s", re.Entry, errG)
+			d.AbortTx()
+			return errG
+		}
+		if !value.Equals(&re.After) {
+			glog.Warningf("applyRollbackSet: %s: Modified since the commit: "+
+				"%v -> %v", re.Entry, re.After, value)
+			changed = append(changed, re.Entry)
+		}
+	}
+	if len(changed) != 0 {
+		d.AbortTx()
+		return CsRollbackConflict{Keys: changed}
+	}
+
+	for i := len(rollbackSet) - 1; i >= 0; i-- {
+		re := &rollbackSet[i]
+		if len(re.Before.Field) == 0 {
+			err = d.DeleteEntry(re.Ts, re.Key)
+		} else {
+			err = d.SetEntry(re.Ts, re.Key, re.Before)
+		}
+		if err != nil {
+			glog.Errorf("applyRollbackSet: %s: err %s", re.Entry, err)
+			d.AbortTx()
+			return err
+		}
+	}
+
+	if err = d.CommitTx(); err != nil {
+		glog.Errorf("applyRollbackSet: CommitTx err %s", err)
+	}
+	return err
+}
@@ -113,8 +113,12 @@ import (
 	"github.com/Azure/sonic-mgmt-common/cvl"
 	cmn "github.com/Azure/sonic-mgmt-common/cvl/common"
 	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/Azure/sonic-mgmt-common/translib/tracing"
 	"github.com/golang/glog"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -157,7 +161,7 @@ func (dbNo DBNum) ID() int {
 	if len(name) == 0 {
 		panic("Invalid DBNum " + fmt.Sprintf("%d", dbNo))
 	}
-	return getDbId(name)
+	return getDbId(DefaultNamespace, name)
 }
 
 // Options gives parameters for opening the redis client.
@@ -187,6 +191,7 @@ type Options struct {
 	IsSession        bool // Is this a Candidate Config DB ?
 	ConfigDBLazyLock bool // For Non-CCDB Action()/RPC (may write to ConfigDB)
 	TxCmdsLim        int  // Tx Limit for Candidate Config DB
+	TxChunkSize      int  // Max Cmds per pipeline in CommitTx (0: No limit)
 
 	IsReplaced  bool // Is candidate Config DB updated by config-replace operation.
 	IsCommitted bool // Is candidate Config DB committed.
@@ -197,14 +202,27 @@ type Options struct {
 	Datastore DBDatastore
 
 	DisableCVLCheck bool
+
+	User string // Northbound user, recorded in the change log on commit
+
+	// Namespace of the DB on a multi ASIC platform (See GetNamespaces()).
+	// The DefaultNamespace ("") is the host (or single ASIC) DB.
+	Namespace string
+
+	Trace *RequestTrace // Collect the stats of this DB in the RequestTrace
+
+	// Ctxt is the northbound request context. The tracing spans of the DB
+	// operations are children of its span.
+	Ctxt context.Context
 }
 
 func (o Options) String() string {
 	return fmt.Sprintf(
-		"{ DBNo: %v, InitIndicator: %v, TableNameSeparator: %v, KeySeparator: %v, IsWriteDisabled: %v, IsCacheEnabled: %v, IsOnChangeEnabled: %v, ForceNewRedisConnection: %v, SDB: %v, DisableCVLCheck: %v, IsSession: %v, ConfigDBLazyLock: %v, TxCmdsLim: %v }",
+		"{ DBNo: %v, InitIndicator: %v, TableNameSeparator: %v, KeySeparator: %v, IsWriteDisabled: %v, IsCacheEnabled: %v, IsOnChangeEnabled: %v, ForceNewRedisConnection: %v, SDB: %v, DisableCVLCheck: %v, IsSession: %v, ConfigDBLazyLock: %v, TxCmdsLim: %v, TxChunkSize: %v, User: %v, Namespace: %v, Trace: %v }",
 		o.DBNo, o.InitIndicator, o.TableNameSeparator, o.KeySeparator,
 		o.IsWriteDisabled, o.IsCacheEnabled, o.IsOnChangeEnabled, o.ForceNewRedisConnection,
-		o.SDB, o.DisableCVLCheck, o.IsSession, o.ConfigDBLazyLock, o.TxCmdsLim)
+		o.SDB, o.DisableCVLCheck, o.IsSession, o.ConfigDBLazyLock, o.TxCmdsLim,
+		o.TxChunkSize, o.User, o.Namespace, o.Trace != nil)
 }
 
 type _txState int
@@ -269,10 +287,11 @@ const (
 type _txOp int
 
 const (
-	txOpNone  _txOp = iota // No Op
-	txOpHMSet              // key, value gives the field:value to be set in key
-	txOpHDel               // key, value gives the fields to be deleted in key
-	txOpDel                // key
+	txOpNone   _txOp = iota // No Op
+	txOpHMSet               // key, value gives the field:value to be set in key
+	txOpHDel                // key, value gives the fields to be deleted in key
+	txOpDel                 // key
+	txOpExpire              // key, ttl gives the time to live (0: persist)
 )
 
 type _txCmd struct {
@@ -280,6 +299,7 @@ type _txCmd struct {
 	op    _txOp
 	key   *Key
 	value *Value
+	ttl   time.Duration
 }
 
 // DB is the main type.
@@ -291,12 +311,21 @@ type DB struct {
 	txCmds       []_txCmd
 	txTsEntryMap map[string]map[string]Value //map[TableSpec.Name]map[Entry]Value
 
+	// For Config Session only, the least len(txCmds) since the last
+	// TxCmdsSince(). The txCmds from it, are to be persisted again.
+	txCmdsLow int
+
 	// For Config Session only, cache the HGetAll for restoring the
 	// txTsEntryMap on error recovery/rollback. This avoids the duplicate
 	// read for recovery/rollback. The Config DB is locked, therefore
 	// it need not be read again.
 	txTsEntryHGetAll map[string]map[string]Value //map[TableSpec.Name]map[Entry]Value
 
+	// For Config Session only, the stack of SavePoints (innermost last), and
+	// the CVL Hints stored since the outermost was declared.
+	savePoints []*_savePoint
+	spHints    []spHint
+
 	cv                *cvl.CVL
 	cvlHintsB4Open    map[string]interface{} // Hints set before CVLSess Opened
 	cvlEditConfigData []cmn.CVLEditConfigData
@@ -308,6 +337,7 @@ type DB struct {
 	sPubSub     *redis.PubSub // PubSub. non-Nil implies SubscribeDB
 	sCIP        bool          // Close in Progress
 	sOnCCacheDB *DB           // Update this DB for PubSub notifications
+	sTxID       string        // Committed Tx being notified (SEventTxBegin..End)
 
 	dbStatsConfig DBStatsConfig
 	dbCacheConfig DBCacheConfig
@@ -409,10 +439,13 @@ func NewDB(opt Options) (*DB, error) {
 	now = time.Now()
 
 	var rc *redis.Client
-	if opt.ForceNewRedisConnection {
-		rc = TransactionalRedisClient(opt.DBNo)
+	nsPresent := IsNamespacePresent(opt.Namespace)
+	if !nsPresent {
+		glog.Error("NewDB: Namespace not present: ", opt.Namespace)
+	} else if opt.ForceNewRedisConnection {
+		rc = NamespaceTransactionalRedisClient(opt.Namespace, opt.DBNo)
 	} else {
-		rc = RedisClient(opt.DBNo)
+		rc = NamespaceRedisClient(opt.Namespace, opt.DBNo)
 	}
 
 	d := DB{
@@ -427,12 +460,23 @@ func NewDB(opt Options) (*DB, error) {
 		cache:             dbCache{Tables: make(map[string]Table, InitialTablesCount), Maps: make(map[string]MAP, InitialMapsCount)},
 	}
 
+	if !nsPresent {
+		e = tlerr.InvalidArgs("Unknown namespace %s", opt.Namespace)
+		goto NewDBExit
+	}
+
 	if d.client == nil {
 		glog.Error("NewDB: Could not create redis client: ", d.Name())
 		e = tlerr.TranslibDBCannotOpen{}
 		goto NewDBExit
 	}
 
+	if opt.Trace != nil {
+		d.dbStatsConfig.TimeStats = true
+		d.dbStatsConfig.TableStats = true
+		d.dbStatsConfig.MapStats = true
+	}
+
 	if opt.IsCacheEnabled && opt.IsOnChangeEnabled {
 		glog.Error("Per Connection cache cannot be enabled with OnChange cache")
 		glog.Error("Disabling Per Connection caching")
@@ -575,6 +619,14 @@ func (d *DB) DeleteDB() error {
 
 	dbGlobalStats.updateStats(d.Opts.DBNo, false, 0, &(d.stats))
 
+	if d.Opts.Trace != nil {
+		name := d.Name()
+		if d.Opts.Namespace != DefaultNamespace {
+			name = d.Opts.Namespace + "/" + name
+		}
+		d.Opts.Trace.addDBStats(name, &(d.stats))
+	}
+
 	if d.txState != txStateNone {
 		glog.Warning("DeleteDB: not txStateNone, txState: ", d.txState)
 	}
@@ -663,6 +715,8 @@ func (d *DB) getEntry(ts *TableSpec, key Key, forceReadDB bool) (Value, error) {
 	// Time Start
 	var cacheHit bool
 	var txCacheHit bool
+	var gCacheHit bool
+	var gCacheSeq uint64
 	var now time.Time
 	var dur time.Duration
 	var stats Stats
@@ -675,11 +729,15 @@ func (d *DB) getEntry(ts *TableSpec, key Key, forceReadDB bool) (Value, error) {
 	var e error
 	var v map[string]string
 
+	_, span := tracing.Start(d.Opts.Ctxt, "db.getEntry",
+		attribute.String("db", d.Name()), attribute.String("table", ts.Name))
+
 	var ok bool
 	entry := d.key2redis(ts, key)
 	useCache := ((d.Opts.IsOnChangeEnabled && d.onCReg.isCacheTable(ts.Name)) ||
 		(d.dbCacheConfig.PerConnection &&
 			d.dbCacheConfig.isCacheTable(ts.Name)))
+	useGlobalCache := !forceReadDB && d.useGlobalCache(ts)
 
 	// check in Tx cache first
 	if value, ok = d.txTsEntryMap[ts.Name][entry]; !ok {
@@ -697,7 +755,13 @@ func (d *DB) getEntry(ts *TableSpec, key Key, forceReadDB bool) (Value, error) {
 		txCacheHit = true
 	}
 
-	if !cacheHit && !txCacheHit {
+	// Next, the (process wide) global cache
+	if !cacheHit && !txCacheHit && useGlobalCache {
+		value, gCacheSeq, gCacheHit = dbGlobalCache.get(&d.dbCacheConfig,
+			ts.Name, entry)
+	}
+
+	if !cacheHit && !txCacheHit && !gCacheHit {
 		// Increase (i.e. more verbose) V() level if it gets too noisy.
 		if glog.V(3) {
 			glog.Info("getEntry: RedisCmd: ", d.Name(), ": ", "HGETALL ", entry)
@@ -720,6 +784,9 @@ func (d *DB) getEntry(ts *TableSpec, key Key, forceReadDB bool) (Value, error) {
 		e = tlerr.TranslibRedisClientEntryNotExist{Entry: d.key2redis(ts, key)}
 
 	} else if !cacheHit && !txCacheHit && useCache {
+		if useGlobalCache && !gCacheHit {
+			dbGlobalCache.put(ts.Name, entry, value, gCacheSeq)
+		}
 		if _, ok := d.cache.Tables[ts.Name]; !ok {
 			if d.cache.Tables == nil {
 				d.cache.Tables = make(map[string]Table, d.onCReg.size())
@@ -733,6 +800,9 @@ func (d *DB) getEntry(ts *TableSpec, key Key, forceReadDB bool) (Value, error) {
 			}
 		}
 		d.cache.Tables[ts.Name].entry[entry] = value.Copy()
+
+	} else if !cacheHit && !txCacheHit && useGlobalCache && !gCacheHit {
+		dbGlobalCache.put(ts.Name, entry, value, gCacheSeq)
 	}
 
 	// Time End, Time, Peak
@@ -747,6 +817,9 @@ func (d *DB) getEntry(ts *TableSpec, key Key, forceReadDB bool) (Value, error) {
 	if cacheHit {
 		stats.GetEntryCacheHits++
 	}
+	if gCacheHit {
+		stats.GetEntryGlobalCacheHits++
+	}
 
 	if d.dbStatsConfig.TimeStats {
 		dur = time.Since(now)
@@ -768,6 +841,8 @@ func (d *DB) getEntry(ts *TableSpec, key Key, forceReadDB bool) (Value, error) {
 		d.stats.AllTables = stats
 	}
 
+	tracing.End(span, e)
+
 	if glog.V(3) {
 		glog.Info("GetEntry: End: ", "value: ", value, " e: ", e)
 	}
@@ -992,10 +1067,22 @@ func (d *DB) DeleteKeys(ts *TableSpec, key Key) error {
 }
 
 func (d *DB) doCVL(ts *TableSpec, cvlOps []cmn.CVLOperation, key Key, vals []Value) error {
+	keys := make([]Key, len(cvlOps))
+	for i := range keys {
+		keys[i] = key
+	}
+	return d.doCVLEntries(ts, cvlOps, keys, vals)
+}
+
+// doCVLEntries validates the operations on multiple entries of a table, in a
+// single ValidateEditConfig() call. cvlOps[i] is for keys[i] with vals[i].
+func (d *DB) doCVLEntries(ts *TableSpec, cvlOps []cmn.CVLOperation, keys []Key, vals []Value) error {
 	var e error = nil
 
 	var cvlRetCode cvl.CVLRetCode
 	var cei cvl.CVLErrorInfo
+	var now time.Time
+	var span trace.Span
 
 	if d.err != nil {
 		e = d.err
@@ -1014,8 +1101,8 @@ func (d *DB) doCVL(ts *TableSpec, cvlOps []cmn.CVLOperation, key Key, vals []Val
 		goto doCVLExit
 	}
 
-	if len(cvlOps) != len(vals) {
-		glog.Error("doCVL: Incorrect arguments len(cvlOps) != len(vals)")
+	if len(cvlOps) != len(vals) || len(cvlOps) != len(keys) {
+		glog.Error("doCVL: Incorrect arguments len(cvlOps) != len(vals|keys)")
 		e = errors.New("CVL Incorrect args")
 		return e
 	}
@@ -1024,7 +1111,7 @@ func (d *DB) doCVL(ts *TableSpec, cvlOps []cmn.CVLOperation, key Key, vals []Val
 		cvlEditConfigData := cmn.CVLEditConfigData{
 			VType: cmn.VALIDATE_ALL,
 			VOp:   cvlOps[i],
-			Key:   d.key2redis(ts, key),
+			Key:   d.key2redis(ts, keys[i]),
 			// Await CVL PR ReplaceOp: isReplaceOp,
 		}
 
@@ -1056,8 +1143,26 @@ func (d *DB) doCVL(ts *TableSpec, cvlOps []cmn.CVLOperation, key Key, vals []Val
 		glog.Info("doCVL: calling ValidateEditConfig: ", d.cvlEditConfigData)
 	}
 
+	if d.Opts.Trace != nil {
+		now = time.Now()
+	}
+
+	_, span = tracing.Start(d.Opts.Ctxt, "cvl.ValidateEditConfig",
+		attribute.String("table", ts.Name),
+		attribute.Int("edits", len(d.cvlEditConfigData)))
+
 	cei, cvlRetCode = d.cv.ValidateEditConfig(d.cvlEditConfigData)
 
+	if cvl.CVL_SUCCESS != cvlRetCode {
+		span.SetAttributes(attribute.Int("cvl.code", int(cvlRetCode)))
+		span.SetStatus(codes.Error, cei.ConstraintErrMsg)
+	}
+	span.End()
+
+	if d.Opts.Trace != nil {
+		d.Opts.Trace.addCVL(time.Since(now))
+	}
+
 	if cvl.CVL_SUCCESS != cvlRetCode {
 		glog.Warning("doCVL: CVL Failure: ", cvlRetCode)
 		// e = errors.New("CVL Failure: " + string(cvlRetCode))
@@ -1086,26 +1191,10 @@ func (d *DB) doWrite(ts *TableSpec, op _txOp, k Key, val interface{}) error {
 
 	key := k.Copy()
 
-	if d.Opts.IsWriteDisabled {
-		glog.Error("doWrite: Write to DB disabled")
-		e = errors.New("Write to DB disabled during this operation")
-		goto doWriteExit
-	}
-
-	if d.err != nil {
-		e = d.err
-		glog.Error("doWrite: DB in error: ", e)
+	if e = d.checkWrite(); e != nil {
 		goto doWriteExit
 	}
 
-	if d.Opts.DBNo == ConfigDB && !d.Opts.IsSession && !d.configDBLocked {
-		if e = ConfigDBTryLock(noSessionToken); e != nil {
-			glog.Errorf("doWrite: ConfigDB possibly locked: %s", e)
-			goto doWriteExit
-		}
-		d.configDBLocked = true
-	}
-
 	if d.Opts.IsSession && (d.Opts.TxCmdsLim != 0) &&
 		(len(d.txCmds) >= d.Opts.TxCmdsLim) {
 
@@ -1185,6 +1274,8 @@ func (d *DB) doWrite(ts *TableSpec, op _txOp, k Key, val interface{}) error {
 			e = errors.New("Unknown Op: " + string(rune(op)))
 		}
 
+		d.invalidateGlobalCache(ts, key)
+
 		// No Transaction. Only update the config-timestamp, and
 		// ignore the error, if any, since the actual operation succeeded.
 		if d.Opts.DBNo == ConfigDB && e == nil {
@@ -1231,6 +1322,13 @@ func (d *DB) doWrite(ts *TableSpec, op _txOp, k Key, val interface{}) error {
 
 	case txOpDel:
 		entry := d.key2redis(ts, key)
+		if _, ok := d.txTsEntryMap[ts.Name][entry]; !ok && d.Opts.IsSession {
+			// Record the entry being deleted, for CheckTxConflicts()
+			var v map[string]string
+			glog.V(3).Info("doWrite: RedisCmd: ", d.Name(), ": ", "HGETALL ", entry)
+			v, e = d.client.HGetAll(context.Background(), entry).Result()
+			d.doTxSPsaveHGetAll(ts, key, Value{Field: v})
+		}
 		delete(d.txTsEntryMap[ts.Name], entry)
 		d.txTsEntryMap[ts.Name][entry] = Value{Field: make(map[string]string)}
 
@@ -1276,6 +1374,30 @@ doWriteExit:
 	return e
 }
 
+// checkWrite verifies that the DB is writable, and acquires the ConfigDB lock
+// for non-session writes to the ConfigDB.
+func (d *DB) checkWrite() error {
+	if d.Opts.IsWriteDisabled {
+		glog.Error("doWrite: Write to DB disabled")
+		return errors.New("Write to DB disabled during this operation")
+	}
+
+	if d.err != nil {
+		glog.Error("doWrite: DB in error: ", d.err)
+		return d.err
+	}
+
+	if d.Opts.DBNo == ConfigDB && !d.Opts.IsSession && !d.configDBLocked {
+		if e := ConfigDBTryLock(noSessionToken); e != nil {
+			glog.Errorf("doWrite: ConfigDB possibly locked: %s", e)
+			return e
+		}
+		d.configDBLocked = true
+	}
+
+	return nil
+}
+
 // setEntry either Creates, or Sets an entry(row) in the table.
 func (d *DB) setEntry(ts *TableSpec, key Key, value Value, isCreate bool) error {
 
@@ -1743,7 +1865,9 @@ func (d *DB) commitTx() error {
 	}
 
 	var e error = nil
-	var tsmap map[TableSpec]bool = make(map[TableSpec]bool, len(d.txCmds)) // UpperBound
+
+	_, span := tracing.Start(d.Opts.Ctxt, "db.commitTx",
+		attribute.String("db", d.Name()), attribute.Int("cmds", len(d.txCmds)))
 
 	// Validate State
 	switch d.txState {
@@ -1773,109 +1897,19 @@ func (d *DB) commitTx() error {
 		goto CommitTxExit
 	}
 
-	// Issue MULTI
-	glog.Info("CommitTx: Do: MULTI")
-	_, e = d.client.Do(context.Background(), "MULTI").Result()
-
-	if e != nil {
-		glog.Warning("CommitTx: Do: MULTI e: ", e.Error())
-		goto CommitTxExit
-	}
-
-	// For each cmd in txCmds
-	//   Invoke it
-	for i := 0; i < len(d.txCmds); i++ {
-
-		var args []interface{}
-
-		redisKey := d.key2redis(d.txCmds[i].ts, *(d.txCmds[i].key))
-
-		// Add TS to the map of watchTables
-		tsmap[*(d.txCmds[i].ts)] = true
-
-		switch d.txCmds[i].op {
-
-		case txOpHMSet:
-
-			args = make([]interface{}, 0, len(d.txCmds[i].value.Field)*2+2)
-			args = append(args, "HMSET", redisKey)
-
-			for k, v := range d.txCmds[i].value.Field {
-				args = append(args, k, v)
-			}
-
-			_, e = d.client.Do(context.Background(), args...).Result()
-
-		case txOpHDel:
-
-			args = make([]interface{}, 0, len(d.txCmds[i].value.Field)+2)
-			args = append(args, "HDEL", redisKey)
-
-			for k := range d.txCmds[i].value.Field {
-				args = append(args, k)
-			}
-
-			_, e = d.client.Do(context.Background(), args...).Result()
-
-		case txOpDel:
-
-			args = make([]interface{}, 0, 2)
-			args = append(args, "DEL", redisKey)
-
-			_, e = d.client.Do(context.Background(), args...).Result()
-
-		default:
-			glog.Error("CommitTx: Unknown, op: ", d.txCmds[i].op)
-			e = errors.New("Unknown Op: " + string(rune(d.txCmds[i].op)))
-		}
-
-		glog.Info("CommitTx: RedisCmd: ", d.Name(), ": ", args)
-
-		if e != nil {
-			glog.Warning("CommitTx: Do: ", args, " e: ", e.Error())
-			break
-		}
-	}
-
-	if e != nil {
-		goto CommitTxExit
-	}
-
-	// Flag the Tables as updated.
-	for ts := range tsmap {
-		if glog.V(4) {
-			glog.Info("CommitTx: Do: SET ", d.ts2redisUpdated(&ts), " 1")
-		}
-		_, e = d.client.Do(context.Background(), "SET", d.ts2redisUpdated(&ts), "1").Result()
-		if e != nil {
-			glog.Warning("CommitTx: Do: SET ",
-				d.ts2redisUpdated(&ts), " 1: e: ",
-				e.Error())
-			break
-		}
-	}
-
-	if e != nil {
-		goto CommitTxExit
-	}
-
-	if e = d.markConfigDBUpdated(); e != nil {
-		goto CommitTxExit
-	}
-
-	// Issue EXEC
-	glog.Info("CommitTx: Do: EXEC")
-	_, e = d.client.Do(context.Background(), "EXEC").Result()
+	// Pipeline the cmds in MULTI/EXEC (chunks)
+	e = d.execTxCmds()
 
-	if e != nil {
-		glog.Warning("CommitTx: Do: EXEC e: ", e.Error())
-		e = tlerr.TranslibTransactionFail{}
+CommitTxExit:
+	// The global cache entries of the keys written are stale, even if the
+	// commit failed (the EXEC could have applied part of the cmds).
+	for _, txCmd := range d.txCmds {
+		d.invalidateGlobalCache(txCmd.ts, *(txCmd.key))
 	}
 
-CommitTxExit:
 	// Switch State, Clear Command list
 	d.txState = txStateNone
-	d.txCmds = d.txCmds[:0]
+	d.truncTxCmds(0)
 	d.cvlEditConfigData = d.cvlEditConfigData[:0]
 	d.txTsEntryMap = make(map[string]map[string]Value)
 	d.txTsEntryHGetAll = make(map[string]map[string]Value)
@@ -1889,6 +1923,8 @@ CommitTxExit:
 		d.cv = nil
 	}
 
+	tracing.End(span, e)
+
 	if glog.V(3) {
 		glog.Info("CommitTx: End: e: ", e)
 	}
@@ -1937,7 +1973,7 @@ func (d *DB) abortTx() error {
 AbortTxExit:
 	// Switch State, Clear Command list
 	d.txState = txStateNone
-	d.txCmds = d.txCmds[:0]
+	d.truncTxCmds(0)
 	d.cvlEditConfigData = d.cvlEditConfigData[:0]
 	d.txTsEntryMap = make(map[string]map[string]Value)
 	d.txTsEntryHGetAll = make(map[string]map[string]Value)
@@ -1981,6 +2017,8 @@ func getOperationName(op _txOp) string {
 		return "HDEL"
 	case txOpDel:
 		return "DEL"
+	case txOpExpire:
+		return "PEXPIRE"
 	}
 	return ""
 }
@@ -2045,6 +2083,8 @@ func (d *DB) getEntries(ts *TableSpec, keys []Key, forceReadDB bool) ([]Value, [
 	var txCacheHit bool
 	var cacheChk bool
 	var tblExist bool
+	var gCacheHit bool
+	var gCacheSeq uint64
 
 	var tbl Table
 
@@ -2054,6 +2094,7 @@ func (d *DB) getEntries(ts *TableSpec, keys []Key, forceReadDB bool) ([]Value, [
 		cacheChk = true
 		tbl, tblExist = d.cache.Tables[ts.Name]
 	}
+	useGlobalCache := !forceReadDB && d.useGlobalCache(ts)
 
 	if d.dbStatsConfig.TableStats {
 		stats = d.stats.Tables[ts.Name]
@@ -2086,7 +2127,20 @@ func (d *DB) getEntries(ts *TableSpec, keys []Key, forceReadDB bool) ([]Value, [
 			}
 		}
 
-		if !cacheHit && !txCacheHit {
+		gCacheHit = false
+		if !cacheHit && !txCacheHit && useGlobalCache {
+			var value Value
+			var seq uint64
+			if value, seq, gCacheHit = dbGlobalCache.get(&d.dbCacheConfig,
+				ts.Name, entry); gCacheHit {
+				values[idx] = value
+				stats.GetEntryGlobalCacheHits++
+			} else if gCacheSeq == 0 {
+				gCacheSeq = seq
+			}
+		}
+
+		if !cacheHit && !txCacheHit && !gCacheHit {
 			keyIdxs = append(keyIdxs, idx)
 			dbKeys = append(dbKeys, entry)
 		}
@@ -2149,6 +2203,9 @@ func (d *DB) getEntries(ts *TableSpec, keys []Key, forceReadDB bool) ([]Value, [
 						}
 						d.cache.Tables[ts.Name].entry[dbKey] = dbValue.Copy()
 					}
+					if useGlobalCache {
+						dbGlobalCache.put(ts.Name, dbKey, dbValue, gCacheSeq)
+					}
 				} else if e == nil {
 					if glog.V(4) {
 						glog.Info("GetEntries: pipe.HGetAll(): empty map for the key: ", dbKey)
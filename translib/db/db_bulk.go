@@ -0,0 +1,388 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	cmn "github.com/Azure/sonic-mgmt-common/cvl/common"
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+	"github.com/redis/go-redis/v9"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// SetEntries sets multiple entries(rows) in the table, with the semantics of
+// SetEntry() for each of them. keys[i] is set to values[i]. All the entries
+// are validated by CVL in a single call, and none of them are written if the
+// validation fails. Outside a Transaction, the writes are pipelined to redis.
+func (d *DB) SetEntries(ts *TableSpec, keys []Key, values []Value) error {
+	if glog.V(3) {
+		glog.Info("SetEntries: Begin: ", d.Name(), ": ts: ", ts, " #keys: ",
+			len(keys))
+	}
+
+	if !d.IsOpen() {
+		return ConnectionClosed
+	}
+
+	if len(keys) != len(values) {
+		glog.Error("SetEntries: Incorrect arguments len(keys) != len(values)")
+		return tlerr.TranslibDBNotSupported{
+			Description: "SetEntries: Incorrect args"}
+	}
+
+	// Current entries, to prepare the HDel list (See setEntry())
+	currValues, currErrs := d.GetEntries(ts, keys)
+
+	cvlOps := make([]cmn.CVLOperation, 0, len(keys))
+	cvlKeys := make([]Key, 0, len(keys))
+	cvlVals := make([]Value, 0, len(keys))
+	cmds := make([]_txCmd, 0, len(keys))
+
+	for i := range keys {
+		key, value := keys[i], values[i]
+
+		if len(value.Field) == 0 {
+			if ts.NoDelete {
+				glog.Info("SetEntries: NoDelete flag is true, skipping deletion of ", key)
+				continue
+			}
+			cvlOps = append(cvlOps, cmn.OP_DELETE)
+			cvlKeys = append(cvlKeys, key)
+			cvlVals = append(cvlVals, Value{})
+			cmds = append(cmds, _txCmd{ts: ts, op: txOpDel, key: &key})
+			continue
+		}
+
+		if currErrs[i] != nil {
+			cvlOps = append(cvlOps, cmn.OP_CREATE)
+			cvlKeys = append(cvlKeys, key)
+			cvlVals = append(cvlVals, value)
+			cmds = append(cmds, _txCmd{ts: ts, op: txOpHMSet, key: &key,
+				value: &value})
+			continue
+		}
+
+		valueComplement := Value{Field: make(map[string]string)}
+		for k := range currValues[i].Field {
+			if _, present := value.Field[k]; !present {
+				valueComplement.Field[k] = ""
+			}
+		}
+
+		cvlOps = append(cvlOps, cmn.OP_UPDATE)
+		cvlKeys = append(cvlKeys, key)
+		cvlVals = append(cvlVals, value)
+		cmds = append(cmds, _txCmd{ts: ts, op: txOpHMSet, key: &key,
+			value: &value})
+
+		if len(valueComplement.Field) != 0 {
+			cvlOps = append(cvlOps, cmn.OP_DELETE)
+			cvlKeys = append(cvlKeys, key)
+			cvlVals = append(cvlVals, valueComplement)
+			cmds = append(cmds, _txCmd{ts: ts, op: txOpHDel, key: &key,
+				value: &valueComplement})
+		}
+	}
+
+	e := d.doBulkWrite(ts, cvlOps, cvlKeys, cvlVals, cmds)
+
+	if glog.V(3) {
+		glog.Info("SetEntries: End: e: ", e)
+	}
+
+	return e
+}
+
+// DeleteEntries deletes multiple entries(rows) in the table. All the entries
+// are validated by CVL in a single call, and none of them are deleted if the
+// validation fails. Outside a Transaction, the deletes are pipelined to redis.
+func (d *DB) DeleteEntries(ts *TableSpec, keys []Key) error {
+	if glog.V(3) {
+		glog.Info("DeleteEntries: Begin: ", d.Name(), ": ts: ", ts,
+			" #keys: ", len(keys))
+	}
+
+	if !d.IsOpen() {
+		return ConnectionClosed
+	}
+
+	if ts.NoDelete {
+		glog.Info("DeleteEntries: NoDelete flag is true, skipping deletion of ",
+			len(keys), " keys")
+		return nil
+	}
+
+	cvlOps := make([]cmn.CVLOperation, len(keys))
+	cvlVals := make([]Value, len(keys))
+	cmds := make([]_txCmd, len(keys))
+	for i := range keys {
+		cvlOps[i] = cmn.OP_DELETE
+		cmds[i] = _txCmd{ts: ts, op: txOpDel, key: &keys[i]}
+	}
+
+	e := d.doBulkWrite(ts, cvlOps, keys, cvlVals, cmds)
+
+	if glog.V(3) {
+		glog.Info("DeleteEntries: End: e: ", e)
+	}
+
+	return e
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// doBulkWrite validates the (cvlOps, keys, vals) in one CVL call, and then
+// writes the cmds, either to the Transaction, or pipelined to redis.
+func (d *DB) doBulkWrite(ts *TableSpec, cvlOps []cmn.CVLOperation, keys []Key,
+	vals []Value, cmds []_txCmd) error {
+
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	if e := d.doCVLEntries(ts, cvlOps, keys, vals); e != nil {
+		return e
+	}
+
+	// Transaction case: Each cmd is cached, and replayed on CommitTx.
+	if d.txState != txStateNone {
+		for i := range cmds {
+			var val interface{}
+			if cmds[i].value != nil {
+				val = *(cmds[i].value)
+			}
+			if e := d.doWrite(ts, cmds[i].op, *(cmds[i].key), val); e != nil {
+				return e
+			}
+		}
+		return nil
+	}
+
+	// No Transaction case. Pipeline the cmds.
+	if e := d.checkWrite(); e != nil {
+		return e
+	}
+
+	pipe := d.client.Pipeline()
+	for i := range cmds {
+		args := d.txCmdArgs(&cmds[i])
+		glog.Info("doBulkWrite: RedisCmd: ", d.Name(), ": ", args)
+		pipe.Do(context.Background(), args...)
+	}
+
+	_, e := pipe.Exec(context.Background())
+	if e != nil {
+		glog.Error("doBulkWrite: ", d.Name(), ": Pipeline Exec: e: ", e)
+	}
+
+	// The global cache entries of the keys written are stale, even if some
+	// of the cmds failed.
+	for i := range cmds {
+		d.invalidateGlobalCache(cmds[i].ts, *(cmds[i].key))
+	}
+
+	// Only update the config-timestamp, and ignore the error, if any,
+	// since the actual operations succeeded.
+	if d.Opts.DBNo == ConfigDB && e == nil {
+		d.markConfigDBUpdated()
+	}
+
+	return e
+}
+
+// txCmdArgs returns the redis command args for the cmd.
+func (d *DB) txCmdArgs(cmd *_txCmd) []interface{} {
+	var args []interface{}
+
+	redisKey := d.key2redis(cmd.ts, *(cmd.key))
+
+	switch cmd.op {
+	case txOpHMSet:
+		args = make([]interface{}, 0, len(cmd.value.Field)*2+2)
+		args = append(args, "HMSET", redisKey)
+		for k, v := range cmd.value.Field {
+			args = append(args, k, v)
+		}
+
+	case txOpHDel:
+		args = make([]interface{}, 0, len(cmd.value.Field)+2)
+		args = append(args, "HDEL", redisKey)
+		for k := range cmd.value.Field {
+			args = append(args, k)
+		}
+
+	case txOpDel:
+		args = append(args, "DEL", redisKey)
+
+	case txOpExpire:
+		if cmd.ttl > 0 {
+			args = append(args, "PEXPIRE", redisKey, cmd.ttl.Milliseconds())
+		} else {
+			args = append(args, "PERSIST", redisKey)
+		}
+	}
+
+	return args
+}
+
+// execTxCmds pipelines the txCmds to redis in a MULTI/EXEC. If the txCmds
+// exceed Opts.TxChunkSize, they are queued in the MULTI in pipelines of
+// TxChunkSize cmds. The transaction is atomic, and guarded by the WATCH, as a
+// whole, irrespective of the chunks.
+func (d *DB) execTxCmds() error {
+	var beginCmds, endCmds [][]interface{}
+	txID := newTxID()
+
+	// Tx begin marker is published first. The change log record, and the Tx
+	// end marker are last.
+	if len(d.txCmds) != 0 {
+		beginCmds = [][]interface{}{d.txEventArgs(txEventBegin, txID)}
+		endCmds = [][]interface{}{d.txEventArgs(txEventEnd, txID)}
+		if clog := d.changeLogArgs(txID); clog != nil {
+			endCmds = append([][]interface{}{clog}, endCmds...)
+		}
+	}
+
+	chunkSize := d.Opts.TxChunkSize
+	if chunkSize <= 0 || len(d.txCmds) <= chunkSize {
+		return d.execTxChunk(d.txCmds, beginCmds, endCmds)
+	}
+
+	glog.Infof("CommitTx: %d cmds, in chunks of %d", len(d.txCmds), chunkSize)
+
+	ctx := context.Background()
+	glog.Info("CommitTx: Do: MULTI")
+	e := d.client.Do(ctx, "MULTI").Err()
+
+	for start := 0; e == nil && start < len(d.txCmds); start += chunkSize {
+		var preCmds, postCmds [][]interface{}
+		end := min(start+chunkSize, len(d.txCmds))
+		if start == 0 {
+			preCmds = beginCmds
+		}
+		if end == len(d.txCmds) {
+			postCmds = endCmds
+		}
+
+		pipe := d.client.Pipeline()
+		if e = d.queueTxCmds(pipe, d.txCmds[start:end], preCmds,
+			postCmds); e == nil {
+			_, e = pipe.Exec(ctx)
+		}
+		if e != nil {
+			glog.Errorf("CommitTx: Chunk [%d:%d] fails: %v", start, end, e)
+			glog.Info("CommitTx: Do: DISCARD")
+			d.client.Do(ctx, "DISCARD")
+		}
+	}
+
+	if e == nil {
+		glog.Info("CommitTx: Do: EXEC")
+		var replies []interface{}
+		replies, e = d.client.Do(ctx, "EXEC").Slice()
+		for i := 0; e == nil && i < len(replies); i++ {
+			e, _ = replies[i].(error)
+		}
+	}
+
+	if e != nil {
+		glog.Warning("CommitTx: Do: EXEC e: ", e.Error())
+		e = tlerr.TranslibTransactionFail{}
+	}
+
+	return e
+}
+
+// execTxChunk pipelines the cmds in a MULTI/EXEC, along with flagging
+// the tables as updated. preCmds, and postCmds (if any) are pipelined
+// before, and after the cmds respectively.
+func (d *DB) execTxChunk(cmds []_txCmd, preCmds, postCmds [][]interface{}) error {
+	pipe := d.client.TxPipeline()
+
+	e := d.queueTxCmds(pipe, cmds, preCmds, postCmds)
+	if e != nil {
+		pipe.Discard()
+		return e
+	}
+
+	glog.Info("CommitTx: Do: EXEC")
+	if _, e = pipe.Exec(context.Background()); e != nil {
+		glog.Warning("CommitTx: Do: EXEC e: ", e.Error())
+		e = tlerr.TranslibTransactionFail{}
+	}
+
+	return e
+}
+
+// queueTxCmds adds the cmds to the pipe, along with flagging the tables as
+// updated. preCmds, and postCmds (if any) are added before, and after the
+// cmds respectively.
+func (d *DB) queueTxCmds(pipe redis.Pipeliner, cmds []_txCmd, preCmds,
+	postCmds [][]interface{}) error {
+
+	tsmap := make(map[TableSpec]bool)
+
+	for _, args := range preCmds {
+		glog.V(3).Info("CommitTx: RedisCmd: ", d.Name(), ": ", args)
+		pipe.Do(context.Background(), args...)
+	}
+
+	for i := range cmds {
+		args := d.txCmdArgs(&cmds[i])
+		if args == nil {
+			glog.Error("CommitTx: Unknown, op: ", cmds[i].op)
+			return errors.New("Unknown Op: " + string(rune(cmds[i].op)))
+		}
+
+		glog.Info("CommitTx: RedisCmd: ", d.Name(), ": ", args)
+		pipe.Do(context.Background(), args...)
+
+		// Add TS to the map of watchTables
+		tsmap[*(cmds[i].ts)] = true
+	}
+
+	// Flag the Tables as updated.
+	for ts := range tsmap {
+		if glog.V(4) {
+			glog.Info("CommitTx: Do: SET ", d.ts2redisUpdated(&ts), " 1")
+		}
+		pipe.Do(context.Background(), "SET", d.ts2redisUpdated(&ts), "1")
+	}
+
+	pipe.Do(context.Background(), "SET", d.ts2redisUpdated(&TableSpec{Name: "*"}),
+		strconv.FormatInt(time.Now().UnixNano(), 10))
+
+	for _, args := range postCmds {
+		glog.V(3).Info("CommitTx: RedisCmd: ", d.Name(), ": ", args)
+		pipe.Do(context.Background(), args...)
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+var BULK_PF string = "DBBULK_TST_" + strconv.FormatInt(int64(os.Getpid()), 10)
+
+func bulkTestKeysValues(n int) ([]Key, []Value) {
+	keys := make([]Key, n)
+	values := make([]Value, n)
+	for i := 0; i < n; i++ {
+		keys[i] = Key{Comp: []string{"KEY" + strconv.Itoa(i)}}
+		values[i] = Value{Field: map[string]string{
+			"idx": strconv.Itoa(i), "desc": "entry " + strconv.Itoa(i)}}
+	}
+	return keys, values
+}
+
+func verifyBulkEntries(t *testing.T, d *DB, ts *TableSpec, keys []Key, values []Value) {
+	t.Helper()
+	for i := range keys {
+		v, e := d.GetEntry(ts, keys[i])
+		if e != nil {
+			t.Errorf("GetEntry(%v) fails e: %v", keys[i], e)
+		} else if !reflect.DeepEqual(v, values[i]) {
+			t.Errorf("GetEntry(%v) = %v; expected %v", keys[i], v, values[i])
+		}
+	}
+}
+
+func verifyBulkNoEntries(t *testing.T, d *DB, ts *TableSpec) {
+	t.Helper()
+	if keys, e := d.GetKeys(ts); e != nil || len(keys) != 0 {
+		t.Errorf("GetKeys(%v) = %v, %v; expected no keys", ts.Name, keys, e)
+	}
+}
+
+func TestSetDeleteEntriesNoTx(t *testing.T) {
+	ts := &TableSpec{Name: BULK_PF + "_NOTX"}
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(d, ts, t) })
+
+	keys, values := bulkTestKeysValues(20)
+	if e = d.SetEntries(ts, keys, values); e != nil {
+		t.Fatalf("SetEntries() fails e: %v", e)
+	}
+	verifyBulkEntries(t, d, ts, keys, values)
+
+	// Replace semantics: Fields not in the new value are removed.
+	values[0] = Value{Field: map[string]string{"idx": "100"}}
+	values[1] = Value{Field: map[string]string{"desc": "new"}}
+	if e = d.SetEntries(ts, keys[:2], values[:2]); e != nil {
+		t.Fatalf("SetEntries() replace fails e: %v", e)
+	}
+	verifyBulkEntries(t, d, ts, keys, values)
+
+	// Empty value maps to delete.
+	if e = d.SetEntries(ts, keys[:1], []Value{{}}); e != nil {
+		t.Fatalf("SetEntries() empty value fails e: %v", e)
+	}
+	if _, e = d.GetEntry(ts, keys[0]); e == nil {
+		t.Errorf("GetEntry(%v) succeeds after SetEntries() with empty value", keys[0])
+	}
+
+	// NoDelete: The entries are not deleted.
+	tsNoDel := *ts
+	tsNoDel.NoDelete = true
+	if e = d.DeleteEntries(&tsNoDel, keys[1:]); e != nil {
+		t.Fatalf("DeleteEntries() NoDelete fails e: %v", e)
+	}
+	verifyBulkEntries(t, d, ts, keys[1:], values[1:])
+
+	if e = d.DeleteEntries(ts, keys); e != nil {
+		t.Fatalf("DeleteEntries() fails e: %v", e)
+	}
+	verifyBulkNoEntries(t, d, ts)
+
+	if e = d.SetEntries(ts, keys, values[:1]); e == nil {
+		t.Errorf("SetEntries() with mismatched keys, values succeeds")
+	}
+}
+
+func TestSetDeleteEntriesTxChunks(t *testing.T) {
+	for _, chunkSize := range []int{0, 3, 100} {
+		t.Run("chunk"+strconv.Itoa(chunkSize), func(t *testing.T) {
+			testSetDeleteEntriesTx(t, chunkSize)
+		})
+	}
+}
+
+func testSetDeleteEntriesTx(t *testing.T, chunkSize int) {
+	ts := &TableSpec{Name: BULK_PF + "_TX" + strconv.Itoa(chunkSize)}
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(d, ts, t) })
+	d.Opts.TxChunkSize = chunkSize
+
+	keys, values := bulkTestKeysValues(10)
+
+	if e = d.StartTx(nil, []*TableSpec{ts}); e != nil {
+		t.Fatalf("StartTx() fails e: %v", e)
+	}
+	if e = d.SetEntries(ts, keys, values); e != nil {
+		t.Fatalf("SetEntries() fails e: %v", e)
+	}
+	// Reads within the Transaction see the bulk writes.
+	verifyBulkEntries(t, d, ts, keys, values)
+	if e = d.CommitTx(); e != nil {
+		t.Fatalf("CommitTx() fails e: %v", e)
+	}
+	verifyBulkEntries(t, d, ts, keys, values)
+
+	if e = d.StartTx(nil, []*TableSpec{ts}); e != nil {
+		t.Fatalf("StartTx() fails e: %v", e)
+	}
+	if e = d.DeleteEntries(ts, keys); e != nil {
+		t.Fatalf("DeleteEntries() fails e: %v", e)
+	}
+	if e = d.CommitTx(); e != nil {
+		t.Fatalf("CommitTx() fails e: %v", e)
+	}
+	verifyBulkNoEntries(t, d, ts)
+}
+
+func TestCommitTxChunksWatchFail(t *testing.T) {
+	ts := &TableSpec{Name: BULK_PF + "_WATCH"}
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(d, ts, t) })
+	d.Opts.TxChunkSize = 3
+
+	d2, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { d2.DeleteDB() })
+
+	keys, values := bulkTestKeysValues(10)
+
+	if e = d.StartTx(nil, []*TableSpec{ts}); e != nil {
+		t.Fatalf("StartTx() fails e: %v", e)
+	}
+	if e = d.SetEntries(ts, keys, values); e != nil {
+		t.Fatalf("SetEntries() fails e: %v", e)
+	}
+
+	// Modify the WATCHed table flag, so that the transaction fails.
+	if e = d2.client.Set(context.Background(), d2.ts2redisUpdated(ts), "1", 0).Err(); e != nil {
+		t.Fatalf("Set(%s) fails e: %v", d2.ts2redisUpdated(ts), e)
+	}
+
+	if e = d.CommitTx(); e == nil {
+		t.Fatalf("CommitTx() succeeds after WATCHed table is modified")
+	}
+	verifyBulkNoEntries(t, d, ts)
+}
+
+func TestCommitTxChunksLaterFail(t *testing.T) {
+	ts := &TableSpec{Name: BULK_PF + "_LATER"}
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(d, ts, t) })
+	d.Opts.TxChunkSize = 3
+
+	keys, values := bulkTestKeysValues(10)
+
+	if e = d.StartTx(nil, []*TableSpec{ts}); e != nil {
+		t.Fatalf("StartTx() fails e: %v", e)
+	}
+	if e = d.SetEntries(ts, keys, values); e != nil {
+		t.Fatalf("SetEntries() fails e: %v", e)
+	}
+
+	// A bad cmd in the last chunk fails the whole transaction.
+	d.txCmds = append(d.txCmds, _txCmd{ts: ts, op: txOpNone, key: &keys[0]})
+
+	if e = d.CommitTx(); e == nil {
+		t.Fatalf("CommitTx() succeeds with a bad cmd in the last chunk")
+	}
+	verifyBulkNoEntries(t, d, ts)
+
+	// The connection is usable after the failure.
+	if e = d.SetEntries(ts, keys, values); e != nil {
+		t.Fatalf("SetEntries() after the failure fails e: %v", e)
+	}
+	verifyBulkEntries(t, d, ts, keys, values)
+}
@@ -21,10 +21,10 @@ package db
 
 import (
 	// "fmt"
-	// "strconv"
 
 	// "errors"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 	// "github.com/Azure/sonic-mgmt-common/cvl"
@@ -48,7 +48,7 @@ type DBGlobalCache struct {
 
 type DBCacheConfig struct {
 	PerConnection bool            // Enable per DB conn cache
-	Global        bool            // Enable global cache (TBD)
+	Global        bool            // Enable global cache
 	CacheTables   map[string]bool // Only cache these tables.
 	// Empty == Cache all tables
 	NoCacheTables map[string]bool // Do not cache these tables.
@@ -57,6 +57,10 @@ type DBCacheConfig struct {
 	// Empty == Cache all maps
 	NoCacheMaps map[string]bool // Do not cache these maps
 	// "all" == Do not cache any maps
+	GlobalCacheTables map[string]bool // CONFIG_DB tables in global cache
+	// Empty == Do not cache any tables
+	GlobalMaxEntries uint // Global cache max entries (0 == default)
+	GlobalMaxBytes   uint // Global cache max (approx.) bytes (0 == default)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -67,8 +71,9 @@ func ReconfigureCache() error {
 	return dbCacheConfig.reconfigure()
 }
 
+// ClearCache flushes the global cache.
 func ClearCache() error {
-	return nil // TBD for Global Cache
+	return dbGlobalCache.clear()
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -106,10 +111,14 @@ func getDBCacheConfig() DBCacheConfig {
 		NoCacheTables: make(map[string]bool, len(dbCacheConfig.NoCacheTables)),
 		CacheMaps:     make(map[string]bool, len(dbCacheConfig.CacheMaps)),
 		NoCacheMaps:   make(map[string]bool, len(dbCacheConfig.NoCacheMaps)),
+		GlobalCacheTables: make(map[string]bool,
+			len(dbCacheConfig.GlobalCacheTables)),
 	}
 
 	cacheConfig.PerConnection = dbCacheConfig.PerConnection
 	cacheConfig.Global = dbCacheConfig.Global
+	cacheConfig.GlobalMaxEntries = dbCacheConfig.GlobalMaxEntries
+	cacheConfig.GlobalMaxBytes = dbCacheConfig.GlobalMaxBytes
 
 	for k, v := range dbCacheConfig.CacheTables {
 		cacheConfig.CacheTables[k] = v
@@ -127,6 +136,10 @@ func getDBCacheConfig() DBCacheConfig {
 		cacheConfig.NoCacheMaps[k] = v
 	}
 
+	for k, v := range dbCacheConfig.GlobalCacheTables {
+		cacheConfig.GlobalCacheTables[k] = v
+	}
+
 	mutexCacheConfig.Unlock()
 
 	return cacheConfig
@@ -200,6 +213,14 @@ func (config *DBCacheConfig) readFromDB() error {
 			config.NoCacheMaps[k] = v
 		}
 
+		config.GlobalCacheTables = make(map[string]bool,
+			len(defaultDBCacheConfig.GlobalCacheTables))
+		for k, v := range defaultDBCacheConfig.GlobalCacheTables {
+			config.GlobalCacheTables[k] = v
+		}
+		config.GlobalMaxEntries = defaultDBCacheConfig.GlobalMaxEntries
+		config.GlobalMaxBytes = defaultDBCacheConfig.GlobalMaxBytes
+
 	} else {
 		for k, v := range fields {
 			switch {
@@ -235,6 +256,20 @@ func (config *DBCacheConfig) readFromDB() error {
 				for _, t := range l {
 					config.NoCacheMaps[t] = true
 				}
+			case k == "@global_cache_tables":
+				l := strings.Split(v, ",")
+				config.GlobalCacheTables = make(map[string]bool, len(l))
+				for _, t := range l {
+					config.GlobalCacheTables[t] = true
+				}
+			case k == "global_cache_max_entries":
+				if n, e := strconv.ParseUint(v, 10, 0); e == nil {
+					config.GlobalMaxEntries = uint(n)
+				}
+			case k == "global_cache_max_bytes":
+				if n, e := strconv.ParseUint(v, 10, 0); e == nil {
+					config.GlobalMaxBytes = uint(n)
+				}
 			}
 		}
 	}
@@ -258,3 +293,18 @@ func (config *DBCacheConfig) isCacheMap(name string) bool {
 	}
 	return false
 }
+
+func (config *DBCacheConfig) isGlobalCacheTable(name string) bool {
+	return config.GlobalCacheTables[name]
+}
+
+func (config *DBCacheConfig) globalCacheLimits() (uint, uint) {
+	maxEntries, maxBytes := config.GlobalMaxEntries, config.GlobalMaxBytes
+	if maxEntries == 0 {
+		maxEntries = defaultGlobalCacheMaxEntries
+	}
+	if maxBytes == 0 {
+		maxBytes = defaultGlobalCacheMaxBytes
+	}
+	return maxEntries, maxBytes
+}
@@ -0,0 +1,254 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/redis/go-redis/v9"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// ChangeLogStream is the redis stream in the CONFIG_DB, to which a record is
+// appended for each committed Transaction, when the change log is enabled
+// ("change_log" field of TRANSLIB_DB|default is "True").
+const ChangeLogStream = "CONFIG_DB_CHANGELOG"
+
+// ChangeLogOp is a redis command of a committed Transaction.
+type ChangeLogOp struct {
+	Op     string            `json:"op"` // HMSET, HDEL, DEL, PEXPIRE
+	Table  string            `json:"table"`
+	Key    string            `json:"key"`              // Key components joined by the separator
+	Fields map[string]string `json:"fields,omitempty"` // Field values set (HMSET), or fields deleted (HDEL)
+	TTL    time.Duration     `json:"ttl,omitempty"`    // PEXPIRE
+}
+
+// ChangeLogRecord is the change log of a committed Transaction.
+type ChangeLogRecord struct {
+	ID   string        // Stream entry ID
+	TxID string        // Transaction ID
+	User string        // Options.User of the DB
+	Time time.Time     // Commit time
+	Ops  []ChangeLogOp // Commands, in the order of execution
+}
+
+type DBChangeLogConfig struct {
+	Enabled bool  // Append to the ChangeLogStream on commit
+	MaxLen  int64 // Approximate max records retained in the stream
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// ReadChangeLog returns the change log records of the Transactions committed
+// at, or after the since time, in the order of commit.
+func ReadChangeLog(since time.Time) ([]ChangeLogRecord, error) {
+	client := RedisClient(ConfigDB)
+	defer CloseRedisClient(client)
+
+	start := strconv.FormatInt(since.UnixMilli(), 10)
+	msgs, e := client.XRange(context.Background(), ChangeLogStream, start,
+		"+").Result()
+	if e != nil {
+		glog.Error("ReadChangeLog: XRANGE ", ChangeLogStream, " ", start,
+			" + : e: ", e)
+		return nil, e
+	}
+
+	records := make([]ChangeLogRecord, 0, len(msgs))
+	for _, msg := range msgs {
+		records = append(records, changeLogMsg2Record(msg))
+	}
+
+	return records, nil
+}
+
+// ReconfigureChangeLog re-reads the change log configuration.
+func ReconfigureChangeLog() error {
+	return dbChangeLogConfig.handleReconfigureSignal()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+var dbChangeLogConfig *DBChangeLogConfig = &DBChangeLogConfig{}
+var defaultDBChangeLogConfig DBChangeLogConfig = DBChangeLogConfig{
+	MaxLen: 10000,
+}
+var reconfigureChangeLogConfig bool = true
+var mutexChangeLogConfig sync.Mutex
+
+var txIDCounter uint64
+var mutexTxID sync.Mutex
+
+// newTxID returns a unique ID for a Transaction, as
+// <unix time in ns>-<pid>-<counter>
+func newTxID() string {
+	mutexTxID.Lock()
+	txIDCounter++
+	ctr := txIDCounter
+	mutexTxID.Unlock()
+
+	return strconv.FormatInt(time.Now().UnixNano(), 10) + "-" +
+		strconv.Itoa(os.Getpid()) + "-" + strconv.FormatUint(ctr, 10)
+}
+
+// changeLogArgs returns the XADD args for the change log record of the txCmds
+// being committed, or nil if the change log is disabled.
+func (d *DB) changeLogArgs(txID string) []interface{} {
+	config := getChangeLogConfig()
+	if !config.Enabled || d.Opts.DBNo != ConfigDB {
+		return nil
+	}
+
+	ops := make([]ChangeLogOp, 0, len(d.txCmds))
+	for i := range d.txCmds {
+		cmd := &d.txCmds[i]
+		op := ChangeLogOp{
+			Op:    getOperationName(cmd.op),
+			Table: cmd.ts.Name,
+			Key:   strings.Join(cmd.key.Comp, d.Opts.KeySeparator),
+		}
+
+		switch cmd.op {
+		case txOpHMSet:
+			op.Fields = cmd.value.Copy().Field
+		case txOpHDel:
+			op.Fields = make(map[string]string, len(cmd.value.Field))
+			for k := range cmd.value.Field {
+				op.Fields[k] = ""
+			}
+		case txOpExpire:
+			op.TTL = cmd.ttl
+		}
+		ops = append(ops, op)
+	}
+
+	opsJson, e := json.Marshal(ops)
+	if e != nil {
+		glog.Error("changeLogArgs: json.Marshal: e: ", e)
+		return nil
+	}
+
+	args := []interface{}{"XADD", ChangeLogStream}
+	if config.MaxLen > 0 {
+		args = append(args, "MAXLEN", "~", config.MaxLen)
+	}
+	args = append(args, "*",
+		"txid", txID,
+		"user", d.Opts.User,
+		"time", strconv.FormatInt(time.Now().UnixNano(), 10),
+		"ops", string(opsJson))
+
+	return args
+}
+
+func changeLogMsg2Record(msg redis.XMessage) ChangeLogRecord {
+	record := ChangeLogRecord{ID: msg.ID}
+
+	if v, ok := msg.Values["txid"].(string); ok {
+		record.TxID = v
+	}
+	if v, ok := msg.Values["user"].(string); ok {
+		record.User = v
+	}
+	if v, ok := msg.Values["time"].(string); ok {
+		if ns, e := strconv.ParseInt(v, 10, 64); e == nil {
+			record.Time = time.Unix(0, ns)
+		}
+	}
+	if v, ok := msg.Values["ops"].(string); ok {
+		if e := json.Unmarshal([]byte(v), &record.Ops); e != nil {
+			glog.Warning("changeLogMsg2Record: ", msg.ID, ": ops: e: ", e)
+		}
+	}
+
+	return record
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Configure Change Log                                                      //
+////////////////////////////////////////////////////////////////////////////////
+
+func getChangeLogConfig() DBChangeLogConfig {
+	dbChangeLogConfig.reconfigure()
+	mutexChangeLogConfig.Lock()
+	config := *dbChangeLogConfig
+	mutexChangeLogConfig.Unlock()
+	return config
+}
+
+func (config *DBChangeLogConfig) reconfigure() error {
+	mutexChangeLogConfig.Lock()
+	var doReconfigure bool = reconfigureChangeLogConfig
+	reconfigureChangeLogConfig = false
+	mutexChangeLogConfig.Unlock()
+
+	if doReconfigure {
+		var readConfig DBChangeLogConfig
+		readConfig.readFromDB()
+
+		mutexChangeLogConfig.Lock()
+		dbChangeLogConfig = &readConfig
+		mutexChangeLogConfig.Unlock()
+	}
+	return nil
+}
+
+func (config *DBChangeLogConfig) handleReconfigureSignal() error {
+	mutexChangeLogConfig.Lock()
+	reconfigureChangeLogConfig = true
+	mutexChangeLogConfig.Unlock()
+	return nil
+}
+
+func (config *DBChangeLogConfig) readFromDB() error {
+	*config = defaultDBChangeLogConfig
+	fields, e := readRedis("TRANSLIB_DB|default")
+	if e != nil {
+		return e
+	}
+
+	for k, v := range fields {
+		switch {
+		case k == "change_log" && v == "True":
+			config.Enabled = true
+		case k == "change_log" && v == "False":
+			config.Enabled = false
+		case k == "change_log_maxlen":
+			if n, e := strconv.ParseInt(v, 10, 64); e == nil {
+				config.MaxLen = n
+			}
+		}
+	}
+	return nil
+}
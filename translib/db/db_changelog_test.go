@@ -0,0 +1,131 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var CLOG_PF string = "DBCLOG_TST_" + strconv.FormatInt(int64(os.Getpid()), 10)
+
+func enableChangeLog(t *testing.T) {
+	mutexChangeLogConfig.Lock()
+	saved := dbChangeLogConfig
+	dbChangeLogConfig = &DBChangeLogConfig{Enabled: true, MaxLen: 1000}
+	reconfigureChangeLogConfig = false
+	mutexChangeLogConfig.Unlock()
+
+	t.Cleanup(func() {
+		mutexChangeLogConfig.Lock()
+		dbChangeLogConfig = saved
+		mutexChangeLogConfig.Unlock()
+	})
+}
+
+func TestChangeLog(t *testing.T) {
+	ts := &TableSpec{Name: CLOG_PF}
+	key := Key{Comp: []string{"KEY1", "KEY2"}}
+	value := Value{Field: map[string]string{"f1": "v1", "f2": "v2"}}
+	user := "clog_" + strconv.Itoa(os.Getpid())
+
+	enableChangeLog(t)
+
+	d, e := NewDB(Options{
+		DBNo:                    ConfigDB,
+		TableNameSeparator:      "|",
+		KeySeparator:            "|",
+		DisableCVLCheck:         true,
+		ForceNewRedisConnection: true,
+		User:                    user,
+	})
+	if e != nil {
+		t.Fatalf("NewDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(d, ts, t) })
+
+	since := time.Now()
+
+	if e = d.StartTx(nil, []*TableSpec{ts}); e != nil {
+		t.Fatalf("StartTx() fails e: %v", e)
+	}
+	if e = d.SetEntry(ts, key, value); e != nil {
+		t.Fatalf("SetEntry() fails e: %v", e)
+	}
+	if e = d.DeleteEntryFields(ts, key, Value{Field: map[string]string{"f2": ""}}); e != nil {
+		t.Fatalf("DeleteEntryFields() fails e: %v", e)
+	}
+	if e = d.CommitTx(); e != nil {
+		t.Fatalf("CommitTx() fails e: %v", e)
+	}
+
+	records, e := ReadChangeLog(since)
+	if e != nil {
+		t.Fatalf("ReadChangeLog() fails e: %v", e)
+	}
+
+	var record *ChangeLogRecord
+	for i := range records {
+		if records[i].User == user {
+			record = &records[i]
+		}
+	}
+	if record == nil {
+		t.Fatalf("ReadChangeLog() missing record for user %s: %v", user, records)
+	}
+
+	expOps := []ChangeLogOp{
+		{Op: "HMSET", Table: ts.Name, Key: "KEY1|KEY2", Fields: value.Field},
+		{Op: "HDEL", Table: ts.Name, Key: "KEY1|KEY2", Fields: map[string]string{"f2": ""}},
+	}
+	if !reflect.DeepEqual(record.Ops, expOps) {
+		t.Errorf("ChangeLogRecord.Ops = %v; expected %v", record.Ops, expOps)
+	}
+	if len(record.TxID) == 0 || record.Time.Before(since) {
+		t.Errorf("ChangeLogRecord = %+v; TxID or Time invalid", *record)
+	}
+
+	// Aborted Transaction is not logged
+	if e = d.StartTx(nil, []*TableSpec{ts}); e != nil {
+		t.Fatalf("StartTx() fails e: %v", e)
+	}
+	if e = d.DeleteEntry(ts, key); e != nil {
+		t.Fatalf("DeleteEntry() fails e: %v", e)
+	}
+	if e = d.AbortTx(); e != nil {
+		t.Fatalf("AbortTx() fails e: %v", e)
+	}
+
+	if records, e = ReadChangeLog(since); e != nil {
+		t.Fatalf("ReadChangeLog() fails e: %v", e)
+	}
+	nRecords := 0
+	for _, r := range records {
+		if r.User == user {
+			nRecords++
+		}
+	}
+	if nRecords != 1 {
+		t.Errorf("ReadChangeLog() has %d records; expected 1 (none for AbortTx)", nRecords)
+	}
+}
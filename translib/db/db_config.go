@@ -24,13 +24,24 @@ import (
 	"fmt"
 	io "io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 
+	cmn "github.com/Azure/sonic-mgmt-common/cvl/common"
 	"github.com/golang/glog"
 )
 
+// DefaultNamespace is the namespace of the host (or single ASIC) databases,
+// whose config is the database_config.json.
+const DefaultNamespace = ""
+
 var dbConfigMap = make(map[string]interface{})
 
+// dbNsConfigMap is the database_config.json of each namespace, other than
+// the DefaultNamespace, on a multi ASIC platform.
+var dbNsConfigMap = make(map[string]map[string]interface{})
+
 func dbConfigInit() {
 	dbConfigPath := "/var/run/redis/sonic-db/database_config.json"
 	if path, ok := os.LookupEnv("DB_CONFIG_PATH"); ok {
@@ -57,27 +68,30 @@ func dbConfigInit() {
 			assert(err)
 		}
 	}
+
+	dbGlobalConfigInit()
 }
 
 func assert(msg error) {
 	panic(msg)
 }
 
-func getDbList() map[string]interface{} {
-	dbEntries, ok := dbConfigMap["DATABASES"].(map[string]interface{})
+func getDbList(ns string) map[string]interface{} {
+	dbEntries, ok := getDbConfig(ns)["DATABASES"].(map[string]interface{})
 	if !ok {
 		assert(fmt.Errorf("DATABASES is invalid key."))
 	}
 	return dbEntries
 }
 
-func isDbInstPresent(dbName string) bool {
-	_, ok := getDbList()[dbName]
+func isDbInstPresent(ns, dbName string) bool {
+	_, ok := getDbList(ns)[dbName]
 	return ok
 }
 
-func getDbInst(dbName string) map[string]interface{} {
-	db, ok := dbConfigMap["DATABASES"].(map[string]interface{})[dbName]
+func getDbInst(ns, dbName string) map[string]interface{} {
+	dbConfig := getDbConfig(ns)
+	db, ok := dbConfig["DATABASES"].(map[string]interface{})[dbName]
 	if !ok {
 		assert(fmt.Errorf("database name '%v' is not found", dbName))
 	}
@@ -85,15 +99,15 @@ func getDbInst(dbName string) map[string]interface{} {
 	if !ok {
 		assert(fmt.Errorf("'instance' is not a valid field"))
 	}
-	inst, ok := dbConfigMap["INSTANCES"].(map[string]interface{})[instName.(string)]
+	inst, ok := dbConfig["INSTANCES"].(map[string]interface{})[instName.(string)]
 	if !ok {
 		assert(fmt.Errorf("instance name '%v' is not found", instName))
 	}
 	return inst.(map[string]interface{})
 }
 
-func getDbSeparator(dbName string) string {
-	dbEntries := getDbList()
+func getDbSeparator(ns, dbName string) string {
+	dbEntries := getDbList(ns)
 	separator, ok := dbEntries[dbName].(map[string]interface{})["separator"]
 	if !ok {
 		assert(fmt.Errorf("'separator' is not a valid field"))
@@ -101,8 +115,8 @@ func getDbSeparator(dbName string) string {
 	return separator.(string)
 }
 
-func getDbId(dbName string) int {
-	dbEntries := getDbList()
+func getDbId(ns, dbName string) int {
+	dbEntries := getDbList(ns)
 	id, ok := dbEntries[dbName].(map[string]interface{})["id"]
 	if !ok {
 		assert(fmt.Errorf("'id' is not a valid field"))
@@ -110,8 +124,8 @@ func getDbId(dbName string) int {
 	return int(id.(float64))
 }
 
-func getDbHostName(dbName string) string {
-	inst := getDbInst(dbName)
+func getDbHostName(ns, dbName string) string {
+	inst := getDbInst(ns, dbName)
 	hostname, ok := inst["hostname"]
 	if !ok {
 		assert(fmt.Errorf("'hostname' is not a valid field"))
@@ -119,8 +133,8 @@ func getDbHostName(dbName string) string {
 	return hostname.(string)
 }
 
-func getDbPort(dbName string) int {
-	inst := getDbInst(dbName)
+func getDbPort(ns, dbName string) int {
+	inst := getDbInst(ns, dbName)
 	port, ok := inst["port"]
 	if !ok {
 		assert(fmt.Errorf("'port' is not a valid field"))
@@ -128,14 +142,14 @@ func getDbPort(dbName string) int {
 	return int(port.(float64))
 }
 
-func getDbTcpAddr(dbName string) string {
-	hostname := getDbHostName(dbName)
-	port := getDbPort(dbName)
+func getDbTcpAddr(ns, dbName string) string {
+	hostname := getDbHostName(ns, dbName)
+	port := getDbPort(ns, dbName)
 	return hostname + ":" + strconv.Itoa(port)
 }
 
-func getDbSock(dbName string) string {
-	inst := getDbInst(dbName)
+func getDbSock(ns, dbName string) string {
+	inst := getDbInst(ns, dbName)
 	if unix_socket_path, ok := inst["unix_socket_path"]; ok {
 		return unix_socket_path.(string)
 	} else {
@@ -144,8 +158,8 @@ func getDbSock(dbName string) string {
 	}
 }
 
-func getDbPassword(dbName string) string {
-	inst := getDbInst(dbName)
+func getDbPassword(ns, dbName string) string {
+	inst := getDbInst(ns, dbName)
 	password := ""
 	password_path, ok := inst["password_path"]
 	if !ok {
@@ -160,6 +174,138 @@ func getDbPassword(dbName string) string {
 	return password
 }
 
+// getDbUsername returns the redis ACL user of the instance, if any.
+func getDbUsername(ns, dbName string) string {
+	inst := getDbInst(ns, dbName)
+	if username, ok := inst["username"].(string); ok {
+		return username
+	}
+	return ""
+}
+
+// getDbTLSConfig returns the TLS configuration of the instance, from the
+// "tls_*" fields. TLS is enabled by "tls_enabled": true.
+func getDbTLSConfig(ns, dbName string) cmn.RedisTLSConfig {
+	return cmn.RedisTLSConfigOf(getDbInst(ns, dbName))
+}
+
 func GetDbConfigMap() map[string]interface{} {
 	return dbConfigMap
 }
+
+// GetDbConfigMapForNamespace returns the database_config.json contents of
+// the namespace, or nil if the namespace is not present.
+func GetDbConfigMapForNamespace(ns string) map[string]interface{} {
+	if ns == DefaultNamespace {
+		return dbConfigMap
+	}
+	return dbNsConfigMap[ns]
+}
+
+// GetNamespaces returns the namespaces listed in the database_global.json,
+// including the DefaultNamespace (first). On a single ASIC platform, only
+// the DefaultNamespace is returned.
+func GetNamespaces() []string {
+	namespaces := make([]string, 0, len(dbNsConfigMap)+1)
+	for ns := range dbNsConfigMap {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return append([]string{DefaultNamespace}, namespaces...)
+}
+
+// IsMultiNamespace returns true on a multi ASIC platform, where the
+// database_global.json lists namespaces other than the DefaultNamespace.
+func IsMultiNamespace() bool {
+	return len(dbNsConfigMap) != 0
+}
+
+// IsNamespacePresent returns true if the namespace is the DefaultNamespace,
+// or is listed in the database_global.json.
+func IsNamespacePresent(ns string) bool {
+	return GetDbConfigMapForNamespace(ns) != nil
+}
+
+func getDbConfig(ns string) map[string]interface{} {
+	dbConfig := GetDbConfigMapForNamespace(ns)
+	if dbConfig == nil {
+		assert(fmt.Errorf("namespace '%v' is not found", ns))
+	}
+	return dbConfig
+}
+
+// dbGlobalConfigInit loads the database_config.json of each namespace listed
+// in the database_global.json. The file is only present on multi ASIC
+// platforms.
+func dbGlobalConfigInit() {
+	dbGlobalConfigPath := "/var/run/redis/sonic-db/database_global.json"
+	if path, ok := os.LookupEnv("DB_GLOBAL_CONFIG_PATH"); ok {
+		dbGlobalConfigPath = path
+	}
+
+	if _, e := os.Stat(dbGlobalConfigPath); e != nil {
+		glog.V(3).Info("dbGlobalConfigInit: No ", dbGlobalConfigPath)
+		return
+	}
+
+	nsConfigMap, e := loadDbGlobalConfig(dbGlobalConfigPath)
+	if e != nil {
+		glog.Error("dbGlobalConfigInit: ", dbGlobalConfigPath, ": e: ", e)
+		return
+	}
+
+	dbNsConfigMap = nsConfigMap
+	glog.Info("dbGlobalConfigInit: namespaces: ", GetNamespaces())
+}
+
+// loadDbGlobalConfig reads a database_global.json, and returns the
+// database_config.json of each namespace in its INCLUDES. The include paths
+// are relative to the directory of the database_global.json. The include
+// without a namespace is the DefaultNamespace, which is skipped, since it is
+// loaded from the DB_CONFIG_PATH.
+func loadDbGlobalConfig(path string) (map[string]map[string]interface{}, error) {
+	data, e := io.ReadFile(path)
+	if e != nil {
+		return nil, e
+	}
+
+	var globalConfig struct {
+		Includes []struct {
+			Namespace string `json:"namespace"`
+			Include   string `json:"include"`
+		} `json:"INCLUDES"`
+	}
+	if e = json.Unmarshal(data, &globalConfig); e != nil {
+		return nil, e
+	}
+
+	nsConfigMap := make(map[string]map[string]interface{})
+	for _, inc := range globalConfig.Includes {
+		if inc.Namespace == DefaultNamespace {
+			continue
+		}
+
+		incPath := inc.Include
+		if !filepath.IsAbs(incPath) {
+			incPath = filepath.Join(filepath.Dir(path), incPath)
+		}
+
+		if data, e = io.ReadFile(incPath); e != nil {
+			return nil, fmt.Errorf("namespace %v: %v", inc.Namespace, e)
+		}
+
+		dbConfig := make(map[string]interface{})
+		if e = json.Unmarshal(data, &dbConfig); e != nil {
+			return nil, fmt.Errorf("namespace %v: %s: %v", inc.Namespace,
+				incPath, e)
+		}
+		if _, ok := dbConfig["DATABASES"].(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("namespace %v: %s: DATABASES is invalid key",
+				inc.Namespace, incPath)
+		}
+
+		nsConfigMap[inc.Namespace] = dbConfig
+	}
+
+	return nsConfigMap, nil
+}
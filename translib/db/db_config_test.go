@@ -0,0 +1,144 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+)
+
+const testNsDbConfig = `{
+    "INSTANCES": {
+        "redis": {
+            "hostname" : "127.0.0.1",
+            "port" : 6380,
+            "unix_socket_path": "/var/run/redis0/redis.sock"
+        }
+    },
+    "DATABASES" : {
+        "CONFIG_DB" : {
+            "id" : 4,
+            "separator": "|",
+            "instance" : "redis"
+        }
+    },
+    "VERSION" : "1.0"
+}`
+
+const testDbGlobalConfig = `{
+    "INCLUDES" : [
+        {
+            "include" : "../../redis/sonic-db/database_config.json"
+        },
+        {
+            "namespace" : "asic0",
+            "include" : "../../redis0/sonic-db/database_config.json"
+        }
+    ],
+    "VERSION" : "1.0"
+}`
+
+func writeTestDbGlobalConfig(t *testing.T) string {
+	dir := t.TempDir()
+	nsDir := filepath.Join(dir, "run", "redis0", "sonic-db")
+	globalDir := filepath.Join(dir, "run", "redis", "sonic-db")
+	for _, d := range []string{nsDir, globalDir} {
+		if e := os.MkdirAll(d, 0755); e != nil {
+			t.Fatal(e)
+		}
+	}
+	if e := os.WriteFile(filepath.Join(nsDir, "database_config.json"),
+		[]byte(testNsDbConfig), 0644); e != nil {
+		t.Fatal(e)
+	}
+	path := filepath.Join(globalDir, "database_global.json")
+	if e := os.WriteFile(path, []byte(testDbGlobalConfig), 0644); e != nil {
+		t.Fatal(e)
+	}
+	return path
+}
+
+func TestLoadDbGlobalConfig(t *testing.T) {
+	nsConfigMap, e := loadDbGlobalConfig(writeTestDbGlobalConfig(t))
+	if e != nil {
+		t.Fatal("loadDbGlobalConfig() failed: ", e)
+	}
+	if len(nsConfigMap) != 1 || nsConfigMap["asic0"] == nil {
+		t.Fatal("loadDbGlobalConfig() unexpected namespaces: ", nsConfigMap)
+	}
+
+	if _, e = loadDbGlobalConfig(filepath.Join(t.TempDir(), "none.json")); e == nil {
+		t.Error("loadDbGlobalConfig() of missing file succeeded")
+	}
+}
+
+func TestNamespaceDbConfig(t *testing.T) {
+	nsConfigMap, e := loadDbGlobalConfig(writeTestDbGlobalConfig(t))
+	if e != nil {
+		t.Fatal("loadDbGlobalConfig() failed: ", e)
+	}
+
+	saved := dbNsConfigMap
+	dbNsConfigMap = nsConfigMap
+	defer func() { dbNsConfigMap = saved }()
+
+	if !IsMultiNamespace() {
+		t.Error("IsMultiNamespace() false")
+	}
+	if ns := GetNamespaces(); !reflect.DeepEqual(ns, []string{"", "asic0"}) {
+		t.Error("GetNamespaces() unexpected: ", ns)
+	}
+	if IsNamespacePresent("asic1") {
+		t.Error("IsNamespacePresent(asic1) true")
+	}
+
+	if addr := getDbTcpAddr("asic0", "CONFIG_DB"); addr != "127.0.0.1:6380" {
+		t.Error("getDbTcpAddr(asic0) unexpected: ", addr)
+	}
+	if isDbInstPresent("asic0", "STATE_DB") {
+		t.Error("isDbInstPresent(asic0, STATE_DB) true")
+	}
+
+	redisOpts := adjustRedisOpts(&Options{DBNo: ConfigDB, Namespace: "asic0"})
+	if redisOpts.Network != DefaultRedisUNIXNetwork ||
+		redisOpts.Addr != "/var/run/redis0/redis.sock" || redisOpts.DB != 4 {
+		t.Error("adjustRedisOpts(asic0) unexpected: ", redisOpts)
+	}
+
+	redisOpts = adjustRedisOpts(&Options{DBNo: ConfigDB})
+	if redisOpts.Addr == "/var/run/redis0/redis.sock" {
+		t.Error("adjustRedisOpts(default) used asic0 instance")
+	}
+}
+
+func TestNewDBInvalidNamespace(t *testing.T) {
+	d, e := NewDB(Options{DBNo: ConfigDB, Namespace: "asic99"})
+	if e == nil {
+		d.DeleteDB()
+		t.Fatal("NewDB() with invalid namespace succeeded")
+	}
+	if _, ok := e.(tlerr.InvalidArgsError); !ok {
+		t.Errorf("NewDB() with invalid namespace: %T %v", e, e)
+	}
+}
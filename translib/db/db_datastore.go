@@ -19,24 +19,34 @@
 
 package db
 
-// import (
-// "errors"
-// "flag"
-// "reflect"
-// "strconv"
-// "strings"
-// "sync"
-// "time"
-
-// "github.com/Azure/sonic-mgmt-common/cvl"
-// "github.com/go-redis/redis/v7"
-// "github.com/golang/glog"
-// )
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+)
 
 ////////////////////////////////////////////////////////////////////////////////
 //  Exported Types                                                            //
 ////////////////////////////////////////////////////////////////////////////////
 
+// DefaultCheckpointsDir is the default CHECKPOINTS_DIR, where the CONFIG_DB
+// of the commit-ids are saved. See SetCheckpointsDir().
+const DefaultCheckpointsDir = "/etc/sonic/checkpoints/"
+
+// CheckpointExt is the CHECKPOINT_EXT of the files in the CHECKPOINTS_DIR
+// (CheckpointGzExt, if compressed)
+const (
+	CheckpointExt   = ".cp.json"
+	CheckpointGzExt = CheckpointExt + ".gz"
+)
+
 type DBDatastore interface {
 
 	// Eg: Commit-ID, Filename(Future), Snapshot-ID(Future), Writable(Future)
@@ -56,6 +66,35 @@ func (ds *CommitIdDbDs) Attributes() map[string]string {
 	}
 }
 
+// Path returns the file of the saved-to-disk CONFIG_DB of the commit-id.
+// (The compressed file, if only it exists)
+func (ds *CommitIdDbDs) Path() string {
+	dir := GetCheckpointsDir()
+	path := dir + ds.CommitID + CheckpointExt
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		gzPath := dir + ds.CommitID + CheckpointGzExt
+		if _, err = os.Stat(gzPath); err == nil {
+			return gzPath
+		}
+	}
+	return path
+}
+
+// SetCheckpointsDir sets the CHECKPOINTS_DIR (with the trailing "/"), Eg: by
+// the checkpoint storage backend.
+func SetCheckpointsDir(dir string) {
+	checkpointsDirMutex.Lock()
+	checkpointsDir = dir
+	checkpointsDirMutex.Unlock()
+}
+
+// GetCheckpointsDir returns the CHECKPOINTS_DIR.
+func GetCheckpointsDir() string {
+	checkpointsDirMutex.Lock()
+	defer checkpointsDirMutex.Unlock()
+	return checkpointsDir
+}
+
 // DefaultDbDs is the default Datastore representing the data
 // stored in the CONFIG_DB database(/selection) of the redis-server
 type DefaultDbDs struct {
@@ -64,3 +103,101 @@ type DefaultDbDs struct {
 func (ds *DefaultDbDs) Attributes() map[string]string {
 	return map[string]string{}
 }
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+var checkpointsDir = DefaultCheckpointsDir
+var checkpointsDirMutex sync.Mutex
+
+// getConfig reads the tables (all, if len(tables) == 0) from the saved-to-disk
+// CONFIG_DB (config_db.json format) of the commit-id. The list (leaf-list)
+// fields are converted to the "field@" comma separated redis fields.
+func (ds *CommitIdDbDs) getConfig(d *DB, tables []*TableSpec) (map[TableSpec]Table, error) {
+	glog.Infof("getConfig: %s: tables: %v", ds.Path(), tables)
+
+	data, err := ds.readFile()
+	if err != nil {
+		glog.Errorf("getConfig: %s: err: %v", ds.Path(), err)
+		if os.IsNotExist(err) {
+			return nil, tlerr.NotFound("Checkpoint %s not found", ds.CommitID)
+		}
+		return nil, err
+	}
+
+	var config map[string]map[string]map[string]interface{}
+	if err = json.Unmarshal(data, &config); err != nil {
+		glog.Errorf("getConfig: %s: json err: %v", ds.Path(), err)
+		return nil, tlerr.New("Invalid checkpoint %s: %v", ds.CommitID, err)
+	}
+
+	var tsM map[string]bool
+	if len(tables) != 0 {
+		tsM = make(map[string]bool, len(tables))
+		for _, ts := range tables {
+			tsM[ts.Name] = true
+		}
+	}
+
+	tblM := make(map[TableSpec]Table, len(config))
+	for tName, entries := range config {
+		if tsM != nil && !tsM[tName] {
+			continue
+		}
+
+		ts := TableSpec{Name: tName}
+		table := Table{
+			ts:       &ts,
+			entry:    make(map[string]Value, len(entries)),
+			complete: true,
+			db:       d,
+		}
+
+		for key, fields := range entries {
+			value := Value{Field: make(map[string]string, len(fields))}
+			for f, fv := range fields {
+				switch fv := fv.(type) {
+				case string:
+					value.Field[f] = fv
+				case []interface{}:
+					list := make([]string, 0, len(fv))
+					for _, lv := range fv {
+						list = append(list, fmt.Sprint(lv))
+					}
+					value.Field[f+"@"] = strings.Join(list, ",")
+				default:
+					value.Field[f] = fmt.Sprint(fv)
+				}
+			}
+			table.entry[tName+d.Opts.TableNameSeparator+key] = value
+		}
+
+		tblM[ts] = table
+	}
+
+	glog.Infof("getConfig: %s: End: #tblM: %v", ds.Path(), len(tblM))
+	return tblM, nil
+}
+
+// readFile reads the saved-to-disk CONFIG_DB, uncompressing it if required.
+func (ds *CommitIdDbDs) readFile() ([]byte, error) {
+	path := ds.Path()
+	if !strings.HasSuffix(path, CheckpointGzExt) {
+		return os.ReadFile(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
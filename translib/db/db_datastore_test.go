@@ -0,0 +1,118 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"compress/gzip"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestCommitIdDbDsGetConfig(t *testing.T) {
+	SetCheckpointsDir(t.TempDir() + "/")
+	t.Cleanup(func() { SetCheckpointsDir(DefaultCheckpointsDir) })
+
+	ds := &CommitIdDbDs{CommitID: "cp1"}
+	cpJson := `{
+  "PORT": {"Ethernet0": {"mtu": "9100", "admin_status": "up"}},
+  "VLAN": {"Vlan10": {"vlanid": "10", "members": ["Ethernet0", "Ethernet4"]}},
+  "VLAN_MEMBER": {"Vlan10|Ethernet0": {"tagging_mode": "untagged"}}
+}`
+	if e := os.WriteFile(ds.Path(), []byte(cpJson), 0644); e != nil {
+		t.Fatalf("WriteFile(%s) fails e: %v", ds.Path(), e)
+	}
+
+	d, e := NewDB(Options{
+		DBNo:               ConfigDB,
+		TableNameSeparator: "|",
+		KeySeparator:       "|",
+		IsWriteDisabled:    true,
+		Datastore:          ds,
+	})
+	if e != nil {
+		t.Fatalf("NewDB() fails e: %v", e)
+	}
+	defer d.DeleteDB()
+
+	tblM, e := d.GetConfig(nil, nil)
+	if e != nil {
+		t.Fatalf("GetConfig() fails e: %v", e)
+	}
+	if len(tblM) != 3 {
+		t.Errorf("GetConfig() returns %d tables, expected 3", len(tblM))
+	}
+
+	vlan := tblM[TableSpec{Name: "VLAN"}]
+	v, e := vlan.GetEntry(Key{Comp: []string{"Vlan10"}})
+	expected := map[string]string{"vlanid": "10", "members@": "Ethernet0,Ethernet4"}
+	if e != nil || !reflect.DeepEqual(v.Field, expected) {
+		t.Errorf("VLAN|Vlan10 = %v, e: %v, expected %v", v, e, expected)
+	}
+
+	vlanMember := tblM[TableSpec{Name: "VLAN_MEMBER"}]
+	if _, e = vlanMember.GetEntry(Key{Comp: []string{"Vlan10", "Ethernet0"}}); e != nil {
+		t.Errorf("VLAN_MEMBER|Vlan10|Ethernet0 GetEntry() fails e: %v", e)
+	}
+
+	tblM, e = d.GetConfig([]*TableSpec{{Name: "PORT"}}, nil)
+	if e != nil || len(tblM) != 1 {
+		t.Errorf("GetConfig(PORT) = %v, e: %v, expected 1 table", tblM, e)
+	}
+
+	d.Opts.Datastore = &CommitIdDbDs{CommitID: "cp2"}
+	if _, e = d.GetConfig(nil, nil); e == nil {
+		t.Errorf("GetConfig() of missing checkpoint succeeds")
+	}
+}
+
+func TestCommitIdDbDsGetConfigGz(t *testing.T) {
+	SetCheckpointsDir(t.TempDir() + "/")
+	t.Cleanup(func() { SetCheckpointsDir(DefaultCheckpointsDir) })
+
+	ds := &CommitIdDbDs{CommitID: "cp1"}
+	f, e := os.Create(GetCheckpointsDir() + "cp1" + CheckpointGzExt)
+	if e != nil {
+		t.Fatalf("Create() fails e: %v", e)
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte(`{"PORT": {"Ethernet0": {"mtu": "9100"}}}`))
+	zw.Close()
+	f.Close()
+
+	if ds.Path() != GetCheckpointsDir()+"cp1"+CheckpointGzExt {
+		t.Errorf("Path() = %s, expected the compressed file", ds.Path())
+	}
+
+	d, e := NewDB(Options{DBNo: ConfigDB, IsWriteDisabled: true, Datastore: ds})
+	if e != nil {
+		t.Fatalf("NewDB() fails e: %v", e)
+	}
+	defer d.DeleteDB()
+
+	tblM, e := d.GetConfig(nil, nil)
+	if e != nil || len(tblM) != 1 {
+		t.Fatalf("GetConfig() = %v, e: %v, expected 1 table", tblM, e)
+	}
+	port := tblM[TableSpec{Name: "PORT"}]
+	if v, _ := port.GetEntry(Key{Comp: []string{"Ethernet0"}}); v.Get("mtu") != "9100" {
+		t.Errorf("PORT|Ethernet0 = %v, expected mtu 9100", v)
+	}
+}
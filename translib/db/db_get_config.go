@@ -43,6 +43,8 @@ type GetConfigOptions struct {
 //   - OnChange not supported [IsEnableOnChange == false]
 //   - PCC (per_connection_cache) is not supported, and it will log an error/
 //     warning.
+//   - If the Datastore is a CommitIdDbDs, the tables are read from its
+//     saved-to-disk CONFIG_DB.
 func (d *DB) GetConfig(tables []*TableSpec, opt *GetConfigOptions) (map[TableSpec]Table, error) {
 
 	if glog.V(3) {
@@ -68,6 +70,10 @@ func (d *DB) GetConfig(tables []*TableSpec, opt *GetConfigOptions) (map[TableSpe
 		return nil, err
 	}
 
+	if ds, ok := d.Opts.Datastore.(*CommitIdDbDs); ok {
+		return ds.getConfig(d, tables)
+	}
+
 	if d.dbCacheConfig.PerConnection {
 		glog.Warning("GetConfig: Per Connection Cache not supported")
 	}
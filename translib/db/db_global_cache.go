@@ -0,0 +1,349 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// GlobalCacheStats are the statistics of the process wide CONFIG_DB read
+// cache. They are reported in the CONFIG_DB DBStats of GetDBStats().
+type GlobalCacheStats struct {
+	Subscribed bool `json:"subscribed"`
+
+	Entries    uint `json:"entries"`
+	Bytes      uint `json:"bytes"`
+	MaxEntries uint `json:"max-entries"`
+	MaxBytes   uint `json:"max-bytes"`
+
+	Hits          uint    `json:"hits"`
+	Misses        uint    `json:"misses"`
+	HitRatio      float64 `json:"hit-ratio"`
+	Evictions     uint    `json:"evictions"`
+	Invalidations uint    `json:"invalidations"`
+	Flushes       uint    `json:"flushes"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// globalCache is a read-through LRU cache of CONFIG_DB entries, shared by
+// all the DB connections of the process. It is kept coherent by keyspace
+// notifications of a SubscribeDB on the cached tables. The cache is only
+// used while the subscription is up; entries read while a change to the
+// table was in flight are not cached (see seq).
+type globalCache struct {
+	mu sync.Mutex
+
+	lru     *list.List               // *globalCacheEntry, Front is the MRU
+	entries map[string]*list.Element // redis key to lru element
+	bytes   uint
+
+	maxEntries uint
+	maxBytes   uint
+
+	// seq is bumped on every invalidation, and flush. A get() miss returns
+	// the current seq, and the put() is dropped if the table (tableSeq) or
+	// the whole cache (flushSeq) was invalidated since.
+	seq      uint64
+	flushSeq uint64
+	tableSeq map[string]uint64
+
+	sdb         *DB       // SubscribeDB for the keyspace notifications
+	tables      []string  // Tables subscribed to
+	subscribing bool      // SubscribeDB in progress
+	retryAt     time.Time // Do not attempt SubscribeDB before this
+
+	stats GlobalCacheStats
+}
+
+type globalCacheEntry struct {
+	table string
+	entry string
+	value Value
+	size  uint
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	defaultGlobalCacheMaxEntries = 16384
+	defaultGlobalCacheMaxBytes   = 32 * 1024 * 1024
+	globalCacheRetryInterval     = 30 * time.Second
+	globalCacheEntryOverhead     = 64
+)
+
+var dbGlobalCache = newGlobalCache()
+
+func newGlobalCache() *globalCache {
+	return &globalCache{
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element, InitialTableEntryCount),
+		tableSeq: make(map[string]uint64, InitialTablesCount),
+	}
+}
+
+// useGlobalCache returns true if the reads of the table ts should go through
+// the global cache. The reads in a transaction bypass it, as they are
+// WATCHed, and must see the redis values.
+func (d *DB) useGlobalCache(ts *TableSpec) bool {
+	return d.dbCacheConfig.Global && d.Opts.DBNo == ConfigDB &&
+		d.Opts.Namespace == DefaultNamespace &&
+		!d.Opts.IsSession && !d.Opts.IsSubscribeDB &&
+		d.txState == txStateNone &&
+		d.dbCacheConfig.isGlobalCacheTable(ts.Name)
+}
+
+// invalidateGlobalCache drops the entry from the global cache, on a write
+// from this process. This avoids a stale read, before the keyspace
+// notification for the write is received.
+func (d *DB) invalidateGlobalCache(ts *TableSpec, key Key) {
+	if d.Opts.DBNo == ConfigDB && d.Opts.Namespace == DefaultNamespace {
+		dbGlobalCache.invalidate(ts.Name, d.key2redis(ts, key))
+	}
+}
+
+// get returns a copy of the cached Value of the redis key entry. On a miss,
+// it returns the seq to be passed to the put() of the Value read from redis.
+// The subscription is (re)started, if it is not up.
+func (c *globalCache) get(config *DBCacheConfig, table, entry string) (Value, uint64, bool) {
+	c.mu.Lock()
+	if c.sdb == nil {
+		c.mu.Unlock()
+		c.subscribe(config)
+		c.mu.Lock()
+	}
+	defer c.mu.Unlock()
+
+	if c.sdb == nil {
+		return Value{}, 0, false
+	}
+
+	if elem, ok := c.entries[entry]; ok {
+		c.lru.MoveToFront(elem)
+		c.stats.Hits++
+		return elem.Value.(*globalCacheEntry).value.Copy(), c.seq, true
+	}
+
+	c.stats.Misses++
+	return Value{}, c.seq, false
+}
+
+// put adds the Value of the redis key entry, read after the get() miss which
+// returned seq, evicting the least recently used entries if required.
+func (c *globalCache) put(table, entry string, value Value, seq uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sdb == nil || seq == 0 || seq < c.flushSeq || seq < c.tableSeq[table] {
+		return
+	}
+
+	size := uint(len(entry)) + globalCacheEntryOverhead
+	for f, v := range value.Field {
+		size += uint(len(f) + len(v))
+	}
+	if size > c.maxBytes {
+		return
+	}
+
+	if elem, ok := c.entries[entry]; ok {
+		c.remove(elem)
+	}
+	c.entries[entry] = c.lru.PushFront(&globalCacheEntry{
+		table: table, entry: entry, value: value.Copy(), size: size})
+	c.bytes += size
+
+	for (uint(c.lru.Len()) > c.maxEntries) || (c.bytes > c.maxBytes) {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *globalCache) remove(elem *list.Element) {
+	ce := c.lru.Remove(elem).(*globalCacheEntry)
+	delete(c.entries, ce.entry)
+	c.bytes -= ce.size
+}
+
+// invalidate drops the redis key entry of the table.
+func (c *globalCache) invalidate(table, entry string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	c.tableSeq[table] = c.seq
+	if elem, ok := c.entries[entry]; ok {
+		c.remove(elem)
+		c.stats.Invalidations++
+	}
+}
+
+// flush drops all the entries. Called with c.mu held.
+func (c *globalCache) flush() {
+	c.seq++
+	c.flushSeq = c.seq
+	c.tableSeq = make(map[string]uint64, InitialTablesCount)
+	c.entries = make(map[string]*list.Element, InitialTableEntryCount)
+	c.lru.Init()
+	c.bytes = 0
+	c.stats.Flushes++
+}
+
+// clear flushes the cache, and closes the subscription, which is restarted
+// with the current DBCacheConfig on the next get().
+func (c *globalCache) clear() error {
+	c.mu.Lock()
+	sdb := c.sdb
+	c.sdb = nil
+	c.tables = nil
+	c.retryAt = time.Time{}
+	c.flush()
+	c.mu.Unlock()
+
+	if sdb != nil {
+		glog.Info("globalCache: clear: closing subscription")
+		return sdb.UnsubscribeDB()
+	}
+	return nil
+}
+
+func (c *globalCache) subscribe(config *DBCacheConfig) {
+	c.mu.Lock()
+	if c.sdb != nil || c.subscribing || time.Now().Before(c.retryAt) {
+		c.mu.Unlock()
+		return
+	}
+	c.subscribing = true
+	c.mu.Unlock()
+
+	tables := make([]string, 0, len(config.GlobalCacheTables))
+	skeys := make([]*SKey, 0, len(config.GlobalCacheTables))
+	for name, ok := range config.GlobalCacheTables {
+		if ok {
+			tables = append(tables, name)
+			skeys = append(skeys, &SKey{Ts: &TableSpec{Name: name},
+				Key: &Key{Comp: []string{"*"}}, SEMap: globalCacheSEMap})
+		}
+	}
+
+	var sdb *DB
+	var e error
+	if len(skeys) != 0 {
+		sdb, e = SubscribeDB(Options{
+			DBNo:               ConfigDB,
+			InitIndicator:      "",
+			TableNameSeparator: "|",
+			KeySeparator:       "|",
+		}, skeys, c.handler)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.subscribing = false
+	if sdb == nil {
+		glog.Warningf("globalCache: SubscribeDB(%v) failed: %v", tables, e)
+		c.retryAt = time.Now().Add(globalCacheRetryInterval)
+		return
+	}
+
+	glog.Info("globalCache: subscribed: tables: ", tables)
+	c.flush()
+	c.sdb = sdb
+	c.tables = tables
+	c.maxEntries, c.maxBytes = config.globalCacheLimits()
+}
+
+// globalCacheSEMap lists the events of the global cache subscription; any
+// change invalidates, and a resync flushes.
+var globalCacheSEMap = map[SEvent]bool{
+	SEventHSet:    true,
+	SEventHDel:    true,
+	SEventDel:     true,
+	SEventOther:   true,
+	SEventExpired: true,
+	SEventResync:  true,
+}
+
+// handler is the HFunc of the global cache subscription.
+func (c *globalCache) handler(d *DB, skey *SKey, key *Key, event SEvent) error {
+	switch event {
+	case SEventClose, SEventErr:
+		c.mu.Lock()
+		if c.sdb != d {
+			c.mu.Unlock()
+			return nil
+		}
+		glog.Warning("globalCache: subscription closed: event: ", event)
+		c.sdb = nil
+		c.tables = nil
+		c.flush()
+		c.mu.Unlock()
+
+		if event == SEventErr {
+			d.UnsubscribeDB()
+		}
+	case SEventResync:
+		// Invalidations may have been lost while disconnected.
+		c.mu.Lock()
+		if c.sdb == d {
+			c.flush()
+		}
+		c.mu.Unlock()
+	case SEventTxBegin, SEventTxEnd:
+	default:
+		c.invalidate(skey.Ts.Name, d.key2redis(skey.Ts, *key))
+	}
+	return nil
+}
+
+func (c *globalCache) getStats() *GlobalCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Subscribed = (c.sdb != nil)
+	stats.Entries = uint(c.lru.Len())
+	stats.Bytes = c.bytes
+	stats.MaxEntries = c.maxEntries
+	stats.MaxBytes = c.maxBytes
+	if lookups := stats.Hits + stats.Misses; lookups != 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(lookups)
+	}
+	return &stats
+}
+
+func (c *globalCache) clearStats() {
+	c.mu.Lock()
+	c.stats = GlobalCacheStats{}
+	c.mu.Unlock()
+}
@@ -0,0 +1,187 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestGlobalCache(maxEntries, maxBytes uint) *globalCache {
+	c := newGlobalCache()
+	c.sdb = &DB{} // Pretend subscribed
+	c.maxEntries, c.maxBytes = maxEntries, maxBytes
+	return c
+}
+
+func testGlobalCacheFill(c *globalCache, table string, entries ...string) {
+	for _, entry := range entries {
+		_, seq, _ := c.get(nil, table, entry)
+		c.put(table, entry, Value{Field: map[string]string{"f": entry}}, seq)
+	}
+}
+
+func TestGlobalCacheLRU(t *testing.T) {
+	c := newTestGlobalCache(2, defaultGlobalCacheMaxBytes)
+	testGlobalCacheFill(c, "PORT", "PORT|Ethernet0", "PORT|Ethernet4")
+
+	// Ethernet0 is now the MRU, Ethernet4 gets evicted.
+	if v, _, ok := c.get(nil, "PORT", "PORT|Ethernet0"); !ok || v.Get("f") != "PORT|Ethernet0" {
+		t.Errorf("get(Ethernet0) = %v, %v", v, ok)
+	}
+	testGlobalCacheFill(c, "PORT", "PORT|Ethernet8")
+
+	if _, _, ok := c.get(nil, "PORT", "PORT|Ethernet4"); ok {
+		t.Errorf("Ethernet4 not evicted")
+	}
+	for _, entry := range []string{"PORT|Ethernet0", "PORT|Ethernet8"} {
+		if _, _, ok := c.get(nil, "PORT", entry); !ok {
+			t.Errorf("%s evicted", entry)
+		}
+	}
+
+	stats := c.getStats()
+	if stats.Entries != 2 || stats.Evictions != 1 || stats.Hits != 3 ||
+		stats.Misses != 4 || stats.HitRatio != 3.0/7.0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestGlobalCacheMaxBytes(t *testing.T) {
+	c := newTestGlobalCache(100, 3*globalCacheEntryOverhead)
+	testGlobalCacheFill(c, "VLAN_MEMBER", "V|1", "V|2", "V|3")
+
+	if stats := c.getStats(); stats.Entries != 2 || stats.Bytes > stats.MaxBytes {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if _, _, ok := c.get(nil, "VLAN_MEMBER", "V|1"); ok {
+		t.Errorf("V|1 not evicted")
+	}
+}
+
+func TestGlobalCacheInvalidate(t *testing.T) {
+	c := newTestGlobalCache(100, defaultGlobalCacheMaxBytes)
+	testGlobalCacheFill(c, "ACL_RULE", "ACL_RULE|A|R1")
+
+	c.invalidate("ACL_RULE", "ACL_RULE|A|R1")
+	if _, _, ok := c.get(nil, "ACL_RULE", "ACL_RULE|A|R1"); ok {
+		t.Errorf("ACL_RULE|A|R1 not invalidated")
+	}
+
+	// A change to the table while the read was in flight.
+	_, seq, _ := c.get(nil, "ACL_RULE", "ACL_RULE|A|R2")
+	c.invalidate("ACL_RULE", "ACL_RULE|A|R2")
+	c.put("ACL_RULE", "ACL_RULE|A|R2", Value{Field: map[string]string{"f": "v"}}, seq)
+	if _, _, ok := c.get(nil, "ACL_RULE", "ACL_RULE|A|R2"); ok {
+		t.Errorf("Stale ACL_RULE|A|R2 cached")
+	}
+
+	// Not cached when not subscribed.
+	c.mu.Lock()
+	c.flush()
+	c.sdb = nil
+	c.retryAt = time.Now().Add(time.Hour)
+	c.mu.Unlock()
+	if _, seq, _ = c.get(&DBCacheConfig{}, "ACL_RULE", "ACL_RULE|A|R3"); seq != 0 {
+		t.Errorf("get() seq = %d when not subscribed", seq)
+	}
+}
+
+func TestGlobalCacheGetEntry(t *testing.T) {
+	ts := &TableSpec{Name: "DBGCACHE_TST_" + strconv.Itoa(os.Getpid())}
+	key := Key{Comp: []string{"KEY1"}}
+	entry := ts.Name + "|KEY1"
+
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() {
+		deleteTableAndDb(d, ts, t)
+		ClearCache()
+	})
+	d.dbCacheConfig.Global = true
+	d.dbCacheConfig.GlobalCacheTables = map[string]bool{ts.Name: true}
+
+	if e = d.SetEntry(ts, key, Value{Field: map[string]string{"f1": "v1"}}); e != nil {
+		t.Fatalf("SetEntry() fails e: %v", e)
+	}
+
+	for i := 0; i < 2; i++ {
+		if v, e := d.GetEntry(ts, key); e != nil || v.Get("f1") != "v1" {
+			t.Fatalf("GetEntry() = %v, %v", v, e)
+		}
+	}
+	if hits := d.stats.AllTables.GetEntryGlobalCacheHits; hits != 1 {
+		t.Errorf("GetEntryGlobalCacheHits = %d; expected 1", hits)
+	}
+
+	// A write from this process invalidates immediately.
+	if e = d.ModEntry(ts, key, Value{Field: map[string]string{"f1": "v2"}}); e != nil {
+		t.Fatalf("ModEntry() fails e: %v", e)
+	}
+	if v, e := d.GetEntry(ts, key); e != nil || v.Get("f1") != "v2" {
+		t.Errorf("GetEntry() after ModEntry() = %v, %v", v, e)
+	}
+
+	// So does a bulk write.
+	if e = d.SetEntries(ts, []Key{key}, []Value{{Field: map[string]string{"f1": "v2b"}}}); e != nil {
+		t.Fatalf("SetEntries() fails e: %v", e)
+	}
+	if v, e := d.GetEntry(ts, key); e != nil || v.Get("f1") != "v2b" {
+		t.Errorf("GetEntry() after SetEntries() = %v, %v", v, e)
+	}
+
+	// A write from elsewhere invalidates on the keyspace notification.
+	if e = d.client.HSet(context.Background(), entry, "f1", "v3").Err(); e != nil {
+		t.Fatalf("HSet() fails e: %v", e)
+	}
+	var v Value
+	for i := 0; i < 50; i++ {
+		if v, _ = d.GetEntry(ts, key); v.Get("f1") == "v3" {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if v.Get("f1") != "v3" {
+		t.Errorf("GetEntry() after HSet() = %v", v)
+	}
+
+	if stats, _ := GetDBStats(); stats.Databases[ConfigDB].GlobalCache == nil ||
+		!stats.Databases[ConfigDB].GlobalCache.Subscribed {
+		t.Errorf("GlobalCache stats not reported")
+	}
+
+	// The reads in a transaction bypass the global cache.
+	if e = d.StartTx(nil, nil); e != nil {
+		t.Fatalf("StartTx() fails e: %v", e)
+	}
+	hits := d.stats.AllTables.GetEntryGlobalCacheHits
+	if v, e := d.GetEntry(ts, key); e != nil || v.Get("f1") != "v3" {
+		t.Errorf("GetEntry() in Tx = %v, %v", v, e)
+	}
+	if d.stats.AllTables.GetEntryGlobalCacheHits != hits {
+		t.Errorf("GetEntry() in Tx hit the GlobalCache")
+	}
+	d.AbortTx()
+}
@@ -43,8 +43,16 @@ const (
 	lockTableKey   string = lockTable + "|" + lockKey
 	noSessionToken string = "0-0"
 
+	// A lock with a lease has the "<Lockname>|lease" field, and the lease key
+	// with a TTL. It is stale if the lease key expired, i.e. was not renewed.
+	// The lease key is a plain string, so it is kept out of the LOCK table.
+	lockLeaseTable string = "LOCK_LEASE"
+	leaseKeyPfx    string = lockLeaseTable + "|"
+
 	tryLockAttempt int           = 4
 	tryLockPause   time.Duration = 200
+
+	configDBLockTTL time.Duration = 60 * time.Second
 )
 
 var execName string
@@ -52,11 +60,13 @@ var execName string
 type lockStruct struct {
 	comm   string // Basename of the executable
 	locked bool
+	stop   chan struct{} // Stops the lease renewal
 }
 
 type LockStruct struct {
-	Name string // Lockname
-	Id   string // ID Unique to the executable (Eg: Session-Token, "0-0")
+	Name string        // Lockname
+	Id   string        // ID Unique to the executable (Eg: Session-Token, "0-0")
+	TTL  time.Duration // Lease, renewed while locked. (0: No lease)
 
 	lockStruct
 }
@@ -78,11 +88,13 @@ func (lt *LockStruct) tryLock() error {
 	}
 	defer CloseRedisClient(client)
 
-	// HSETNX: Set Hash Field if Not Exist
-	args := []interface{}{"HSETNX", lockTableKey, lt.Name,
-		lt.comm + ":" + lt.Id}
-	glog.V(3).Info("tryLock: RedisCmd: STATE_DB: ", args)
-	if reply, err = client.Do(context.Background(), args...).Result(); err == nil {
+	// Run the LUA Script to HSETNX, or to take over a stale lease.
+	keys := []string{lockTableKey, lt.leaseKey(), tableLocksKey}
+	args := []interface{}{lt.Name, lt.comm + ":" + lt.Id, lt.TTL.Milliseconds(),
+		configDBLock, time.Now().UnixMilli()}
+	glog.V(3).Info("tryLock: RedisCmd: STATE_DB: ", keys, args)
+	if reply, err = luaScriptTryLock.Run(context.Background(), client, keys,
+		args...).Result(); err == nil {
 		if intReply, ok := reply.(int64); !ok {
 			glog.Errorf("tryLock: Reply %v Not int64: %v Type: %v",
 				args, reply, reflect.TypeOf(reply))
@@ -91,7 +103,11 @@ func (lt *LockStruct) tryLock() error {
 			err = lt.dbLockedError(client)
 		} else {
 			lt.locked = true
-			glog.Infof("tryLock: Locked: %s:%s", lt.Name, lt.Id)
+			glog.Infof("tryLock: Locked: %s:%s TTL: %v", lt.Name, lt.Id, lt.TTL)
+			if lt.TTL > 0 {
+				lt.stop = make(chan struct{})
+				go lt.renewLease(lt.stop)
+			}
 		}
 	}
 
@@ -118,9 +134,14 @@ func (lt *LockStruct) unlock() error {
 	}
 	defer CloseRedisClient(client)
 
+	if lt.stop != nil {
+		close(lt.stop)
+		lt.stop = nil
+	}
+
 	// Run the LUA Script to HDEL if we set the key
 	if reply, err = luaScriptUnlock.Run(context.Background(), client,
-		[]string{lockTableKey},
+		[]string{lockTableKey, lt.leaseKey()},
 		[]string{lt.Name, lt.comm, lt.Id}).Result(); err == nil {
 
 		if intReply, ok := reply.(int64); !ok {
@@ -145,6 +166,44 @@ func (lt *LockStruct) unlock() error {
 	return err
 }
 
+// renewLease extends the lease every TTL/3, until stopped, or the lock is
+// found to be lost.
+func (lt *LockStruct) renewLease(stop chan struct{}) {
+	ticker := time.NewTicker(lt.TTL / 3)
+	defer ticker.Stop()
+
+	keys := []string{lockTableKey, lt.leaseKey()}
+	args := []interface{}{lt.Name, lt.comm + ":" + lt.Id, lt.TTL.Milliseconds()}
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		client, err := getStateDB()
+		if err != nil {
+			continue
+		}
+
+		reply, err := luaScriptRenewLease.Run(context.Background(), client,
+			keys, args...).Result()
+		CloseRedisClient(client)
+
+		if err != nil {
+			glog.Warningf("renewLease: %s:%s: %v", lt.Name, lt.Id, err)
+		} else if intReply, ok := reply.(int64); ok && intReply == 0 {
+			glog.Errorf("renewLease: %s:%s: Lock lost", lt.Name, lt.Id)
+			return
+		}
+	}
+}
+
+func (lt *LockStruct) leaseKey() string {
+	return leaseKeyPfx + lt.Name
+}
+
 func (lt *LockStruct) dbLockedError(c *redis.Client) error {
 	var lockId string
 
@@ -184,7 +243,7 @@ func ConfigDBTryLock(token string) error {
 	if cdbLock != nil {
 		err = cdbLock.dbLockedError(nil)
 	} else {
-		ls := LockStruct{Name: configDBLock, Id: token,
+		ls := LockStruct{Name: configDBLock, Id: token, TTL: configDBLockTTL,
 			lockStruct: lockStruct{comm: execName}}
 		for attempts := 0; attempts < tryLockAttempt; attempts++ {
 			if err = ls.tryLock(); err == nil {
@@ -233,6 +292,11 @@ func ConfigDBClearLock() error {
 	var err error
 	glog.Info("ConfigDBClearLock:")
 
+	if cdbLock != nil && cdbLock.stop != nil {
+		close(cdbLock.stop)
+		cdbLock.stop = nil
+	}
+
 	err = (&LockStruct{Name: configDBLock, Id: "*",
 		lockStruct: lockStruct{comm: execName, locked: true}}).unlock()
 	cdbLock = nil
@@ -278,6 +342,8 @@ func getExecName() string {
 }
 
 var luaScriptUnlock *redis.Script
+var luaScriptTryLock *redis.Script
+var luaScriptRenewLease *redis.Script
 
 func init() {
 
@@ -298,12 +364,56 @@ func init() {
 			local id = string.sub(fieldVal, colon + 1, slen)
 			if ((ARGV[2] == '*') or (ARGV[2] == comm)) and
 					((ARGV[3] == '*') or (ARGV[3] == id)) then
+				redis.call("HDEL", KEYS[1], ARGV[1] .. "|lease")
+				redis.call("DEL", KEYS[2])
 				return redis.call("HDEL", KEYS[1], ARGV[1])
 			end
 		end
 		return 0
 	`)
 
+	// Register the Lua Script to Lock. HSETNX KEYS[1] ARGV[1] ARGV[2], or
+	// take over the lock if it has a lease, and the lease KEYS[2] expired.
+	// If ARGV[3] (ttl in ms) is not 0, the lock has a lease. The CONFIG_DB
+	// lock (named ARGV[4]) also fails if another owner holds a (live, at
+	// ARGV[5] unix ms) table lock in the KEYS[3] hash.
+	luaScriptTryLock = redis.NewScript(luaTableLockLive + `
+		if redis.call("HEXISTS", KEYS[1], ARGV[1]) == 1 then
+			if (redis.call("HEXISTS", KEYS[1], ARGV[1] .. "|lease") == 0) or
+					(redis.call("EXISTS", KEYS[2]) == 1) then
+				return 0
+			end
+		end
+		if ARGV[1] == ARGV[4] then
+			local locks = redis.call("HGETALL", KEYS[3])
+			for j = 1, #locks, 2 do
+				local h = string.match(locks[j], "^([^|]*)|")
+				if (h ~= ARGV[2]) and live(locks[j + 1], tonumber(ARGV[5])) then
+					return 0
+				end
+			end
+		end
+		redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+		if tonumber(ARGV[3]) > 0 then
+			redis.call("HSET", KEYS[1], ARGV[1] .. "|lease", ARGV[3])
+			redis.call("SET", KEYS[2], ARGV[2], "PX", ARGV[3])
+		else
+			redis.call("HDEL", KEYS[1], ARGV[1] .. "|lease")
+			redis.call("DEL", KEYS[2])
+		end
+		return 1
+	`)
+
+	// Register the Lua Script to renew the lease KEYS[2] for ARGV[3] ms, if
+	// HGET KEYS[1] ARGV[1] == ARGV[2] i.e. we still hold the lock.
+	luaScriptRenewLease = redis.NewScript(`
+		if redis.call("HGET", KEYS[1], ARGV[1]) == ARGV[2] then
+			redis.call("SET", KEYS[2], ARGV[2], "PX", ARGV[3])
+			return 1
+		end
+		return 0
+	`)
+
 	// Clears the ConfigDB Lock, (if the current executable placed it, i.e.
 	// RESTCONF/rest-server clears it's lock, and gNMI/telemetry clears
 	// it's lock).
@@ -23,6 +23,7 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
 )
@@ -360,3 +361,55 @@ func TestLockConfigDBDeleteEntryFields(t *testing.T) {
 			tlerr.TranslibDBLock{}, err)
 	}
 }
+
+// TestLockLease: A lock with an expired lease is taken over, while a lock with
+// a live lease, or without a lease is honored.
+func TestLockLease(t *testing.T) {
+	var err error
+
+	ls := &LockStruct{Name: configDBLock, Id: testSTok, TTL: time.Second,
+		lockStruct: lockStruct{comm: execName}}
+	other := &LockStruct{Name: configDBLock, Id: testSTok + "0",
+		TTL: time.Second, lockStruct: lockStruct{comm: execName}}
+
+	// Clean it up.
+	if err = stateDB.DeleteEntry(fTs, fKey); err != nil {
+		t.Errorf("DeleteEntry: Expecting nil: Received %v", err)
+	}
+	t.Cleanup(func() { stateDB.DeleteEntry(fTs, fKey); cdbLock = nil })
+
+	// Lock it, with a lease
+	if err = ls.tryLock(); err != nil {
+		t.Fatalf("tryLock: Expecting nil: Received %v", err)
+	}
+
+	// The lease key is outside the LOCK table, so the table is readable.
+	if _, err = stateDB.GetTable(fTs); err != nil {
+		t.Errorf("GetTable(LOCK): Expecting nil: Received %v", err)
+	}
+
+	// Lease is renewed, so the lock is held beyond the TTL.
+	time.Sleep(2 * time.Second)
+	if err = other.tryLock(); err == nil {
+		t.Errorf("tryLock: Lock with a live lease taken over")
+	}
+
+	// Simulate a crash: Stop the renewal, and let the lease expire.
+	close(ls.stop)
+	ls.stop = nil
+	ls.locked = false
+	time.Sleep(2 * time.Second)
+	if err = other.tryLock(); err != nil {
+		t.Errorf("tryLock: Stale lease: Expecting nil: Received %v", err)
+	} else if err = other.unlock(); err != nil {
+		t.Errorf("unlock: Expecting nil: Received %v", err)
+	}
+
+	// Lock without a lease is never stale.
+	fVal := Value{Field: map[string]string{
+		configDBLock: execName + ":" + testSTok + "0"}}
+	setupKey(t, fTs, fKey, fVal)
+	if err = ls.tryLock(); err == nil {
+		t.Errorf("tryLock: Lock without a lease taken over")
+	}
+}
@@ -107,6 +107,23 @@ func (d *DB) OnChangeCacheDelete(ts *TableSpec, key Key) (Value, error) {
 	return valueOrig, nil
 }
 
+// OnChangeCacheKeys returns the keys of the on_change cache entries of the
+// table, which match the pattern.
+func (d *DB) OnChangeCacheKeys(ts *TableSpec, pattern Key) []Key {
+	table, ok := d.cache.Tables[ts.Name]
+	if !ok || !d.Opts.IsOnChangeEnabled {
+		return nil
+	}
+
+	keys := make([]Key, 0, len(table.entry))
+	for redisKey := range table.entry {
+		if key := d.redis2key(ts, redisKey); key.Matches(pattern) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 //  Internal Functions                                                        //
 ////////////////////////////////////////////////////////////////////////////////
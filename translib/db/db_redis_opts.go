@@ -29,6 +29,7 @@ import (
 	"sync"
 	"time"
 
+	cmn "github.com/Azure/sonic-mgmt-common/cvl/common"
 	"github.com/golang/glog"
 	"github.com/redis/go-redis/v9"
 )
@@ -47,6 +48,7 @@ import (
 
 type _DBRedisOptsConfig struct {
 	opts redis.Options
+	tls  cmn.RedisTLSConfig // Overrides the database_config.json TLS settings
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -82,25 +84,31 @@ func adjustRedisOpts(dbOpt *Options) *redis.Options {
 	dbRedisOptsConfig.reconfigure()
 	mutexRedisOptsConfig.Lock()
 	redisOpts := dbRedisOptsConfig.opts
+	tlsOverride := dbRedisOptsConfig.tls
 	mutexRedisOptsConfig.Unlock()
 
+	var tlsConfig cmn.RedisTLSConfig
+	var dbUsername string
+
 	var dbSock string
 	var dbNetwork string
 	addr := DefaultRedisLocalTCPEP
 	dbId := int(dbOpt.DBNo)
 	dbPassword := ""
 	if dbInstName := getDBInstName(dbOpt.DBNo); dbInstName != "" {
-		if isDbInstPresent(dbInstName) {
-			if dbSock = getDbSock(dbInstName); dbSock != "" {
+		if isDbInstPresent(dbOpt.Namespace, dbInstName) {
+			if dbSock = getDbSock(dbOpt.Namespace, dbInstName); dbSock != "" {
 				dbNetwork = DefaultRedisUNIXNetwork
 				addr = dbSock
 			} else {
 				dbNetwork = DefaultRedisTCPNetwork
-				addr = getDbTcpAddr(dbInstName)
+				addr = getDbTcpAddr(dbOpt.Namespace, dbInstName)
 			}
-			dbId = getDbId(dbInstName)
-			dbSepStr := getDbSeparator(dbInstName)
-			dbPassword = getDbPassword(dbInstName)
+			dbId = getDbId(dbOpt.Namespace, dbInstName)
+			dbSepStr := getDbSeparator(dbOpt.Namespace, dbInstName)
+			dbPassword = getDbPassword(dbOpt.Namespace, dbInstName)
+			dbUsername = getDbUsername(dbOpt.Namespace, dbInstName)
+			tlsConfig = getDbTLSConfig(dbOpt.Namespace, dbInstName)
 			if len(dbSepStr) > 0 {
 				if len(dbOpt.TableNameSeparator) > 0 &&
 					dbOpt.TableNameSeparator != dbSepStr {
@@ -128,6 +136,17 @@ func adjustRedisOpts(dbOpt *Options) *redis.Options {
 	redisOpts.Password = dbPassword
 	redisOpts.DB = dbId
 
+	// Redis 6 ACL user. The go_redis_opts Username, if any, has priority.
+	if redisOpts.Username == "" {
+		redisOpts.Username = dbUsername
+	}
+
+	// TLS only applies to TCP. (Unix sockets are local to the host.)
+	if tlsConfig = tlsConfig.Merge(tlsOverride); tlsConfig.Enabled &&
+		dbNetwork != DefaultRedisUNIXNetwork {
+		applyTLS(tlsConfig, &redisOpts)
+	}
+
 	// redisOpts.DialTimeout = 0 // Default
 
 	// Default 3secs read & write timeout was not sufficient in high CPU load
@@ -183,6 +202,11 @@ func (config *_DBRedisOptsConfig) reconfigure() error {
 		}
 		mutexRedisOptsConfig.Unlock()
 	}
+
+	// Share the TLS override with the CVL redis client.
+	mutexRedisOptsConfig.Lock()
+	cmn.SetRedisTLSOverride(dbRedisOptsConfig.tls)
+	mutexRedisOptsConfig.Unlock()
 	return nil
 }
 
@@ -190,6 +214,7 @@ func (config *_DBRedisOptsConfig) handleReconfigureSignal() error {
 	mutexRedisOptsConfig.Lock()
 	reconfigureRedisOptsConfig = true
 	mutexRedisOptsConfig.Unlock()
+	cmn.ClearRedisTLSConfigCache()
 	return nil
 }
 
@@ -220,6 +245,7 @@ func (config *_DBRedisOptsConfig) parseRedisOptsConfig(optsString string) error
 	// First zero the config redis.Options, in case there is any existing
 	// stale configuration.
 	config.opts = redis.Options{}
+	config.tls = cmn.RedisTLSConfig{}
 
 	// This could be optimized using reflection, if the # of options grows
 	for optI, optS := range strings.Split(optsString, ",") {
@@ -284,6 +310,25 @@ func (config *_DBRedisOptsConfig) parseRedisOptsConfig(optsString string) error
 					time.ParseDuration(optSA[1]); optSAErr != nil {
 					eS += ("Parse Error: " + optSA[0] + " :" + optSAErr.Error())
 				}
+			case "Username":
+				config.opts.Username = optSA[1]
+			case "TLS", "TLSInsecureSkipVerify":
+				var boolVal bool
+				if boolVal, optSAErr = strconv.ParseBool(optSA[1]); optSAErr != nil {
+					eS += ("Parse Error: " + optSA[0] + " :" + optSAErr.Error())
+				} else if optSA[0] == "TLS" {
+					config.tls.Enabled = boolVal
+				} else {
+					config.tls.InsecureSkipVerify = boolVal
+				}
+			case "TLSCACert":
+				config.tls.CACertPath = optSA[1]
+			case "TLSCert":
+				config.tls.CertPath = optSA[1]
+			case "TLSKey":
+				config.tls.KeyPath = optSA[1]
+			case "TLSServerName":
+				config.tls.ServerName = optSA[1]
 			default:
 				eS += ("Unknown Redis Option: " + optSA[0] + " ")
 			}
@@ -0,0 +1,49 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"net"
+
+	cmn "github.com/Azure/sonic-mgmt-common/cvl/common"
+	"github.com/golang/glog"
+	"github.com/redis/go-redis/v9"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// applyTLS sets the TLSConfig of the config in the redisOpts. The loading,
+// and caching of the certificates is shared with CVL (cmn.RedisTLSConfig).
+// If the TLS configuration cannot be loaded, the connections fail (rather
+// than fall back to plain text) with the error.
+func applyTLS(config cmn.RedisTLSConfig, redisOpts *redis.Options) {
+	tlsConfig, e := config.TLSConfig(redisOpts.Addr)
+	if e != nil {
+		glog.Errorf("applyTLS: %s: %v", redisOpts.Addr, e)
+		redisOpts.Dialer = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, e
+		}
+		return
+	}
+	redisOpts.TLSConfig = tlsConfig
+}
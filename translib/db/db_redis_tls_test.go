@@ -0,0 +1,131 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cmn "github.com/Azure/sonic-mgmt-common/cvl/common"
+	"github.com/redis/go-redis/v9"
+)
+
+// writeTestCert writes a self signed certificate, and its key, in dir.
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	key, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if e != nil {
+		t.Fatalf("GenerateKey() fails e: %v", e)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, e := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if e != nil {
+		t.Fatalf("CreateCertificate() fails e: %v", e)
+	}
+	keyDer, e := x509.MarshalECPrivateKey(key)
+	if e != nil {
+		t.Fatalf("MarshalECPrivateKey() fails e: %v", e)
+	}
+
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return certPath, keyPath
+}
+
+func TestSetGoRedisOptsTLS(t *testing.T) {
+	t.Cleanup(func() {
+		dbRedisOptsConfig.parseRedisOptsConfig("")
+		cmn.SetRedisTLSOverride(cmn.RedisTLSConfig{})
+	})
+
+	setGoRedisOpts("Username=mgmt,TLS=true,TLSCACert=/etc/ca.pem,TLSServerName=redis")
+	if dbRedisOptsConfig.opts.Username != "mgmt" {
+		t.Errorf("Username = %q", dbRedisOptsConfig.opts.Username)
+	}
+	expTLS := cmn.RedisTLSConfig{Enabled: true, CACertPath: "/etc/ca.pem", ServerName: "redis"}
+	if dbRedisOptsConfig.tls != expTLS {
+		t.Errorf("tls = %+v; expected %+v", dbRedisOptsConfig.tls, expTLS)
+	}
+
+	// The override is shared with the CVL redis client.
+	dbRedisOptsConfig.reconfigure()
+	if override := cmn.RedisTLSOverride(); override != expTLS {
+		t.Errorf("RedisTLSOverride() = %+v; expected %+v", override, expTLS)
+	}
+
+	if e := dbRedisOptsConfig.parseRedisOptsConfig("TLS=maybe"); e == nil {
+		t.Errorf("parseRedisOptsConfig(TLS=maybe) succeeds")
+	}
+}
+
+func TestDBTLSConfig(t *testing.T) {
+	t.Cleanup(cmn.ClearRedisTLSConfigCache)
+	certPath, keyPath := writeTestCert(t, t.TempDir())
+
+	config := cmn.RedisTLSConfig{Enabled: true, CACertPath: certPath,
+		CertPath: certPath, KeyPath: keyPath}
+	tlsConfig, e := config.TLSConfig("redis.local:6380")
+	if e != nil {
+		t.Fatalf("TLSConfig() fails e: %v", e)
+	}
+	if tlsConfig.ServerName != "redis.local" || tlsConfig.RootCAs == nil ||
+		len(tlsConfig.Certificates) != 1 {
+		t.Errorf("Unexpected tls.Config: %+v", tlsConfig)
+	}
+	if again, _ := config.TLSConfig("redis.local:6380"); again != tlsConfig {
+		t.Errorf("tls.Config not cached")
+	}
+
+	// Override
+	config = config.Merge(cmn.RedisTLSConfig{ServerName: "other", InsecureSkipVerify: true})
+	if config.ServerName != "other" || !config.InsecureSkipVerify ||
+		config.CACertPath != certPath {
+		t.Errorf("Merge() = %+v", config)
+	}
+
+	// Missing CA: the connections must fail, not fall back to plain text.
+	config = cmn.RedisTLSConfig{Enabled: true, CACertPath: filepath.Join(t.TempDir(), "none.pem")}
+	redisOpts := redis.Options{Addr: "127.0.0.1:6380"}
+	applyTLS(config, &redisOpts)
+	if redisOpts.TLSConfig != nil || redisOpts.Dialer == nil {
+		t.Fatalf("applyTLS() with missing CA: %+v", redisOpts)
+	}
+	if _, e = redisOpts.Dialer(context.Background(), "tcp", redisOpts.Addr); e == nil {
+		t.Errorf("Dialer() succeeds with missing CA")
+	}
+}
@@ -0,0 +1,434 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+// Typed RPC over redis Pub/Sub
+// The requests, and responses are JSON envelopes, published on the server's
+// request channel, and the client's reply channel respectively. Responses
+// are matched to the calls by the correlation ID.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// RpcRequest is the envelope of a request.
+type RpcRequest struct {
+	Id       string          `json:"id"`                 // Correlation ID
+	Method   string          `json:"method"`             // Handler name
+	ReplyTo  string          `json:"reply_to"`           // Response channel
+	Deadline int64           `json:"deadline,omitempty"` // Unix ms (0: None)
+	Params   json.RawMessage `json:"params,omitempty"`
+}
+
+// RpcResponse is the envelope of a response. One of Result, or Error is set.
+type RpcResponse struct {
+	Id     string          `json:"id"` // Correlation ID of the RpcRequest
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *RpcError       `json:"error,omitempty"`
+}
+
+// RpcErrorCode identifies the tlerr type of an RpcError.
+type RpcErrorCode string
+
+const (
+	RpcErrInternal      RpcErrorCode = "internal"       // tlerr.InternalError
+	RpcErrInvalidArgs   RpcErrorCode = "invalid_args"   // tlerr.InvalidArgsError
+	RpcErrNotFound      RpcErrorCode = "not_found"      // tlerr.NotFoundError
+	RpcErrAlreadyExists RpcErrorCode = "already_exists" // tlerr.AlreadyExistsError
+	RpcErrNotSupported  RpcErrorCode = "not_supported"  // tlerr.NotSupportedError
+	RpcErrUnauthorized  RpcErrorCode = "unauthorized"   // tlerr.AuthorizationError
+	RpcErrTimeout       RpcErrorCode = "timeout"        // tlerr.TranslibTimeoutError
+	RpcErrBusy          RpcErrorCode = "busy"           // tlerr.TranslibBusy
+	RpcErrLocked        RpcErrorCode = "locked"         // tlerr.TranslibDBLock
+	RpcErrNoMethod      RpcErrorCode = "no_method"      // tlerr.NotSupportedError
+)
+
+// RpcError is the error returned by the server's RpcHandler.
+type RpcError struct {
+	Code    RpcErrorCode `json:"code"`
+	Message string       `json:"message"`
+	Path    string       `json:"path,omitempty"`
+	AppTag  string       `json:"app_tag,omitempty"`
+}
+
+// RpcHandler handles the requests for a method. The params are the JSON
+// encoded RpcRequest.Params. The result is JSON encoded in the RpcResponse.
+// The ctx is cancelled at the deadline of the request.
+type RpcHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// RpcClient makes the typed RPC calls. It is safe for concurrent use.
+type RpcClient struct {
+	d       *DB    // PubSubRpcDB subscribed to the replyTo channel
+	replyTo string // Response channel, unique to the client
+
+	pending map[string]chan *RpcResponse // Correlation ID to the call
+	mu      sync.Mutex
+	seq     uint64
+}
+
+// RpcServer dispatches the requests on a channel to the RpcHandlers.
+type RpcServer struct {
+	d       *DB // PubSubRpcDB subscribed to the request channel
+	channel string
+
+	handlers map[string]RpcHandler
+	mu       sync.RWMutex
+}
+
+// RpcDefaultTimeout is the deadline of the calls whose ctx has no deadline.
+var RpcDefaultTimeout = 30 * time.Second
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// NewRpcClient opens an RpcClient on the DB of the opt (Eg: LogLevelDB).
+func NewRpcClient(opt Options) (*RpcClient, error) {
+	replyTo := fmt.Sprintf("RPC_REPLY_%s_%d_%d", execName, os.Getpid(),
+		atomic.AddUint64(&rpcClientSeq, 1))
+
+	d, err := PubSubRpcDB(opt, replyTo)
+	if err != nil {
+		glog.Errorf("NewRpcClient: %s: %v", replyTo, err)
+		return nil, err
+	}
+
+	c := &RpcClient{d: d, replyTo: replyTo,
+		pending: make(map[string]chan *RpcResponse)}
+	go c.receive()
+
+	glog.V(3).Infof("NewRpcClient: %s", replyTo)
+	return c, nil
+}
+
+// Call invokes the method on the RpcServer of the channel, with the (JSON
+// encoded) params, and decodes the response into the result (if not nil).
+// The call fails with tlerr.TranslibTimeoutError at the ctx deadline (or
+// RpcDefaultTimeout), and with the tlerr type of the handler's RpcError.
+func (c *RpcClient) Call(ctx context.Context, channel, method string,
+	params, result interface{}) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, RpcDefaultTimeout)
+		defer cancel()
+	}
+	deadline, _ := ctx.Deadline()
+
+	req := RpcRequest{
+		Id:       c.replyTo + ":" + strconv.FormatUint(atomic.AddUint64(&c.seq, 1), 10),
+		Method:   method,
+		ReplyTo:  c.replyTo,
+		Deadline: deadline.UnixMilli(),
+	}
+	if params != nil {
+		var err error
+		if req.Params, err = json.Marshal(params); err != nil {
+			return tlerr.InvalidArgs("RPC %s: params: %v", method, err)
+		}
+	}
+	msg, err := json.Marshal(&req)
+	if err != nil {
+		return tlerr.InvalidArgs("RPC %s: %v", method, err)
+	}
+
+	respCh := make(chan *RpcResponse, 1)
+	c.mu.Lock()
+	if c.pending == nil {
+		c.mu.Unlock()
+		return tlerr.TranslibDBConnectionReset{}
+	}
+	c.pending[req.Id] = respCh
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, req.Id)
+		c.mu.Unlock()
+	}()
+
+	glog.V(3).Infof("RpcClient.Call: %s: %s", channel, msg)
+	listeners, err := c.d.SendRpcRequest(channel, string(msg))
+	if err != nil {
+		glog.Errorf("RpcClient.Call: %s: %s: %v", channel, method, err)
+		return err
+	}
+	if listeners == 0 {
+		glog.Warningf("RpcClient.Call: %s: %s: No RPC server", channel, method)
+		return tlerr.NotSupported("No RPC server on %s", channel)
+	}
+
+	select {
+	case resp := <-respCh:
+		if resp.Error != nil {
+			glog.V(2).Infof("RpcClient.Call: %s: %s: %v", channel, method,
+				resp.Error)
+			return resp.Error.ToError()
+		}
+		if result != nil && len(resp.Result) != 0 {
+			if err = json.Unmarshal(resp.Result, result); err != nil {
+				glog.Errorf("RpcClient.Call: %s: %s: result: %v", channel,
+					method, err)
+				return tlerr.New("RPC %s: result: %v", method, err)
+			}
+		}
+		return nil
+
+	case <-ctx.Done():
+		glog.Warningf("RpcClient.Call: %s: %s: %s: %v", channel, method,
+			req.Id, ctx.Err())
+		if ctx.Err() == context.Canceled {
+			return tlerr.RequestContextCancelled("RPC "+method, ctx.Err())
+		}
+		return tlerr.TranslibTimeoutError{}
+	}
+}
+
+// Close closes the RpcClient. The pending calls time out.
+func (c *RpcClient) Close() error {
+	c.mu.Lock()
+	c.pending = nil
+	c.mu.Unlock()
+	return c.d.ClosePubSubRpcDB()
+}
+
+// NewRpcServer opens an RpcServer serving the requests on the channel, of the
+// DB of the opt (Eg: LogLevelDB). Requests of methods which have no
+// RpcHandler (see Handle()) fail with RpcErrNoMethod.
+func NewRpcServer(opt Options, channel string) (*RpcServer, error) {
+	d, err := PubSubRpcDB(opt, channel)
+	if err != nil {
+		glog.Errorf("NewRpcServer: %s: %v", channel, err)
+		return nil, err
+	}
+
+	s := &RpcServer{d: d, channel: channel,
+		handlers: make(map[string]RpcHandler)}
+	go s.serve()
+
+	glog.V(3).Infof("NewRpcServer: %s", channel)
+	return s, nil
+}
+
+// Handle registers (or, if h is nil, removes) the RpcHandler of the method.
+func (s *RpcServer) Handle(method string, h RpcHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h == nil {
+		delete(s.handlers, method)
+	} else {
+		s.handlers[method] = h
+	}
+}
+
+// Close stops the RpcServer.
+func (s *RpcServer) Close() error {
+	return s.d.ClosePubSubRpcDB()
+}
+
+// NewRpcError returns the RpcError of the err, with the RpcErrorCode of its
+// tlerr type.
+func NewRpcError(err error) *RpcError {
+	if err == nil {
+		return nil
+	}
+
+	rpcErr := &RpcError{Code: RpcErrInternal, Message: err.Error()}
+	switch e := err.(type) {
+	case *RpcError:
+		return e
+	case tlerr.InvalidArgsError:
+		rpcErr.Code, rpcErr.Path, rpcErr.AppTag = RpcErrInvalidArgs, e.Path, e.AppTag
+	case tlerr.NotFoundError:
+		rpcErr.Code, rpcErr.Path, rpcErr.AppTag = RpcErrNotFound, e.Path, e.AppTag
+	case tlerr.AlreadyExistsError:
+		rpcErr.Code, rpcErr.Path, rpcErr.AppTag = RpcErrAlreadyExists, e.Path, e.AppTag
+	case tlerr.NotSupportedError:
+		rpcErr.Code, rpcErr.Path, rpcErr.AppTag = RpcErrNotSupported, e.Path, e.AppTag
+	case tlerr.AuthorizationError:
+		rpcErr.Code, rpcErr.Path, rpcErr.AppTag = RpcErrUnauthorized, e.Path, e.AppTag
+	case tlerr.InternalError:
+		rpcErr.Path, rpcErr.AppTag = e.Path, e.AppTag
+	case tlerr.TranslibTimeoutError:
+		rpcErr.Code = RpcErrTimeout
+	case tlerr.TranslibBusy:
+		rpcErr.Code = RpcErrBusy
+	case tlerr.TranslibDBLock:
+		rpcErr.Code = RpcErrLocked
+	case tlerr.TranslibRedisClientEntryNotExist:
+		rpcErr.Code = RpcErrNotFound
+	}
+	return rpcErr
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("RPC Error: %s: %s", e.Code, e.Message)
+}
+
+// ToError returns the tlerr type error of the RpcErrorCode.
+func (e *RpcError) ToError() error {
+	switch e.Code {
+	case RpcErrInvalidArgs:
+		return tlerr.InvalidArgsErr(e.AppTag, e.Path, "%s", e.Message)
+	case RpcErrNotFound:
+		return tlerr.NotFoundErr(e.AppTag, e.Path, "%s", e.Message)
+	case RpcErrAlreadyExists:
+		return tlerr.AlreadyExistsErr(e.AppTag, e.Path, "%s", e.Message)
+	case RpcErrNotSupported, RpcErrNoMethod:
+		return tlerr.NotSupportedErr(e.AppTag, e.Path, "%s", e.Message)
+	case RpcErrUnauthorized:
+		return tlerr.AuthorizationError{Format: "%s", Args: []interface{}{e.Message},
+			Path: e.Path, AppTag: e.AppTag}
+	case RpcErrTimeout:
+		return tlerr.TranslibTimeoutError{}
+	case RpcErrBusy:
+		return tlerr.TranslibBusy{}
+	case RpcErrLocked:
+		return tlerr.TranslibDBLock{}
+	}
+	return tlerr.NewError(e.AppTag, e.Path, "%s", e.Message)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+var rpcClientSeq uint64
+
+// receive dispatches the responses to the pending calls, till closed.
+func (c *RpcClient) receive() {
+	for msg := range c.d.rPubSub.Channel() {
+		var resp RpcResponse
+		if err := json.Unmarshal([]byte(msg.Payload), &resp); err != nil {
+			glog.Warningf("RpcClient.receive: %s: Bad response %q: %v",
+				c.replyTo, msg.Payload, err)
+			continue
+		}
+
+		c.mu.Lock()
+		respCh, ok := c.pending[resp.Id]
+		c.mu.Unlock()
+
+		if !ok {
+			// The call has timed out.
+			glog.Warningf("RpcClient.receive: %s: Late response: %s",
+				c.replyTo, resp.Id)
+			continue
+		}
+		select {
+		case respCh <- &resp:
+		default:
+			glog.Warningf("RpcClient.receive: %s: Duplicate response: %s",
+				c.replyTo, resp.Id)
+		}
+	}
+	glog.V(3).Infof("RpcClient.receive: %s: Closed", c.replyTo)
+}
+
+// serve dispatches the requests to the RpcHandlers, till closed.
+func (s *RpcServer) serve() {
+	for msg := range s.d.rPubSub.Channel() {
+		var req RpcRequest
+		if err := json.Unmarshal([]byte(msg.Payload), &req); err != nil ||
+			len(req.Id) == 0 || len(req.ReplyTo) == 0 {
+			glog.Warningf("RpcServer.serve: %s: Bad request %q: %v",
+				s.channel, msg.Payload, err)
+			continue
+		}
+		go s.handle(&req)
+	}
+	glog.V(3).Infof("RpcServer.serve: %s: Closed", s.channel)
+}
+
+// handle runs the RpcHandler of the req, and publishes the response.
+func (s *RpcServer) handle(req *RpcRequest) {
+	ctx := context.Background()
+	if req.Deadline != 0 {
+		deadline := time.UnixMilli(req.Deadline)
+		if time.Now().After(deadline) {
+			glog.Warningf("RpcServer.handle: %s: %s: %s: Deadline expired",
+				s.channel, req.Method, req.Id)
+			return
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	resp := RpcResponse{Id: req.Id}
+
+	s.mu.RLock()
+	h, ok := s.handlers[req.Method]
+	s.mu.RUnlock()
+
+	if !ok {
+		glog.Warningf("RpcServer.handle: %s: %s: No handler", s.channel,
+			req.Method)
+		resp.Error = &RpcError{Code: RpcErrNoMethod,
+			Message: "Unknown RPC method " + req.Method}
+	} else if result, err := s.invoke(ctx, h, req); err != nil {
+		resp.Error = NewRpcError(err)
+	} else if resp.Result, err = json.Marshal(result); err != nil {
+		glog.Errorf("RpcServer.handle: %s: %s: result: %v", s.channel,
+			req.Method, err)
+		resp.Error = NewRpcError(err)
+	}
+
+	msg, err := json.Marshal(&resp)
+	if err != nil {
+		glog.Errorf("RpcServer.handle: %s: %s: %v", s.channel, req.Method, err)
+		return
+	}
+	glog.V(3).Infof("RpcServer.handle: %s: %s", req.ReplyTo, msg)
+	if _, err = s.d.SendRpcRequest(req.ReplyTo, string(msg)); err != nil {
+		glog.Errorf("RpcServer.handle: %s: %s: %v", req.ReplyTo, req.Id, err)
+	}
+}
+
+// invoke runs the RpcHandler, recovering from its panic.
+func (s *RpcServer) invoke(ctx context.Context, h RpcHandler,
+	req *RpcRequest) (result interface{}, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			glog.Errorf("RpcServer.invoke: %s: %s: panic: %v", s.channel,
+				req.Method, r)
+			err = tlerr.New("RPC %s: Internal error", req.Method)
+		}
+	}()
+	return h(ctx, req.Params)
+}
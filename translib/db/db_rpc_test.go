@@ -0,0 +1,133 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+)
+
+type rpcTestParams struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func newRpcTestServer(t *testing.T) (*RpcServer, *RpcClient, string) {
+	channel := "RPC_TEST_SERVER_" + strconv.Itoa(os.Getpid())
+	s, err := NewRpcServer(Options{DBNo: LogLevelDB, ForceNewRedisConnection: true}, channel)
+	if err != nil {
+		t.Fatalf("NewRpcServer() fails: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	c, err := NewRpcClient(Options{DBNo: LogLevelDB, ForceNewRedisConnection: true})
+	if err != nil {
+		t.Fatalf("NewRpcClient() fails: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	s.Handle("echo", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var p rpcTestParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, tlerr.InvalidArgs("bad params: %v", err)
+		}
+		p.Count++
+		return &p, nil
+	})
+	s.Handle("missing", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return nil, tlerr.NotFoundErr("tag", "/a/b", "No such %s", "thing")
+	})
+	s.Handle("slow", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	s.Handle("panic", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		panic("oops")
+	})
+
+	return s, c, channel
+}
+
+func TestRpcCall(t *testing.T) {
+	_, c, channel := newRpcTestServer(t)
+
+	var result rpcTestParams
+	err := c.Call(context.Background(), channel, "echo",
+		&rpcTestParams{Name: "x", Count: 1}, &result)
+	if err != nil {
+		t.Fatalf("Call(echo) fails: %v", err)
+	}
+	if result.Name != "x" || result.Count != 2 {
+		t.Errorf("Call(echo) result %+v", result)
+	}
+}
+
+func TestRpcCallErrors(t *testing.T) {
+	_, c, channel := newRpcTestServer(t)
+	ctx := context.Background()
+
+	err := c.Call(ctx, channel, "missing", nil, nil)
+	if e, ok := err.(tlerr.NotFoundError); !ok || e.Path != "/a/b" ||
+		e.AppTag != "tag" || e.Error() != "No such thing" {
+		t.Errorf("Call(missing) err %#v", err)
+	}
+
+	err = c.Call(ctx, channel, "echo", "not an object", nil)
+	if _, ok := err.(tlerr.InvalidArgsError); !ok {
+		t.Errorf("Call(echo) err %#v; expecting InvalidArgsError", err)
+	}
+
+	err = c.Call(ctx, channel, "nosuchmethod", nil, nil)
+	if _, ok := err.(tlerr.NotSupportedError); !ok {
+		t.Errorf("Call(nosuchmethod) err %#v; expecting NotSupportedError", err)
+	}
+
+	err = c.Call(ctx, channel, "panic", nil, nil)
+	if _, ok := err.(tlerr.InternalError); !ok {
+		t.Errorf("Call(panic) err %#v; expecting InternalError", err)
+	}
+
+	err = c.Call(ctx, channel+"_NONE", "echo", nil, nil)
+	if _, ok := err.(tlerr.NotSupportedError); !ok {
+		t.Errorf("Call(no server) err %#v; expecting NotSupportedError", err)
+	}
+}
+
+func TestRpcCallDeadline(t *testing.T) {
+	_, c, channel := newRpcTestServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Call(ctx, channel, "slow", nil, nil)
+	if _, ok := err.(tlerr.TranslibTimeoutError); !ok {
+		t.Errorf("Call(slow) err %#v; expecting TranslibTimeoutError", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Call(slow) took %v", d)
+	}
+}
@@ -33,12 +33,15 @@ import (
 	"github.com/golang/glog"
 )
 
-// SavePoint type currently assumes it is singleton (only one object is
-// instantiated). However, it could be extended to be a stack of SavePoint
-// objects.
+// _savePoint is a point in the Config Session transaction, to which it can be
+// rolled back. SavePoints nest; they are kept in a stack (DB.savePoints), the
+// innermost being last.
 // Note: Any change to the underlying datastructures it is trying to save,
 // can result in a change being required to savePoint as well.
 type _savePoint struct {
+	// Name of the SavePoint. (Empty for the DeclareSP() SavePoints)
+	name string
+
 	// CAS Transaction Operations (txCmds)
 	txCmdsLen int
 
@@ -58,11 +61,12 @@ type _savePoint struct {
 	// (even though it may have been zero valued Value to indicate deletion),
 	// and the field to indicate if it was present (or absent) from
 	// the cache.
+	// Every SavePoint in the stack records the entries changed after it was
+	// declared, so that each can be rolled back to independently.
 	txTsOrigEntryMap map[string]map[string]origEntry
 
-	// Record the CVL Hints (with the length of cECDLen at the time they were
-	// invoked)
-	cHints map[int]map[string]interface{}
+	// CVL Hints (DB.spHints) recorded before the SavePoint was declared
+	cHintsLen int
 }
 
 type origEntry struct {
@@ -70,76 +74,178 @@ type origEntry struct {
 	absent bool
 }
 
-var savePoint *_savePoint
+// spHint is a CVL Hint stored while a SavePoint is declared, recorded with
+// the length of cvlEditConfigData at the time it was stored.
+type spHint struct {
+	cECDLen int
+	key     string
+	value   interface{}
+}
 
+// HasSP returns true if a SavePoint is declared.
 func (d *DB) HasSP() bool {
 	if d == nil || !d.Opts.IsSession {
 		return false
 	}
-	return savePoint != nil // TODO keep savePoint in DB struct
+	return len(d.savePoints) != 0
 }
 
+// SPNames returns the names of the declared SavePoints, outermost first.
+func (d *DB) SPNames() []string {
+	if d == nil || !d.Opts.IsSession {
+		return nil
+	}
+	names := make([]string, 0, len(d.savePoints))
+	for _, sp := range d.savePoints {
+		names = append(names, sp.name)
+	}
+	return names
+}
+
+// DeclareSP declares an (unnamed) SavePoint, nested within the SavePoints
+// already declared.
 func (d *DB) DeclareSP() error {
-	glog.Infof("DeclareSP: Begin")
+	return d.DeclareNamedSP("")
+}
+
+// DeclareNamedSP declares a SavePoint with the name, nested within the
+// SavePoints already declared. The name need not be unique; ReleaseNamedSP(),
+// and Rollback2NamedSP() use the innermost SavePoint with the name.
+func (d *DB) DeclareNamedSP(name string) error {
+	glog.Infof("DeclareSP: Begin: %q", name)
 
 	if (d == nil) || !d.Opts.IsSession {
 		glog.Error("DeclareSP: Invalid Session")
 		return tlerr.TranslibInvalidSession{}
 	}
 
-	if savePoint != nil {
-		glog.Error("DeclareSP: Only one SavePoint Supported")
-		return tlerr.TranslibDBNotSupported{}
-	}
-
-	savePoint = &_savePoint{txCmdsLen: len(d.txCmds), // Record CAS Tx Ops
+	sp := &_savePoint{name: name,
+		txCmdsLen:        len(d.txCmds),            // Record CAS Tx Ops
 		cECDLen:          len(d.cvlEditConfigData), // Record CVL Edit Ops
 		txTsOrigEntryMap: make(map[string]map[string]origEntry),
+		cHintsLen:        len(d.spHints),
 	}
+	d.savePoints = append(d.savePoints, sp)
 
-	glog.Infof("DeclareSP: End: %# v", savePoint)
+	glog.Infof("DeclareSP: End: Depth: %d %# v", len(d.savePoints), sp)
 	return nil
 }
 
+// ReleaseSP releases the innermost SavePoint, retaining the changes made
+// after it was declared.
 func (d *DB) ReleaseSP() error {
 	glog.Infof("ReleaseSP: Begin")
 
+	ix, err := d.findSP("ReleaseSP", nil)
+	if err != nil {
+		return err
+	}
+	return d.releaseSP(ix)
+}
+
+// ReleaseNamedSP releases the innermost SavePoint with the name, and the
+// SavePoints nested within it, retaining the changes made after it was
+// declared.
+func (d *DB) ReleaseNamedSP(name string) error {
+	glog.Infof("ReleaseSP: Begin: %q", name)
+
+	ix, err := d.findSP("ReleaseSP", &name)
+	if err != nil {
+		return err
+	}
+	return d.releaseSP(ix)
+}
+
+// Rollback2SP rolls back the changes made after the innermost SavePoint was
+// declared, and releases it.
+func (d *DB) Rollback2SP() error {
+	glog.Infof("Rollback2SP: Begin:")
+
+	ix, err := d.findSP("Rollback2SP", nil)
+	if err != nil {
+		return err
+	}
+	return d.rollback2SP(ix)
+}
+
+// Rollback2NamedSP rolls back the changes made after the innermost SavePoint
+// with the name was declared, and releases it, and the SavePoints nested
+// within it.
+func (d *DB) Rollback2NamedSP(name string) error {
+	glog.Infof("Rollback2SP: Begin: %q", name)
+
+	ix, err := d.findSP("Rollback2SP", &name)
+	if err != nil {
+		return err
+	}
+	return d.rollback2SP(ix)
+}
+
+// RollbackAllSP rolls back to the outermost SavePoint, and releases all the
+// SavePoints. It is a no-op if there are no SavePoints.
+func (d *DB) RollbackAllSP() error {
+	if !d.HasSP() {
+		return nil
+	}
+	glog.Infof("RollbackAllSP: Begin: Depth: %d", len(d.savePoints))
+	return d.rollback2SP(0)
+}
+
+// findSP returns the index of the innermost SavePoint (with the name, if not
+// nil) in the d.savePoints stack.
+func (d *DB) findSP(caller string, name *string) (int, error) {
 	if (d == nil) || !d.Opts.IsSession {
-		glog.Error("ReleaseSP: Invalid Session")
-		return tlerr.TranslibInvalidSession{}
+		glog.Errorf("%s: Invalid Session", caller)
+		return -1, tlerr.TranslibInvalidSession{}
 	}
 
-	if savePoint == nil {
-		glog.Error("DeclareSP: SavePoint Absent")
-		return tlerr.TranslibDBNotSupported{}
+	for ix := len(d.savePoints) - 1; ix >= 0; ix-- {
+		if (name == nil) || (d.savePoints[ix].name == *name) {
+			return ix, nil
+		}
 	}
 
+	if name == nil {
+		glog.Errorf("%s: SavePoint Absent", caller)
+		return -1, tlerr.TranslibDBNotSupported{}
+	}
+	glog.Errorf("%s: SavePoint %q Absent", caller, *name)
+	return -1, tlerr.TranslibDBNotSupported{
+		Description: "SavePoint " + *name + " Absent"}
+}
+
+// releaseSP pops the SavePoints from ix (inclusive) off the stack. The outer
+// SavePoints have already recorded the changes made.
+func (d *DB) releaseSP(ix int) error {
 	if glog.V(3) {
-		glog.Infof("ReleaseSP: End: Releasing %# v", savePoint)
+		glog.Infof("ReleaseSP: End: Releasing %d of %d: %# v",
+			len(d.savePoints)-ix, len(d.savePoints), d.savePoints[ix])
 	} else {
 		glog.Infof("ReleaseSP: End:")
 	}
 
-	savePoint = nil
-
+	d.popSP(ix)
 	return nil
 }
 
-func (d *DB) Rollback2SP() error {
-	if glog.V(3) {
-		glog.Infof("Rollback2SP: Begin: %# v", savePoint)
-	} else {
-		glog.Infof("Rollback2SP: Begin:")
+// popSP pops the SavePoints from ix (inclusive) off the stack.
+func (d *DB) popSP(ix int) {
+	for i := ix; i < len(d.savePoints); i++ {
+		d.savePoints[i] = nil
 	}
-
-	if (d == nil) || !d.Opts.IsSession {
-		glog.Error("Rollback2SP: Invalid Session")
-		return tlerr.TranslibInvalidSession{}
+	d.savePoints = d.savePoints[:ix]
+	if ix == 0 {
+		d.spHints = nil
 	}
+}
 
-	if savePoint == nil {
-		glog.Error("Rollback2SP: SavePoint Absent")
-		return tlerr.TranslibDBNotSupported{}
+// rollback2SP rolls back to the SavePoint at spIx, and pops it (and the ones
+// nested within it) off the stack.
+func (d *DB) rollback2SP(spIx int) error {
+	savePoint := d.savePoints[spIx]
+	if glog.V(3) {
+		glog.Infof("Rollback2SP: Begin: %d of %d: %# v", spIx,
+			len(d.savePoints), savePoint)
 	}
 
 	// Collect the CandidateConfigNotifs to be sent.
@@ -187,28 +293,34 @@ func (d *DB) Rollback2SP() error {
 	}
 
 	// Rollback CAS Tx Operations
-	d.txCmds = d.txCmds[0:savePoint.txCmdsLen]
-
-	// The redis CAS Tx cache needs to be rebuilt from scratch, because
-	// while reopening (and recreating) the CVL Session, there might be
-	// callbacks into the DB Layer (through the CVL DBAccess interface). Thus
-	// the redis CAS Tx cache needs to move in lock-step with Validations of
-	// the CVL edit ops.
-	// Initialize the txTsEntryMap with the values retrieved by HGetAll during
-	// doWrite() CAS Tx cache update. If there was no value found by HGetAll
-	// a zero Value (i.e. len(Value.Field) == 0) should be there to indicate
-	// the key was absent in redis.
-	for tn, tb := range d.txTsEntryMap {
-		for k := range tb {
-			delete(d.txTsEntryMap[tn], k)
-		}
-	}
-	for tn, tb := range d.txTsEntryHGetAll {
-		if _, ok := d.txTsEntryMap[tn]; !ok {
-			d.txTsEntryHGetAll[tn] = make(map[string]Value)
+	d.truncTxCmds(savePoint.txCmdsLen)
+
+	if d.Opts.DisableCVLCheck || (d.cv == nil) {
+		// There are no CVL edit ops to replay. Restore the entries changed
+		// after the SavePoint was declared, to their original values.
+		d.restoreSPTxCache(savePoint)
+	} else {
+		// The redis CAS Tx cache needs to be rebuilt from scratch, because
+		// while reopening (and recreating) the CVL Session, there might be
+		// callbacks into the DB Layer (through the CVL DBAccess interface). Thus
+		// the redis CAS Tx cache needs to move in lock-step with Validations of
+		// the CVL edit ops.
+		// Initialize the txTsEntryMap with the values retrieved by HGetAll during
+		// doWrite() CAS Tx cache update. If there was no value found by HGetAll
+		// a zero Value (i.e. len(Value.Field) == 0) should be there to indicate
+		// the key was absent in redis.
+		for tn, tb := range d.txTsEntryMap {
+			for k := range tb {
+				delete(d.txTsEntryMap[tn], k)
+			}
 		}
-		for k := range tb {
-			d.txTsEntryMap[tn][k] = tb[k].Copy()
+		for tn, tb := range d.txTsEntryHGetAll {
+			if _, ok := d.txTsEntryMap[tn]; !ok {
+				d.txTsEntryMap[tn] = make(map[string]Value)
+			}
+			for k := range tb {
+				d.txTsEntryMap[tn][k] = tb[k].Copy()
+			}
 		}
 	}
 
@@ -230,27 +342,13 @@ func (d *DB) Rollback2SP() error {
 		// After Each of the Ops (either 2 for ReplaceOp, or 1 for OtherOp),
 		// adjust the CAS Tx cache (in case the next CVL Ops's validations
 		// require data from the DB Layer back again.)
+		hIx := 0
 		for ix := 0; ix < savePoint.cECDLen; ix++ {
 
 			glog.V(3).Infof("Rollback2SP: Playback %d", ix)
 
 			// Replay the hints at this cECDLen first
-			if (savePoint.cHints != nil) && (savePoint.cHints[ix] != nil) {
-				for hKey, hValue := range savePoint.cHints[ix] {
-					glog.V(3).Infof("Rollback2SP: Playback Hint %s:%v", hKey,
-						hValue)
-					// TBD Wait for CVL PR
-					// hRet := d.cv.StoreHint(hKey, hValue)
-					var hRet cvl.CVLRetCode
-					if cvl.CVL_SUCCESS != hRet {
-						glog.Warningf("Rollback2SP:%d: Hint CVL Failure: %d",
-							ix, hRet)
-						err = tlerr.TranslibCVLFailure{Code: int(hRet)}
-						break
-					}
-				}
-			}
-			if err != nil {
+			if err = d.replaySPHints(savePoint, ix, &hIx); err != nil {
 				break
 			}
 
@@ -310,13 +408,14 @@ func (d *DB) Rollback2SP() error {
 			// }
 		}
 
-		// Zeroise the remaining Hints
-		if savePoint.cHints != nil {
-			for ix := savePoint.cECDLen; ix < len(d.cvlEditConfigData); ix++ {
-				delete(savePoint.cHints, ix)
-			}
+		// Replay the hints stored after the last Op, before the SavePoint
+		if err == nil {
+			err = d.replaySPHints(savePoint, savePoint.cECDLen, &hIx)
 		}
 
+		// Zeroise the remaining Hints
+		d.spHints = d.spHints[0:savePoint.cHintsLen]
+
 		// Reset the cvlEditConfigData
 		if err == nil {
 			d.cvlEditConfigData = d.cvlEditConfigData[0:savePoint.cECDLen]
@@ -341,15 +440,53 @@ func (d *DB) Rollback2SP() error {
 		Maps: make(map[string]MAP, InitialMapsCount),
 	}
 
-	savePoint = nil
+	d.popSP(spIx)
 
 	glog.Infof("Rollback2SP: End:")
 	return err
 }
 
+// restoreSPTxCache restores the CAS Tx cache entries recorded in savePoint.
+func (d *DB) restoreSPTxCache(savePoint *_savePoint) {
+	for tn, tbl := range savePoint.txTsOrigEntryMap {
+		if _, ok := d.txTsEntryMap[tn]; !ok {
+			d.txTsEntryMap[tn] = make(map[string]Value)
+		}
+		for rk, oEntry := range tbl {
+			if oEntry.absent {
+				delete(d.txTsEntryMap[tn], rk)
+			} else {
+				d.txTsEntryMap[tn][rk] = oEntry.value.Copy()
+			}
+		}
+	}
+}
+
+// replaySPHints replays the CVL Hints, stored (before the savePoint was
+// declared) at, or before the cECDLen, starting with d.spHints[*hIx].
+func (d *DB) replaySPHints(savePoint *_savePoint, cECDLen int, hIx *int) error {
+	for ; *hIx < savePoint.cHintsLen; *hIx++ {
+		hint := &d.spHints[*hIx]
+		if hint.cECDLen > cECDLen {
+			break
+		}
+		glog.V(3).Infof("Rollback2SP: Playback Hint %s:%v", hint.key,
+			hint.value)
+		// TBD Wait for CVL PR
+		// hRet := d.cv.StoreHint(hint.key, hint.value)
+		var hRet cvl.CVLRetCode
+		if cvl.CVL_SUCCESS != hRet {
+			glog.Warningf("Rollback2SP:%d: Hint CVL Failure: %d",
+				cECDLen, hRet)
+			return tlerr.TranslibCVLFailure{Code: int(hRet)}
+		}
+	}
+	return nil
+}
+
 // doTxSPsave should be called before every change to the CAS Tx Cache.
 func (d *DB) doTxSPsave(ts *TableSpec, key Key) {
-	if (d == nil) || (savePoint == nil) || !d.Opts.IsSession {
+	if (d == nil) || (len(d.savePoints) == 0) || !d.Opts.IsSession {
 		return
 	}
 
@@ -358,17 +495,24 @@ func (d *DB) doTxSPsave(ts *TableSpec, key Key) {
 	glog.V(4).Infof("doTxSPsave: Begin: Table: %s redisKey: %s",
 		tsName, redisKey)
 
-	if _, ok := savePoint.txTsOrigEntryMap[tsName]; !ok {
-		savePoint.txTsOrigEntryMap[tsName] = make(map[string]origEntry)
-	}
+	// Record in every SavePoint, innermost first. If a SavePoint has already
+	// recorded the entry, so have the outer ones (which were declared before).
+	for ix := len(d.savePoints) - 1; ix >= 0; ix-- {
+		savePoint := d.savePoints[ix]
+		if _, ok := savePoint.txTsOrigEntryMap[tsName]; !ok {
+			savePoint.txTsOrigEntryMap[tsName] = make(map[string]origEntry)
+		}
+
+		// Only record, if we have never recorded the original entry.
+		// (On rollback, we don't need to traverse the intermediate entries.
+		// The original entry will suffice)
+		if _, ok := savePoint.txTsOrigEntryMap[tsName][redisKey]; ok {
+			break
+		}
 
-	// Only record, if we have never recorded the original entry.
-	// (On rollback, we don't need to traverse the intermediate entries. The
-	// original entry will suffice)
-	if _, ok := savePoint.txTsOrigEntryMap[tsName][redisKey]; !ok {
 		value, vok := d.txTsEntryMap[tsName][redisKey]
-		glog.V(3).Infof("doTxSPsave:Record:T: %s redisKey: %s val: %#v vok: %t",
-			tsName, redisKey, value, vok)
+		glog.V(3).Infof("doTxSPsave:Record:%d:T: %s redisKey: %s val: %#v vok: %t",
+			ix, tsName, redisKey, value, vok)
 
 		savePoint.txTsOrigEntryMap[tsName][redisKey] = origEntry{
 			value: value.Copy(), absent: !vok}
@@ -376,9 +520,11 @@ func (d *DB) doTxSPsave(ts *TableSpec, key Key) {
 }
 
 // doTxSPsaveHGetAll is a sister func of doTxSPsave, and saves HGetAll() made
-// just prior to the time of change to CAS Tx Cache for the first time.
+// just prior to the time of change to CAS Tx Cache for the first time. It is
+// saved even if no SavePoint is declared yet, since a rollback to any (later)
+// SavePoint rebuilds the CAS Tx Cache from these.
 func (d *DB) doTxSPsaveHGetAll(ts *TableSpec, key Key, value Value) {
-	if (d == nil) || (savePoint == nil) || !d.Opts.IsSession {
+	if (d == nil) || !d.Opts.IsSession {
 		return
 	}
 
@@ -398,18 +544,10 @@ func (d *DB) doTxSPsaveHGetAll(ts *TableSpec, key Key, value Value) {
 
 // doCHintSave should be called on successfully Storing a Hint to CVL
 func (d *DB) doCHintSave(key string, value interface{}) {
-	if (d == nil) || (savePoint == nil) || !d.Opts.IsSession {
+	if (d == nil) || (len(d.savePoints) == 0) || !d.Opts.IsSession {
 		return
 	}
 
-	if savePoint.cHints == nil {
-		savePoint.cHints = make(map[int]map[string]interface{})
-	}
-
-	cECDLen := len(d.cvlEditConfigData)
-	if savePoint.cHints[cECDLen] == nil {
-		savePoint.cHints[cECDLen] = make(map[string]interface{})
-	}
-
-	savePoint.cHints[cECDLen][key] = value
+	d.spHints = append(d.spHints, spHint{cECDLen: len(d.cvlEditConfigData),
+		key: key, value: value})
 }
@@ -22,6 +22,7 @@ package db
 import (
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
 
 	"testing"
@@ -236,6 +237,104 @@ func TestSPDeclareSP(t *testing.T) {
 
 }
 
+// TestSPNested tests nested, named SavePoints
+func TestSPNested(t *testing.T) {
+
+	ccd, e := NewDB(Options{
+		DBNo:                    ConfigDB,
+		InitIndicator:           "",
+		TableNameSeparator:      "|",
+		KeySeparator:            "|",
+		IsSession:               true,
+		DisableCVLCheck:         true,
+		ForceNewRedisConnection: true,
+	})
+
+	if e != nil {
+		t.Fatalf("Session NewDB() fails e: %v", e)
+	}
+
+	t.Cleanup(func() { ccd.DeleteDB() })
+
+	if e = ccd.StartSessTx(nil, []*TableSpec{&(TableSpec{Name: "*"})}); e != nil {
+		t.Fatalf("Session StartTx() fails e: %v", e)
+	}
+
+	t.Cleanup(func() { ccd.AbortSessTx() })
+
+	ts := &TableSpec{Name: SP_PF + "NESTED"}
+	mod := func(k string) {
+		if e := ccd.ModEntry(ts, Key{Comp: []string{k}},
+			Value{Field: map[string]string{"k": k}}); e != nil {
+			t.Fatalf("ccd.ModEntry(%s) fails e: %v", k, e)
+		}
+	}
+	expect := func(present ...string) {
+		t.Helper()
+		keys, e := ccd.GetKeys(ts)
+		if e != nil {
+			t.Fatalf("ccd.GetKeys() fails e: %v", e)
+		}
+		got := []string{}
+		for _, k := range keys {
+			got = append(got, k.Get(0))
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, present) {
+			t.Errorf("Keys: %v Expected: %v", got, present)
+		}
+	}
+
+	mod("a")
+	if e = ccd.DeclareNamedSP("outer"); e != nil {
+		t.Fatalf("DeclareNamedSP(outer) fails e: %v", e)
+	}
+	mod("b")
+	if e = ccd.DeclareNamedSP("inner"); e != nil {
+		t.Fatalf("DeclareNamedSP(inner) fails e: %v", e)
+	}
+	mod("c")
+	if e = ccd.DeclareSP(); e != nil {
+		t.Fatalf("DeclareSP() fails e: %v", e)
+	}
+	mod("d")
+
+	if names := ccd.SPNames(); !reflect.DeepEqual(names, []string{"outer", "inner", ""}) {
+		t.Errorf("SPNames() = %q", names)
+	}
+
+	if e = ccd.ReleaseNamedSP("missing"); e == nil {
+		t.Errorf("ReleaseNamedSP(missing) succeeds")
+	}
+
+	// Rolling back to inner also releases the unnamed SavePoint.
+	if e = ccd.Rollback2NamedSP("inner"); e != nil {
+		t.Errorf("Rollback2NamedSP(inner) fails e: %v", e)
+	}
+	expect("a", "b")
+	if names := ccd.SPNames(); !reflect.DeepEqual(names, []string{"outer"}) {
+		t.Errorf("SPNames() = %q after Rollback2NamedSP(inner)", names)
+	}
+
+	if e = ccd.DeclareNamedSP("inner"); e != nil {
+		t.Fatalf("DeclareNamedSP(inner) fails e: %v", e)
+	}
+	mod("e")
+	if e = ccd.ReleaseNamedSP("inner"); e != nil {
+		t.Errorf("ReleaseNamedSP(inner) fails e: %v", e)
+	}
+	expect("a", "b", "e")
+
+	// The outer SavePoint rolls back the changes of the released inner one.
+	if e = ccd.Rollback2NamedSP("outer"); e != nil {
+		t.Errorf("Rollback2NamedSP(outer) fails e: %v", e)
+	}
+	expect("a")
+	if ccd.HasSP() {
+		t.Errorf("HasSP() after Rollback2NamedSP(outer)")
+	}
+}
+
 // TestRollback2SP
 func TestSPRollback2SP(t *testing.T) {
 	for _, tc := range spTests {
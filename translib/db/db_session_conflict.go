@@ -0,0 +1,188 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+// Config Session (Candidate Config) commit-time conflict detection.
+// The Candidate Config does not lock the CONFIG_DB for its lifetime. Instead,
+// the keys written by it are checked for modifications in the CONFIG_DB since
+// they were first read by the Session, just before the commit.
+
+import (
+	"context"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+	"github.com/redis/go-redis/v9"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// TxConflict is a key written by the Config Session transaction, which has
+// been modified in the DB since the transaction first read it.
+type TxConflict struct {
+	Ts    *TableSpec
+	Key   Key
+	Entry string // redis key. Eg: "PORT|Ethernet0"
+	Orig  Value  // When first read by the transaction
+	Value Value  // Now. (len(Value.Field) == 0: Deleted)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// TxKeys returns the keys written by the transaction, in the order they were
+// first written.
+func (d *DB) TxKeys() []WatchKeys {
+	if d == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(d.txCmds))
+	keys := make([]WatchKeys, 0, len(d.txCmds))
+	for i := range d.txCmds {
+		txCmd := &d.txCmds[i]
+		entry := d.key2redis(txCmd.ts, *txCmd.key)
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		keys = append(keys, WatchKeys{Ts: txCmd.ts, Key: txCmd.key})
+	}
+	return keys
+}
+
+// CheckTxConflicts (Config Session only) WATCHes the keys written by the
+// transaction, and returns the ones modified in the DB since they were first
+// read by the transaction. A key written without being read (not expected,
+// as the writes record the keys first) is a conflict, since its modifications
+// can not be detected. If there are none, the CommitSessTx() should follow,
+// which fails if any of the keys are modified in between. Otherwise, the keys
+// are UNWATCHed.
+func (d *DB) CheckTxConflicts() ([]TxConflict, error) {
+	if (d == nil) || !d.Opts.IsSession {
+		glog.Error("CheckTxConflicts: Invalid Session")
+		return nil, tlerr.TranslibInvalidSession{}
+	}
+
+	if d.err != nil {
+		glog.Error("CheckTxConflicts: DB in error: ", d.err)
+		return nil, d.err
+	}
+
+	keys := d.TxKeys()
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	args := make([]interface{}, 0, len(keys)+1)
+	args = append(args, "WATCH")
+	for _, wk := range keys {
+		args = append(args, d.key2redis(wk.Ts, *wk.Key))
+	}
+
+	glog.V(3).Info("CheckTxConflicts: RedisCmd: ", d.Name(), ": ", args)
+	if err := d.client.Do(ctx, args...).Err(); err != nil {
+		glog.Error("CheckTxConflicts: WATCH: ", err)
+		return nil, err
+	}
+
+	pipe := d.client.Pipeline()
+	for _, arg := range args[1:] {
+		pipe.HGetAll(ctx, arg.(string))
+	}
+	cmds, err := pipe.Exec(ctx)
+	if err != nil {
+		glog.Error("CheckTxConflicts: HGETALL: ", err)
+		d.client.Do(ctx, "UNWATCH")
+		return nil, err
+	}
+
+	var conflicts []TxConflict
+	for i, wk := range keys {
+		entry := args[i+1].(string)
+		value := Value{Field: cmds[i].(*redis.MapStringStringCmd).Val()}
+		orig, ok := d.txTsEntryHGetAll[wk.Ts.Name][entry]
+		if !ok {
+			glog.Warningf("CheckTxConflicts: %s: Not read in Tx", entry)
+			conflicts = append(conflicts, TxConflict{Ts: wk.Ts,
+				Key: wk.Key.Copy(), Entry: entry, Value: value})
+		} else if !orig.equalFields(value) {
+			glog.Infof("CheckTxConflicts: %s: Modified: %v -> %v", entry,
+				orig, value)
+			conflicts = append(conflicts, TxConflict{Ts: wk.Ts,
+				Key: wk.Key.Copy(), Entry: entry, Orig: orig.Copy(), Value: value})
+		}
+	}
+
+	if len(conflicts) != 0 {
+		glog.V(3).Info("CheckTxConflicts: RedisCmd: ", d.Name(), ": UNWATCH")
+		d.client.Do(ctx, "UNWATCH")
+	}
+	return conflicts, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// txReadEntry (Config Session only) records the entry as in the DB, before
+// the first write of the transaction to it, for the CheckTxConflicts(). The
+// writes not recording it themselves (Eg: Expire) use it.
+func (d *DB) txReadEntry(ts *TableSpec, key Key) error {
+	if !d.Opts.IsSession {
+		return nil
+	}
+
+	entry := d.key2redis(ts, key)
+	if _, ok := d.txTsEntryMap[ts.Name][entry]; ok {
+		return nil
+	}
+
+	glog.V(3).Info("txReadEntry: RedisCmd: ", d.Name(), ": ", "HGETALL ", entry)
+	v, e := d.client.HGetAll(context.Background(), entry).Result()
+	if e != nil {
+		return e
+	}
+
+	if _, ok := d.txTsEntryMap[ts.Name]; !ok {
+		d.txTsEntryMap[ts.Name] = make(map[string]Value)
+	}
+	d.txTsEntryMap[ts.Name][entry] = Value{Field: v}
+	d.doTxSPsaveHGetAll(ts, key, Value{Field: v})
+	return nil
+}
+
+// equalFields returns true if the v, and other have the same fields, and
+// values. (A nil, and an empty Field are equal.)
+func (v Value) equalFields(other Value) bool {
+	if len(v.Field) != len(other.Field) {
+		return false
+	}
+	for f, fv := range v.Field {
+		if ofv, ok := other.Field[f]; !ok || ofv != fv {
+			return false
+		}
+	}
+	return true
+}
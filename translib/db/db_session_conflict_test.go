@@ -0,0 +1,174 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var CONFLICT_PF string = "DBCONFLICT_TST_" + strconv.FormatInt(int64(os.Getpid()), 10)
+
+func newConflictTestSession(t *testing.T) *DB {
+	ccd, e := NewDB(Options{
+		DBNo:                    ConfigDB,
+		InitIndicator:           "",
+		TableNameSeparator:      "|",
+		KeySeparator:            "|",
+		IsSession:               true,
+		DisableCVLCheck:         true,
+		ForceNewRedisConnection: true,
+	})
+	if e != nil {
+		t.Fatalf("Session NewDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { ccd.DeleteDB() })
+
+	if e = ccd.StartSessTx(nil, nil); e != nil {
+		t.Fatalf("Session StartSessTx() fails e: %v", e)
+	}
+	return ccd
+}
+
+func TestTxConflicts(t *testing.T) {
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+
+	ts := TableSpec{Name: CONFLICT_PF}
+	k1 := Key{Comp: []string{"k1"}}
+	k2 := Key{Comp: []string{"k2"}}
+	t.Cleanup(func() { deleteTableAndDb(d, &ts, t) })
+
+	v := Value{Field: map[string]string{"f1": "v1"}}
+	if e = d.SetEntry(&ts, k1, v); e != nil {
+		t.Fatalf("SetEntry(%v) fails e: %v", k1, e)
+	}
+
+	ccd := newConflictTestSession(t)
+	if e = ccd.ModEntry(&ts, k1, Value{Field: map[string]string{"f2": "v2"}}); e != nil {
+		t.Fatalf("Session ModEntry(%v) fails e: %v", k1, e)
+	}
+	if e = ccd.SetEntry(&ts, k2, v); e != nil {
+		t.Fatalf("Session SetEntry(%v) fails e: %v", k2, e)
+	}
+
+	if keys := ccd.TxKeys(); len(keys) != 2 {
+		t.Errorf("TxKeys() = %v, expected 2 keys", keys)
+	}
+
+	// Modify the running config
+	if e = d.ModEntry(&ts, k2, Value{Field: map[string]string{"f3": "v3"}}); e != nil {
+		t.Fatalf("ModEntry(%v) fails e: %v", k2, e)
+	}
+
+	conflicts, e := ccd.CheckTxConflicts()
+	if e != nil {
+		t.Fatalf("CheckTxConflicts() fails e: %v", e)
+	}
+	if len(conflicts) != 1 || conflicts[0].Entry != CONFLICT_PF+"|k2" ||
+		len(conflicts[0].Orig.Field) != 0 {
+		t.Fatalf("CheckTxConflicts() = %v, expected %s|k2", conflicts, CONFLICT_PF)
+	}
+
+	if e = ccd.AbortSessTx(); e != nil {
+		t.Errorf("Session AbortSessTx() fails e: %v", e)
+	}
+}
+
+func TestTxNoConflicts(t *testing.T) {
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+
+	ts := TableSpec{Name: CONFLICT_PF}
+	k1 := Key{Comp: []string{"k1"}}
+	t.Cleanup(func() { deleteTableAndDb(d, &ts, t) })
+
+	if e = d.SetEntry(&ts, k1, Value{Field: map[string]string{"f1": "v1"}}); e != nil {
+		t.Fatalf("SetEntry(%v) fails e: %v", k1, e)
+	}
+
+	ccd := newConflictTestSession(t)
+	if e = ccd.DeleteEntry(&ts, k1); e != nil {
+		t.Fatalf("Session DeleteEntry(%v) fails e: %v", k1, e)
+	}
+
+	// Modify an unrelated key in the running config
+	if e = d.SetEntry(&ts, Key{Comp: []string{"k2"}}, Value{Field: map[string]string{"f1": "v1"}}); e != nil {
+		t.Fatalf("SetEntry(k2) fails e: %v", e)
+	}
+
+	conflicts, e := ccd.CheckTxConflicts()
+	if e != nil || len(conflicts) != 0 {
+		t.Fatalf("CheckTxConflicts() = %v, %v, expected none", conflicts, e)
+	}
+
+	if e = ccd.CommitSessTx(); e != nil {
+		t.Fatalf("Session CommitSessTx() fails e: %v", e)
+	}
+
+	if _, e = d.GetEntry(&ts, k1); e == nil {
+		t.Errorf("GetEntry(%v) succeeds after commit of delete", k1)
+	}
+}
+
+func TestTxConflictsExpire(t *testing.T) {
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+
+	ts := TableSpec{Name: CONFLICT_PF}
+	k1 := Key{Comp: []string{"k1"}}
+	t.Cleanup(func() { deleteTableAndDb(d, &ts, t) })
+
+	if e = d.SetEntry(&ts, k1, Value{Field: map[string]string{"f1": "v1"}}); e != nil {
+		t.Fatalf("SetEntry(%v) fails e: %v", k1, e)
+	}
+
+	// Written (Expire), without being modified by the session.
+	ccd := newConflictTestSession(t)
+	if e = ccd.Expire(&ts, k1, time.Hour); e != nil {
+		t.Fatalf("Session Expire(%v) fails e: %v", k1, e)
+	}
+	if diff, e := ccd.TxDiff(); e != nil || len(diff) != 0 {
+		t.Errorf("TxDiff() = %v, %v, expected none", diff, e)
+	}
+
+	// Modify the running config
+	if e = d.ModEntry(&ts, k1, Value{Field: map[string]string{"f1": "v2"}}); e != nil {
+		t.Fatalf("ModEntry(%v) fails e: %v", k1, e)
+	}
+
+	conflicts, e := ccd.CheckTxConflicts()
+	if e != nil || len(conflicts) != 1 || conflicts[0].Orig.Get("f1") != "v1" {
+		t.Fatalf("CheckTxConflicts() = %v, %v, expected %s|k1", conflicts, e,
+			CONFLICT_PF)
+	}
+
+	if e = ccd.AbortSessTx(); e != nil {
+		t.Errorf("Session AbortSessTx() fails e: %v", e)
+	}
+}
@@ -0,0 +1,80 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+// Config Session (Candidate Config) diff against the running config.
+
+import (
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// TxDiffEntry is a key with pending changes in the transaction.
+type TxDiffEntry struct {
+	Ts     *TableSpec
+	Key    Key
+	Entry  string // redis key. Eg: "PORT|Ethernet0"
+	Before Value  // When first read by the transaction (Empty: Did not exist)
+	After  Value  // In the transaction cache (Empty: Deleted)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// TxDiff (Config Session only) returns the keys changed by the transaction,
+// in the order they were first written, with their values before, and after
+// the transaction. The keys written back to their original values are
+// skipped.
+func (d *DB) TxDiff() ([]TxDiffEntry, error) {
+	if (d == nil) || !d.Opts.IsSession {
+		glog.Error("TxDiff: Invalid Session")
+		return nil, tlerr.TranslibInvalidSession{}
+	}
+
+	if d.err != nil {
+		glog.Error("TxDiff: DB in error: ", d.err)
+		return nil, d.err
+	}
+
+	var diff []TxDiffEntry
+	for _, wk := range d.TxKeys() {
+		entry := d.key2redis(wk.Ts, *wk.Key)
+		before, ok := d.txTsEntryHGetAll[wk.Ts.Name][entry]
+		if !ok {
+			glog.Warningf("TxDiff: %s: Not read in Tx", entry)
+		}
+		after := d.txTsEntryMap[wk.Ts.Name][entry]
+
+		if before.equalFields(after) {
+			glog.V(3).Infof("TxDiff: %s: Unchanged", entry)
+			continue
+		}
+
+		diff = append(diff, TxDiffEntry{Ts: wk.Ts, Key: wk.Key.Copy(),
+			Entry: entry, Before: before.Copy(), After: after.Copy()})
+	}
+
+	return diff, nil
+}
@@ -0,0 +1,77 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTxDiff(t *testing.T) {
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+
+	ts := TableSpec{Name: CONFLICT_PF + "_DIFF"}
+	k1 := Key{Comp: []string{"k1"}}
+	k2 := Key{Comp: []string{"k2"}}
+	k3 := Key{Comp: []string{"k3"}}
+	t.Cleanup(func() { deleteTableAndDb(d, &ts, t) })
+
+	for _, k := range []Key{k1, k3} {
+		if e = d.SetEntry(&ts, k, Value{Field: map[string]string{"f1": "v1"}}); e != nil {
+			t.Fatalf("SetEntry(%v) fails e: %v", k, e)
+		}
+	}
+
+	ccd := newConflictTestSession(t)
+	t.Cleanup(func() { ccd.AbortSessTx() })
+
+	// Modify k1, Create k2, and write back k3 unchanged.
+	if e = ccd.ModEntry(&ts, k1, Value{Field: map[string]string{"f1": "v2"}}); e != nil {
+		t.Fatalf("Session ModEntry(%v) fails e: %v", k1, e)
+	}
+	if e = ccd.SetEntry(&ts, k2, Value{Field: map[string]string{"f2": "v2"}}); e != nil {
+		t.Fatalf("Session SetEntry(%v) fails e: %v", k2, e)
+	}
+	if e = ccd.ModEntry(&ts, k3, Value{Field: map[string]string{"f1": "v1"}}); e != nil {
+		t.Fatalf("Session ModEntry(%v) fails e: %v", k3, e)
+	}
+
+	diff, e := ccd.TxDiff()
+	if e != nil {
+		t.Fatalf("TxDiff() fails e: %v", e)
+	}
+	if len(diff) != 2 {
+		t.Fatalf("TxDiff() = %v, expected 2 entries", diff)
+	}
+
+	if !diff[0].Key.Equals(k1) ||
+		!reflect.DeepEqual(diff[0].Before.Field, map[string]string{"f1": "v1"}) ||
+		!reflect.DeepEqual(diff[0].After.Field, map[string]string{"f1": "v2"}) {
+		t.Errorf("TxDiff()[0] = %v, expected %v f1: v1 -> v2", diff[0], k1)
+	}
+
+	if !diff[1].Key.Equals(k2) || len(diff[1].Before.Field) != 0 ||
+		!reflect.DeepEqual(diff[1].After.Field, map[string]string{"f2": "v2"}) {
+		t.Errorf("TxDiff()[1] = %v, expected %v created", diff[1], k2)
+	}
+}
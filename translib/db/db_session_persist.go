@@ -0,0 +1,227 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+// Config Session (Candidate Config) persistence. The pending commands of the
+// transaction are saved (Eg: across a process restart), and replayed into a
+// new transaction.
+
+import (
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// TxCmd is a pending command of the transaction.
+type TxCmd struct {
+	Op     string        `json:"op"` // HMSET, HDEL, DEL, PEXPIRE
+	Ts     TableSpec     `json:"ts"`
+	Key    Key           `json:"key"`
+	Value  Value         `json:"value,omitempty"`  // Fields set (HMSET), or deleted (HDEL)
+	TTL    time.Duration `json:"ttl,omitempty"`    // PEXPIRE
+	Create bool          `json:"create,omitempty"` // HMSET of an absent key
+	Orig   *Value        `json:"orig,omitempty"`   // Key as first read (first cmd of the key)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// TxCmds (Config Session only) returns the pending commands of the
+// transaction, in the order of execution. The HMSET of a key absent (before
+// the transaction, or after its deletion in the transaction) is a Create. The
+// first command of a key carries the Orig value of the key, as first read by
+// the transaction, for the CheckTxConflicts() after a replay.
+func (d *DB) TxCmds() ([]TxCmd, error) {
+	cmds, _, err := d.txCmdsFrom(0)
+	return cmds, err
+}
+
+// TxCmdsSince (Config Session only) returns the pending commands of the
+// transaction, from the index from, to persist them incrementally. The
+// commands of the transaction before from are unchanged since the last call,
+// when there were mark commands. (The commands rolled back since, are
+// returned again)
+func (d *DB) TxCmdsSince(mark int) ([]TxCmd, int, error) {
+	from := mark
+	if d != nil && d.txCmdsLow < from {
+		from = d.txCmdsLow
+	}
+
+	cmds, from, err := d.txCmdsFrom(from)
+	if err == nil {
+		d.txCmdsLow = len(d.txCmds)
+	}
+	return cmds, from, err
+}
+
+func (d *DB) txCmdsFrom(from int) ([]TxCmd, int, error) {
+	if (d == nil) || !d.Opts.IsSession {
+		glog.Error("TxCmds: Invalid Session")
+		return nil, 0, tlerr.TranslibInvalidSession{}
+	}
+
+	if from > len(d.txCmds) {
+		from = len(d.txCmds)
+	}
+
+	// The fields of the keys, as the commands are executed
+	fields := make(map[string]map[string]bool)
+	keyFields := func(ts *TableSpec, entry string) map[string]bool {
+		f, ok := fields[entry]
+		if !ok {
+			f = make(map[string]bool)
+			for k := range d.txTsEntryHGetAll[ts.Name][entry].Field {
+				f[k] = true
+			}
+			fields[entry] = f
+		}
+		return f
+	}
+
+	cmds := make([]TxCmd, 0, len(d.txCmds)-from)
+	seen := make(map[string]bool)
+	for i := range d.txCmds {
+		txCmd := &d.txCmds[i]
+		cmd := TxCmd{Op: getOperationName(txCmd.op), Ts: *txCmd.ts,
+			Key: txCmd.key.Copy(), TTL: txCmd.ttl}
+		if txCmd.value != nil {
+			cmd.Value = txCmd.value.Copy()
+		}
+
+		entry := d.key2redis(txCmd.ts, *txCmd.key)
+		if !seen[entry] {
+			seen[entry] = true
+			if orig, ok := d.txTsEntryHGetAll[txCmd.ts.Name][entry]; ok {
+				orig = orig.Copy()
+				cmd.Orig = &orig
+			}
+		}
+		f := keyFields(txCmd.ts, entry)
+		switch txCmd.op {
+		case txOpHMSet:
+			cmd.Create = (len(f) == 0)
+			for k := range cmd.Value.Field {
+				f[k] = true
+			}
+		case txOpHDel:
+			for k := range cmd.Value.Field {
+				delete(f, k)
+			}
+		case txOpDel:
+			fields[entry] = make(map[string]bool)
+		}
+
+		if i >= from {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	return cmds, from, nil
+}
+
+// ReplayTxCmds (Config Session only) re-executes the commands (returned by
+// TxCmds() of an earlier transaction) in the transaction. The commands are
+// validated (CVL) again, against the current DB. The keys, as first read by
+// the transaction, are restored from the Orig of the commands, so that the
+// CheckTxConflicts() detects the modifications since the earlier transaction
+// read them.
+func (d *DB) ReplayTxCmds(cmds []TxCmd) error {
+	if (d == nil) || !d.Opts.IsSession {
+		glog.Error("ReplayTxCmds: Invalid Session")
+		return tlerr.TranslibInvalidSession{}
+	}
+
+	glog.Info("ReplayTxCmds: Begin: ", d.Name(), ": #cmds: ", len(cmds))
+
+	var e error
+	for i := range cmds {
+		cmd := &cmds[i]
+		ts := cmd.Ts
+
+		switch cmd.Op {
+		case getOperationName(txOpHMSet):
+			if cmd.Create {
+				e = d.CreateEntry(&ts, cmd.Key, cmd.Value)
+			} else {
+				e = d.ModEntry(&ts, cmd.Key, cmd.Value)
+			}
+		case getOperationName(txOpHDel):
+			e = d.DeleteEntryFields(&ts, cmd.Key, cmd.Value)
+		case getOperationName(txOpDel):
+			e = d.DeleteEntry(&ts, cmd.Key)
+		case getOperationName(txOpExpire):
+			e = d.Expire(&ts, cmd.Key, cmd.TTL)
+		default:
+			e = tlerr.TranslibDBNotSupported{Description: "ReplayTxCmds: Op " +
+				cmd.Op}
+		}
+
+		if e != nil {
+			glog.Error("ReplayTxCmds: ", cmd.Op, " ", ts.Name, " ", cmd.Key,
+				": e: ", e)
+			break
+		}
+
+		if cmd.Orig != nil {
+			d.doTxSPsaveHGetAll(&ts, cmd.Key, *cmd.Orig)
+		}
+	}
+
+	glog.Info("ReplayTxCmds: End: e: ", e)
+	return e
+}
+
+// CopyTxOrig (Config Session only) replaces the values of the keys, as first
+// read by the transaction, with the ones first read by the src transaction
+// (Eg: of which the commands were replayed in the transaction). The conflicts
+// (CheckTxConflicts) are then detected since the reads of the src.
+func (d *DB) CopyTxOrig(src *DB) error {
+	if (d == nil) || !d.Opts.IsSession || (src == nil) || !src.Opts.IsSession {
+		glog.Error("CopyTxOrig: Invalid Session")
+		return tlerr.TranslibInvalidSession{}
+	}
+
+	for tsName, entries := range d.txTsEntryHGetAll {
+		for entry := range entries {
+			if orig, ok := src.txTsEntryHGetAll[tsName][entry]; ok {
+				entries[entry] = orig.Copy()
+			}
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// truncTxCmds truncates the txCmds to n commands.
+func (d *DB) truncTxCmds(n int) {
+	d.txCmds = d.txCmds[:n]
+	if n < d.txCmdsLow {
+		d.txCmdsLow = n
+	}
+}
@@ -0,0 +1,152 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestTxCmdsReplay(t *testing.T) {
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+
+	ts := TableSpec{Name: CONFLICT_PF + "_PERSIST"}
+	k1 := Key{Comp: []string{"k1"}}
+	k2 := Key{Comp: []string{"k2"}}
+	t.Cleanup(func() { deleteTableAndDb(d, &ts, t) })
+
+	if e = d.SetEntry(&ts, k1, Value{Field: map[string]string{"f1": "v1", "f2": "v2"}}); e != nil {
+		t.Fatalf("SetEntry(%v) fails e: %v", k1, e)
+	}
+
+	ccd := newConflictTestSession(t)
+	t.Cleanup(func() { ccd.AbortSessTx() })
+
+	// Replace k1 (HMSET, HDEL), and create k2.
+	if e = ccd.SetEntry(&ts, k1, Value{Field: map[string]string{"f1": "v3"}}); e != nil {
+		t.Fatalf("Session SetEntry(%v) fails e: %v", k1, e)
+	}
+	if e = ccd.SetEntry(&ts, k2, Value{Field: map[string]string{"f2": "v2"}}); e != nil {
+		t.Fatalf("Session SetEntry(%v) fails e: %v", k2, e)
+	}
+
+	cmds, e := ccd.TxCmds()
+	if e != nil {
+		t.Fatalf("TxCmds() fails e: %v", e)
+	}
+
+	// Persisted, and read back.
+	b, e := json.Marshal(cmds)
+	if e != nil {
+		t.Fatalf("json.Marshal(%v) fails e: %v", cmds, e)
+	}
+	var rCmds []TxCmd
+	if e = json.Unmarshal(b, &rCmds); e != nil {
+		t.Fatalf("json.Unmarshal(%s) fails e: %v", b, e)
+	}
+
+	// Modified (Eg: by another session) after the session read it, before
+	// the replay.
+	if e = d.SetEntry(&ts, k1, Value{Field: map[string]string{"f1": "v4"}}); e != nil {
+		t.Fatalf("SetEntry(%v) fails e: %v", k1, e)
+	}
+
+	rccd := newConflictTestSession(t)
+	t.Cleanup(func() { rccd.AbortSessTx() })
+
+	if e = rccd.ReplayTxCmds(rCmds); e != nil {
+		t.Fatalf("ReplayTxCmds(%v) fails e: %v", rCmds, e)
+	}
+
+	for _, k := range []Key{k1, k2} {
+		want, _ := ccd.GetEntry(&ts, k)
+		got, e := rccd.GetEntry(&ts, k)
+		if e != nil || !reflect.DeepEqual(got.Field, want.Field) {
+			t.Errorf("Replayed GetEntry(%v) = %v, e: %v, expected %v", k,
+				got, e, want)
+		}
+	}
+
+	conflicts, e := rccd.CheckTxConflicts()
+	if e != nil || len(conflicts) != 1 || !conflicts[0].Key.Equals(k1) {
+		t.Errorf("Replayed CheckTxConflicts() = %v, e: %v, expected %v",
+			conflicts, e, k1)
+	}
+}
+
+func TestTxCmdsReplayCVL(t *testing.T) {
+	newCVLSession := func() *DB {
+		ccd, e := NewDB(Options{
+			DBNo:                    ConfigDB,
+			InitIndicator:           "",
+			TableNameSeparator:      "|",
+			KeySeparator:            "|",
+			IsSession:               true,
+			ForceNewRedisConnection: true,
+		})
+		if e != nil {
+			t.Fatalf("Session NewDB() fails e: %v", e)
+		}
+		t.Cleanup(func() { ccd.DeleteDB() })
+
+		if e = ccd.StartSessTx(nil, nil); e != nil {
+			t.Fatalf("Session StartSessTx() fails e: %v", e)
+		}
+		t.Cleanup(func() { ccd.AbortSessTx() })
+		return ccd
+	}
+
+	ts := TableSpec{Name: "ACL_TABLE"}
+	key := Key{Comp: []string{"TXREPLAY_" + strconv.Itoa(os.Getpid())}}
+	value := Value{Field: map[string]string{"type": "L3", "stage": "INGRESS"}}
+
+	ccd := newCVLSession()
+	if e := ccd.CreateEntry(&ts, key, value); e != nil {
+		t.Fatalf("Session CreateEntry(%v) fails e: %v", key, e)
+	}
+	if e := ccd.ModEntry(&ts, key, Value{Field: map[string]string{
+		"policy_desc": "replay"}}); e != nil {
+		t.Fatalf("Session ModEntry(%v) fails e: %v", key, e)
+	}
+
+	cmds, e := ccd.TxCmds()
+	if e != nil || len(cmds) != 2 || !cmds[0].Create || cmds[1].Create {
+		t.Fatalf("TxCmds() = %v, e: %v, expected a create, and a modify",
+			cmds, e)
+	}
+
+	rccd := newCVLSession()
+	if e = rccd.ReplayTxCmds(cmds); e != nil {
+		t.Fatalf("ReplayTxCmds(%v) fails e: %v", cmds, e)
+	}
+
+	want, _ := ccd.GetEntry(&ts, key)
+	if got, e := rccd.GetEntry(&ts, key); e != nil ||
+		!reflect.DeepEqual(got.Field, want.Field) {
+		t.Errorf("Replayed GetEntry(%v) = %v, e: %v, expected %v", key, got,
+			e, want)
+	}
+}
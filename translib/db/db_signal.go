@@ -68,6 +68,10 @@ func HandleSIGUSR2() {
 	if dbRedisOptsConfig != nil {
 		dbRedisOptsConfig.handleReconfigureSignal()
 	}
+
+	if dbChangeLogConfig != nil {
+		dbChangeLogConfig.handleReconfigureSignal()
+	}
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -63,6 +63,7 @@ type Stats struct {
 	// Cache Statistics
 
 	GetEntryCacheHits       uint `json:"get-entry-cache-hits,omitempty"`
+	GetEntryGlobalCacheHits uint `json:"get-entry-global-cache-hits,omitempty"`
 	GetKeysCacheHits        uint `json:"keys-cache-hits,omitempty"`
 	GetKeysPatternCacheHits uint `json:"keys-pattern-cache-hits,omitempty"`
 	GetMapCacheHits         uint `json:"get-map-cache-hits,omitempty"`
@@ -109,6 +110,9 @@ type DBStats struct {
 	Tables         map[string]Stats `json:"tables,omitempty"`
 	Maps           map[string]Stats `json:"maps,omitempty"`
 	RedisPoolStats redis.PoolStats  `json:"redis-pool-stats,omitempty"`
+
+	// Global (i.e. process wide) cache stats. Only for the CONFIG_DB.
+	GlobalCache *GlobalCacheStats `json:"global-cache,omitempty"`
 }
 
 type DBGlobalStats struct {
@@ -240,6 +244,10 @@ func (stats *DBGlobalStats) getStats() (*DBGlobalStats, error) {
 		if poolStats := rcmCounters.PoolStatsPerDB[name]; poolStats != nil {
 			dbGlobalStats.Databases[dbnum].RedisPoolStats = *poolStats
 		}
+
+		if DBNum(dbnum) == ConfigDB {
+			dbGlobalStats.Databases[dbnum].GlobalCache = dbGlobalCache.getStats()
+		}
 	}
 
 	mutexDBGlobalStats.Unlock()
@@ -305,6 +313,8 @@ func (stats *DBGlobalStats) clearStats() error {
 	*stats = DBGlobalStats{Databases: make([]DBStats, MaxDB)}
 	mutexDBGlobalStats.Unlock()
 
+	dbGlobalCache.clearStats()
+
 	return nil
 }
 
@@ -412,6 +422,7 @@ func (stats *Stats) updateStats(connStats *Stats) error {
 		stats.GetNextKeysHits += connStats.GetNextKeysHits
 
 		stats.GetEntryCacheHits += connStats.GetEntryCacheHits
+		stats.GetEntryGlobalCacheHits += connStats.GetEntryGlobalCacheHits
 		stats.GetKeysCacheHits += connStats.GetKeysCacheHits
 		stats.GetKeysPatternCacheHits += connStats.GetKeysPatternCacheHits
 		stats.GetMapCacheHits += connStats.GetMapCacheHits
@@ -20,6 +20,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"reflect"
@@ -484,3 +485,137 @@ func TestSubscribeNoCCNotif2HFuncSA(t *testing.T) {
 		t.Errorf("Unknown Notification Received")
 	}
 }
+
+// TestSubscribeTxEvents: The events of a committed Transaction are bracketed
+// by SEventTxBegin, and SEventTxEnd, with the Tx ID.
+func TestSubscribeTxEvents(t *testing.T) {
+	ts := &TableSpec{Name: SUB_TST + "_TX"}
+	keys := []Key{{Comp: []string{"key1"}}, {Comp: []string{"key2"}}}
+
+	type txEvent struct {
+		event SEvent
+		txID  string
+	}
+	evCh := make(chan txEvent, 10)
+
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(d, ts, t) })
+
+	s, e := SubscribeDB(Options{
+		DBNo:               ConfigDB,
+		TableNameSeparator: "|",
+		KeySeparator:       "|",
+	}, []*SKey{{Ts: ts, Key: &Key{Comp: []string{"*"}},
+		SEMap: map[SEvent]bool{SEventHSet: true, SEventTxBegin: true,
+			SEventTxEnd: true}}},
+		func(s *DB, skey *SKey, key *Key, event SEvent) error {
+			if event != SEventClose && event != SEventErr {
+				evCh <- txEvent{event, s.SubscribeTxID()}
+			}
+			return nil
+		})
+	if e != nil {
+		t.Fatalf("SubscribeDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { s.UnsubscribeDB() })
+
+	if e = d.StartTx(nil, []*TableSpec{ts}); e != nil {
+		t.Fatalf("StartTx() fails e: %v", e)
+	}
+	for _, key := range keys {
+		if e = d.SetEntry(ts, key, sE0); e != nil {
+			t.Fatalf("SetEntry() fails e: %v", e)
+		}
+	}
+	if e = d.CommitTx(); e != nil {
+		t.Fatalf("CommitTx() fails e: %v", e)
+	}
+
+	expEvents := []SEvent{SEventTxBegin, SEventHSet, SEventHSet, SEventTxEnd}
+	var txID string
+	for i, expEvent := range expEvents {
+		select {
+		case ev := <-evCh:
+			if i == 0 {
+				txID = ev.txID
+			}
+			if ev.event != expEvent || len(ev.txID) == 0 || ev.txID != txID {
+				t.Errorf("Event %d: Received %v, txID %q; Expecting %v, txID %q",
+					i, ev.event, ev.txID, expEvent, txID)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Event %d: Timed out waiting for %v", i, expEvent)
+		}
+	}
+
+	// Non-Transaction writes have no Tx ID.
+	if e = d.DeleteEntry(ts, keys[0]); e != nil {
+		t.Fatalf("DeleteEntry() fails e: %v", e)
+	}
+	if e = d.SetEntry(ts, keys[0], sE1); e != nil {
+		t.Fatalf("SetEntry() fails e: %v", e)
+	}
+	select {
+	case ev := <-evCh:
+		if ev.event != SEventHSet || len(ev.txID) != 0 {
+			t.Errorf("Received %v, txID %q; Expecting %v, no txID", ev.event,
+				ev.txID, SEventHSet)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for %v", SEventHSet)
+	}
+}
+
+func TestSubscribeResync(t *testing.T) {
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	defer cleanupSub(t, d, true)
+
+	events := make(chan SEvent, 100)
+	sdb, e := SubscribeDB(Options{
+		DBNo:               ConfigDB,
+		InitIndicator:      "",
+		TableNameSeparator: "|",
+		KeySeparator:       "|",
+	}, []*SKey{{Ts: sTs, Key: &Key{Comp: []string{"*"}},
+		SEMap: map[SEvent]bool{SEventHSet: true, SEventResync: true}}},
+		func(d *DB, skey *SKey, key *Key, event SEvent) error {
+			events <- event
+			return nil
+		})
+	if e != nil {
+		t.Fatalf("SubscribeDB() fails e: %v", e)
+	}
+	defer sdb.UnsubscribeDB()
+
+	waitEvent := func(exp SEvent) {
+		t.Helper()
+		for {
+			select {
+			case event := <-events:
+				if event == exp {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Timed out waiting for event %v", exp)
+			}
+		}
+	}
+
+	// Drop the subscription connection, as on a redis restart.
+	if e = d.client.Do(context.Background(), "CLIENT", "KILL", "TYPE", "pubsub").Err(); e != nil {
+		t.Fatalf("CLIENT KILL fails e: %v", e)
+	}
+	waitEvent(SEventResync)
+
+	// The keyspace notifications are received again.
+	if e = d.SetEntry(sTs, d.redis2key(sTs, sK), sE0); e != nil {
+		t.Fatalf("SetEntry() fails e: %v", e)
+	}
+	waitEvent(SEventHSet)
+}
@@ -0,0 +1,573 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+// Table (or Key prefix) scoped Read/Write Locks
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+	"github.com/redis/go-redis/v9"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// LockMode is the mode of a table lock.
+type LockMode int
+
+const (
+	ReadLock  LockMode = iota // Shared with other ReadLock holders
+	WriteLock                 // Exclusive
+)
+
+// ConfigDBLockScope is the Scope of the CONFIG_DB lock (ConfigDBTryLock) in
+// the TableLockHolder. It is a WriteLock on all the tables.
+const ConfigDBLockScope = "*"
+
+// TableLockSpec is a table, or key prefix scoped lock. Two locks overlap if
+// their scopes are the same, or one is a key prefix of the other. Eg: PORT
+// overlaps PORT|Ethernet0, but not PORTCHANNEL, or PORT|Ethernet04.
+type TableLockSpec struct {
+	Ts   *TableSpec
+	Key  *Key // Leading key components. nil, or empty: The whole table
+	Mode LockMode
+}
+
+// TableLocks is the set of table locks held by an owner. The locks are kept
+// in the STATE_DB, and hence are honored across processes.
+type TableLocks struct {
+	Id  string        // ID Unique to the executable (Eg: Session-Token, "0-0")
+	TTL time.Duration // Lease, renewed while locked. (0: No lease)
+
+	comm string              // Basename of the executable
+	held map[string]LockMode // scope to the LockMode held
+	stop chan struct{}       // Stops the lease renewal
+	mu   sync.Mutex
+}
+
+// TableLockHolder is the holder of a lock, as returned by
+// GetTableLockHolders().
+type TableLockHolder struct {
+	Scope string // Table, or Table|Key prefix. ConfigDBLockScope: CONFIG_DB
+	Mode  LockMode
+	Comm  string        // Basename of the executable of the owner
+	Id    string        // ID of the owner
+	Pid   int           // (0: Unknown)
+	Since time.Time     // (Zero: Unknown)
+	TTL   time.Duration // Lease (0: No lease)
+	Stale bool          // Lease expired. The lock can be taken over.
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+func (m LockMode) String() string {
+	if m == WriteLock {
+		return "write"
+	}
+	return "read"
+}
+
+// NewTableLocks returns an (empty) set of table locks of the owner id. If ttl
+// is not 0, the locks have a lease of ttl, which is renewed while they are
+// held; the locks of a crashed owner can then be taken over after the ttl.
+func NewTableLocks(id string, ttl time.Duration) *TableLocks {
+	return &TableLocks{Id: id, TTL: ttl, comm: execName,
+		held: make(map[string]LockMode)}
+}
+
+// TryLock acquires all the locks of the specs, or none of them. Locks which
+// are already held are upgraded (ReadLock to WriteLock) if required. It fails
+// with TranslibDBLock if any of them overlap a lock held by another owner,
+// or the CONFIG_DB lock.
+func (tl *TableLocks) TryLock(specs ...TableLockSpec) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+	return tl.tryLock(tl.toAcquire(specs))
+}
+
+// Lock is TryLock, retried until the timeout. To avoid deadlocks between the
+// owners waiting for each other's locks, the locks must be acquired in order,
+// i.e. the scopes of the specs must sort after the scopes already held. (A
+// single Lock call acquires all its specs atomically, in any order.)
+func (tl *TableLocks) Lock(timeout time.Duration, specs ...TableLockSpec) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	acquire := tl.toAcquire(specs)
+	if len(acquire) == 0 {
+		return nil
+	}
+
+	var maxHeld string
+	for scope := range tl.held {
+		if scope > maxHeld {
+			maxHeld = scope
+		}
+	}
+	if acquire[0].scope <= maxHeld {
+		err := tlerr.TranslibDBNotSupported{Description: "Lock " +
+			acquire[0].scope + " out of order; holding " + maxHeld}
+		glog.Errorf("TableLocks.Lock: %s: %v", tl.owner(), err)
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := tl.tryLock(acquire)
+		if _, ok := err.(tlerr.TranslibDBLock); !ok || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(tryLockPause * time.Millisecond)
+	}
+}
+
+// Unlock releases the locks of the specs (only the Ts, and Key are used), or
+// all the locks held, if no specs are given.
+func (tl *TableLocks) Unlock(specs ...TableLockSpec) error {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	scopes := make([]string, 0, len(tl.held))
+	if len(specs) == 0 {
+		for scope := range tl.held {
+			scopes = append(scopes, scope)
+		}
+	} else {
+		for _, spec := range specs {
+			if _, ok := tl.held[spec.scope()]; ok {
+				scopes = append(scopes, spec.scope())
+			}
+		}
+	}
+
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	client, err := getStateDB()
+	if err != nil {
+		return err
+	}
+	defer CloseRedisClient(client)
+
+	fields := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		fields = append(fields, tl.owner()+"|"+scope)
+		delete(tl.held, scope)
+	}
+
+	glog.V(3).Info("TableLocks.Unlock: RedisCmd: STATE_DB: HDEL ",
+		tableLocksKey, " ", fields)
+	if err = client.HDel(context.Background(), tableLocksKey,
+		fields...).Err(); err != nil {
+		glog.Errorf("TableLocks.Unlock: %s: %v", tl.owner(), err)
+	} else {
+		glog.Infof("TableLocks.Unlock: %s: %v", tl.owner(), scopes)
+	}
+
+	if len(tl.held) == 0 && tl.stop != nil {
+		close(tl.stop)
+		tl.stop = nil
+	}
+	return err
+}
+
+// Held returns the locks held, and their LockMode.
+func (tl *TableLocks) Held() map[string]LockMode {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	held := make(map[string]LockMode, len(tl.held))
+	for scope, mode := range tl.held {
+		held[scope] = mode
+	}
+	return held
+}
+
+// GetTableLockHolders returns the current holders of the table locks, and of
+// the CONFIG_DB lock (Scope ConfigDBLockScope), sorted by the Scope.
+func GetTableLockHolders() ([]TableLockHolder, error) {
+	client, err := getStateDB()
+	if err != nil {
+		return nil, err
+	}
+	defer CloseRedisClient(client)
+
+	ctx := context.Background()
+	var holders []TableLockHolder
+
+	// The CONFIG_DB lock
+	fields, err := client.HGetAll(ctx, lockTableKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := fields[configDBLock]; ok {
+		holder := TableLockHolder{Scope: ConfigDBLockScope, Mode: WriteLock}
+		holder.Comm, holder.Id, _ = strings.Cut(v, ":")
+		if lease, ok := fields[configDBLock+"|lease"]; ok {
+			ms, _ := strconv.ParseInt(lease, 10, 64)
+			holder.TTL = time.Duration(ms) * time.Millisecond
+			holder.Stale = client.Exists(ctx, leaseKeyPfx+configDBLock).Val() == 0
+		}
+		holders = append(holders, holder)
+	}
+
+	locks, err := client.HGetAll(ctx, tableLocksKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+	for field, v := range locks {
+		owner, scope, _ := strings.Cut(field, "|")
+		holder := parseTableLockHolder(scope, owner, v)
+		holder.Stale = !tableLockLive(v, now)
+		holders = append(holders, holder)
+	}
+
+	sort.Slice(holders, func(i, j int) bool {
+		if holders[i].Scope != holders[j].Scope {
+			return holders[i].Scope < holders[j].Scope
+		}
+		return holders[i].Comm+":"+holders[i].Id < holders[j].Comm+":"+holders[j].Id
+	})
+
+	return holders, nil
+}
+
+// ClearTableLocks clears the table locks of the owner id of the executable,
+// including the ones held by its previous instance (Eg: of a restored Config
+// Session, whose commit was pending confirmation), ignoring their leases.
+func ClearTableLocks(id string) error {
+	glog.Info("ClearTableLocks: ", id)
+
+	client, err := getStateDB()
+	if err != nil {
+		return err
+	}
+	defer CloseRedisClient(client)
+
+	ctx := context.Background()
+	fields, err := client.HKeys(ctx, tableLocksKey).Result()
+	if err != nil {
+		return err
+	}
+
+	owner := execName + ":" + id + "|"
+	var clear []string
+	for _, field := range fields {
+		if strings.HasPrefix(field, owner) {
+			clear = append(clear, field)
+		}
+	}
+	if len(clear) == 0 {
+		return nil
+	}
+
+	glog.V(3).Info("ClearTableLocks: RedisCmd: STATE_DB: HDEL ",
+		tableLocksKey, " ", clear)
+	return client.HDel(ctx, tableLocksKey, clear...).Err()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+const (
+	// The table locks are in the "LOCK|translib|tables" hash, with a field per
+	// holder, and scope: "<comm>:<id>|<scope>" =
+	// "<r|w>|<ttl ms>|<since unix ms>|<pid>|<lease expiry unix ms>". The
+	// expiry of a holder without a lease is 0. (A single hash, so that the Lua
+	// Scripts only access the keys passed to them)
+	tableLocksKey string = lockTableKey + "|tables"
+)
+
+type tableLockAcquire struct {
+	scope string
+	mode  LockMode
+}
+
+func (spec TableLockSpec) scope() string {
+	if spec.Key == nil || len(spec.Key.Comp) == 0 {
+		return spec.Ts.Name
+	}
+	return spec.Ts.Name + "|" + strings.Join(spec.Key.Comp, "|")
+}
+
+func (tl *TableLocks) owner() string {
+	return tl.comm + ":" + tl.Id
+}
+
+// toAcquire returns the (deduplicated) locks of the specs to be acquired,
+// which are not already held in the mode, sorted by scope.
+func (tl *TableLocks) toAcquire(specs []TableLockSpec) []tableLockAcquire {
+	modes := make(map[string]LockMode, len(specs))
+	for _, spec := range specs {
+		scope := spec.scope()
+		if mode, ok := modes[scope]; !ok || spec.Mode > mode {
+			modes[scope] = spec.Mode
+		}
+	}
+
+	acquire := make([]tableLockAcquire, 0, len(modes))
+	for scope, mode := range modes {
+		if held, ok := tl.held[scope]; !ok || mode > held {
+			acquire = append(acquire, tableLockAcquire{scope: scope, mode: mode})
+		}
+	}
+	sort.Slice(acquire, func(i, j int) bool {
+		return acquire[i].scope < acquire[j].scope
+	})
+	return acquire
+}
+
+// tryLock runs the Lua Script to acquire the locks. Caller holds tl.mu.
+func (tl *TableLocks) tryLock(acquire []tableLockAcquire) error {
+	if len(acquire) == 0 {
+		return nil
+	}
+
+	client, err := getStateDB()
+	if err != nil {
+		return err
+	}
+	defer CloseRedisClient(client)
+
+	now := time.Now().UnixMilli()
+	keys := []string{lockTableKey, leaseKeyPfx + configDBLock, tableLocksKey}
+	args := make([]interface{}, 0, 5+2*len(acquire))
+	args = append(args, tl.owner(), tl.TTL.Milliseconds(),
+		strconv.FormatInt(now, 10)+"|"+strconv.Itoa(os.Getpid())+"|"+
+			strconv.FormatInt(tl.expiry(now), 10),
+		now, configDBLock)
+	for _, a := range acquire {
+		mode := "r"
+		if a.mode == WriteLock {
+			mode = "w"
+		}
+		args = append(args, mode, a.scope)
+	}
+
+	glog.V(3).Info("TableLocks.tryLock: RedisCmd: STATE_DB: ", keys, args)
+	reply, err := luaScriptTableTryLock.Run(context.Background(), client,
+		keys, args...).Result()
+	if err != nil {
+		glog.Errorf("TableLocks.tryLock: %s: %v", tl.owner(), err)
+		return err
+	}
+
+	if conflict, ok := reply.([]interface{}); ok && len(conflict) == 2 {
+		scope, _ := conflict[0].(string)
+		holder, _ := conflict[1].(string)
+		glog.Infof("TableLocks.tryLock: %s: %v: Locked by %s on %s",
+			tl.owner(), acquire, holder, scope)
+		lockType := tlerr.DBLockGeneric
+		if _, id, _ := strings.Cut(holder, ":"); len(id) != 0 && id != noSessionToken {
+			lockType = tlerr.DBLockConfigSession
+		}
+		return tlerr.TranslibDBLock{Type: lockType}
+	}
+
+	for _, a := range acquire {
+		tl.held[a.scope] = a.mode
+	}
+	glog.Infof("TableLocks.tryLock: Locked: %s: %v TTL: %v", tl.owner(),
+		acquire, tl.TTL)
+
+	if tl.TTL > 0 && tl.stop == nil {
+		tl.stop = make(chan struct{})
+		go tl.renewLease(tl.stop)
+	}
+	return nil
+}
+
+// renewLease extends the leases of the locks held every TTL/3, until
+// stopped.
+func (tl *TableLocks) renewLease(stop chan struct{}) {
+	ticker := time.NewTicker(tl.TTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		tl.mu.Lock()
+		args := make([]interface{}, 0, 2+len(tl.held))
+		args = append(args, tl.owner(), tl.expiry(time.Now().UnixMilli()))
+		for scope := range tl.held {
+			args = append(args, scope)
+		}
+		held := len(tl.held)
+		tl.mu.Unlock()
+
+		client, err := getStateDB()
+		if err != nil {
+			continue
+		}
+
+		reply, err := luaScriptTableRenewLease.Run(context.Background(), client,
+			[]string{tableLocksKey}, args...).Result()
+		CloseRedisClient(client)
+
+		if err != nil {
+			glog.Warningf("TableLocks.renewLease: %s: %v", tl.owner(), err)
+		} else if n, ok := reply.(int64); ok && int(n) != held {
+			glog.Errorf("TableLocks.renewLease: %s: %d of %d locks lost",
+				tl.owner(), held-int(n), held)
+		}
+	}
+}
+
+// expiry returns the expiry (unix ms) of the lease renewed at now, or 0 if
+// the locks have no lease.
+func (tl *TableLocks) expiry(now int64) int64 {
+	if tl.TTL <= 0 {
+		return 0
+	}
+	return now + tl.TTL.Milliseconds()
+}
+
+// tableLockLive returns whether the holder with the value v is live at now,
+// i.e. it has no lease, or the lease has not expired. (See luaTableLockLive)
+func tableLockLive(v string, now int64) bool {
+	i := strings.LastIndexByte(v, '|')
+	if i < 0 {
+		return true
+	}
+	exp, err := strconv.ParseInt(v[i+1:], 10, 64)
+	return err != nil || exp == 0 || exp > now
+}
+
+func parseTableLockHolder(scope, owner, v string) TableLockHolder {
+	holder := TableLockHolder{Scope: scope}
+	holder.Comm, holder.Id, _ = strings.Cut(owner, ":")
+
+	parts := strings.SplitN(v, "|", 5)
+	if parts[0] == "w" {
+		holder.Mode = WriteLock
+	}
+	if len(parts) > 1 {
+		ms, _ := strconv.ParseInt(parts[1], 10, 64)
+		holder.TTL = time.Duration(ms) * time.Millisecond
+	}
+	if len(parts) > 2 {
+		if ms, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+			holder.Since = time.UnixMilli(ms)
+		}
+	}
+	if len(parts) > 3 {
+		holder.Pid, _ = strconv.Atoi(parts[3])
+	}
+	return holder
+}
+
+var luaScriptTableTryLock *redis.Script
+var luaScriptTableRenewLease *redis.Script
+
+// luaTableLockLive is the Lua function which returns whether the holder with
+// the value v is live at now (unix ms), i.e. it has no lease, or the lease
+// has not expired.
+const luaTableLockLive = `
+	local function live(v, now)
+		local exp = tonumber(string.match(v, "|(%d+)$"))
+		return (not exp) or (exp == 0) or (exp > now)
+	end
+`
+
+func init() {
+
+	// Register the Lua Script to acquire the table locks. KEYS[1], KEYS[2]
+	// are the CONFIG_DB lock hash, and its lease key; KEYS[3] is the table
+	// locks hash. ARGV[1] is the owner, ARGV[2] the ttl ms, ARGV[3]
+	// "<since>|<pid>|<expiry>", ARGV[4] now (unix ms), ARGV[5] the name of the
+	// CONFIG_DB lock, followed by the mode ("r"|"w"), and scope pairs.
+	// Returns 1 if all are acquired, else the {scope, holder} of the
+	// conflicting lock. Stale holders are removed.
+	luaScriptTableTryLock = redis.NewScript(luaTableLockLive + `
+		local owner, now, cdb = ARGV[1], tonumber(ARGV[4]), ARGV[5]
+
+		-- The CONFIG_DB lock is a write lock on all the tables.
+		local g = redis.call("HGET", KEYS[1], cdb)
+		if g and g ~= owner then
+			if (redis.call("HEXISTS", KEYS[1], cdb .. "|lease") == 0) or
+					(redis.call("EXISTS", KEYS[2]) == 1) then
+				return {"*", g}
+			end
+		end
+
+		local function overlaps(a, b)
+			return (a == b) or (string.sub(b, 1, #a + 1) == a .. "|") or
+				(string.sub(a, 1, #b + 1) == b .. "|")
+		end
+
+		local locks = redis.call("HGETALL", KEYS[3])
+		for i = 6, #ARGV, 2 do
+			local mode, scope = ARGV[i], ARGV[i + 1]
+			for j = 1, #locks, 2 do
+				local h, s = string.match(locks[j], "^([^|]*)|(.*)$")
+				if h and (h ~= owner) and overlaps(s, scope) and
+						((mode == "w") or (string.sub(locks[j + 1], 1, 1) == "w")) then
+					if live(locks[j + 1], now) then
+						return {s, h}
+					end
+					redis.call("HDEL", KEYS[3], locks[j])
+				end
+			end
+		end
+
+		for i = 6, #ARGV, 2 do
+			redis.call("HSET", KEYS[3], owner .. "|" .. ARGV[i + 1],
+				ARGV[i] .. "|" .. ARGV[2] .. "|" .. ARGV[3])
+		end
+		return 1
+	`)
+
+	// Register the Lua Script to renew the leases of the table locks, still
+	// held. KEYS[1] is the table locks hash. ARGV[1] is the owner, ARGV[2]
+	// the new expiry (unix ms), followed by the scopes. Returns the # of
+	// locks still held.
+	luaScriptTableRenewLease = redis.NewScript(`
+		local n = 0
+		for i = 3, #ARGV do
+			local f = ARGV[1] .. "|" .. ARGV[i]
+			local v = redis.call("HGET", KEYS[1], f)
+			if v then
+				redis.call("HSET", KEYS[1], f,
+					(string.gsub(v, "|%d+$", "|" .. ARGV[2], 1)))
+				n = n + 1
+			end
+		end
+		return n
+	`)
+}
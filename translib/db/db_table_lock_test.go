@@ -0,0 +1,191 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+)
+
+var tlTs *TableSpec = &TableSpec{Name: "TEST_TABLE_LOCK"}
+var tlTs2 *TableSpec = &TableSpec{Name: "TEST_TABLE_LOCK_2"}
+
+func newTestTableLocks(t *testing.T, id string, ttl time.Duration) *TableLocks {
+	tl := NewTableLocks(id, ttl)
+	t.Cleanup(func() { tl.Unlock() })
+	return tl
+}
+
+func tlSpec(ts *TableSpec, mode LockMode, comp ...string) TableLockSpec {
+	spec := TableLockSpec{Ts: ts, Mode: mode}
+	if len(comp) != 0 {
+		spec.Key = &Key{Comp: comp}
+	}
+	return spec
+}
+
+func expectTableLocked(t *testing.T, err error, expType tlerr.DBLockType) {
+	t.Helper()
+	if e, ok := err.(tlerr.TranslibDBLock); !ok || e.Type != expType {
+		t.Errorf("Expecting %#v: Received %#v", tlerr.TranslibDBLock{Type: expType}, err)
+	}
+}
+
+// TestTableLockShared: ReadLocks are shared, WriteLocks are exclusive.
+func TestTableLockShared(t *testing.T) {
+	r1 := newTestTableLocks(t, "r1", 0)
+	r2 := newTestTableLocks(t, "r2", 0)
+	w := newTestTableLocks(t, noSessionToken, 0)
+
+	if err := r1.TryLock(tlSpec(tlTs, ReadLock)); err != nil {
+		t.Fatalf("r1.TryLock() fails: %v", err)
+	}
+	if err := r2.TryLock(tlSpec(tlTs, ReadLock)); err != nil {
+		t.Fatalf("r2.TryLock() fails: %v", err)
+	}
+	expectTableLocked(t, w.TryLock(tlSpec(tlTs, WriteLock)), tlerr.DBLockConfigSession)
+
+	r1.Unlock()
+	r2.Unlock()
+	if err := w.TryLock(tlSpec(tlTs, WriteLock)); err != nil {
+		t.Fatalf("w.TryLock() fails: %v", err)
+	}
+	expectTableLocked(t, r1.TryLock(tlSpec(tlTs, ReadLock)), tlerr.DBLockGeneric)
+}
+
+// TestTableLockPrefix: Key prefix scoped locks overlap their table, and
+// longer prefixes, only.
+func TestTableLockPrefix(t *testing.T) {
+	a := newTestTableLocks(t, "a", 0)
+	b := newTestTableLocks(t, "b", 0)
+
+	if err := a.TryLock(tlSpec(tlTs, WriteLock, "Ethernet0")); err != nil {
+		t.Fatalf("a.TryLock() fails: %v", err)
+	}
+	if err := b.TryLock(tlSpec(tlTs, WriteLock, "Ethernet04"),
+		tlSpec(tlTs2, WriteLock)); err != nil {
+		t.Fatalf("b.TryLock(non overlapping) fails: %v", err)
+	}
+	expectTableLocked(t, b.TryLock(tlSpec(tlTs, ReadLock)), tlerr.DBLockConfigSession)
+	expectTableLocked(t, b.TryLock(tlSpec(tlTs, ReadLock, "Ethernet0", "0")),
+		tlerr.DBLockConfigSession)
+
+	// All or nothing
+	expectTableLocked(t, a.TryLock(tlSpec(tlTs, ReadLock, "Ethernet1"),
+		tlSpec(tlTs2, ReadLock)), tlerr.DBLockConfigSession)
+	if held := a.Held(); len(held) != 1 {
+		t.Errorf("a.Held() = %v; expecting only %s|Ethernet0", held, tlTs.Name)
+	}
+}
+
+// TestTableLockOrder: Lock() must acquire the locks in order.
+func TestTableLockOrder(t *testing.T) {
+	tl := newTestTableLocks(t, "order", 0)
+
+	if err := tl.Lock(time.Second, tlSpec(tlTs2, WriteLock)); err != nil {
+		t.Fatalf("Lock(%s) fails: %v", tlTs2.Name, err)
+	}
+	if err := tl.Lock(time.Second, tlSpec(tlTs, WriteLock)); err == nil {
+		t.Errorf("Lock(%s) after %s succeeds", tlTs.Name, tlTs2.Name)
+	} else if _, ok := err.(tlerr.TranslibDBNotSupported); !ok {
+		t.Errorf("Lock(%s) after %s: Unexpected error %v", tlTs.Name, tlTs2.Name, err)
+	}
+	if err := tl.TryLock(tlSpec(tlTs, WriteLock)); err != nil {
+		t.Errorf("TryLock(%s) after %s fails: %v", tlTs.Name, tlTs2.Name, err)
+	}
+}
+
+// TestTableLockWait: Lock() waits for the conflicting lock to be released.
+func TestTableLockWait(t *testing.T) {
+	a := newTestTableLocks(t, "a", 0)
+	b := newTestTableLocks(t, "b", 0)
+
+	if err := a.TryLock(tlSpec(tlTs, WriteLock)); err != nil {
+		t.Fatalf("a.TryLock() fails: %v", err)
+	}
+	time.AfterFunc(300*time.Millisecond, func() { a.Unlock() })
+	if err := b.Lock(3*time.Second, tlSpec(tlTs, WriteLock)); err != nil {
+		t.Errorf("b.Lock() fails: %v", err)
+	}
+}
+
+// TestTableLockConfigDB: The CONFIG_DB lock, and the table locks exclude each
+// other.
+func TestTableLockConfigDB(t *testing.T) {
+	t.Cleanup(func() { ConfigDBUnlock(noSessionToken) })
+	tl := newTestTableLocks(t, testSTok, 0)
+
+	if err := tl.TryLock(tlSpec(tlTs, ReadLock)); err != nil {
+		t.Fatalf("TryLock() fails: %v", err)
+	}
+	if err := ConfigDBTryLock(noSessionToken); err == nil {
+		t.Fatalf("ConfigDBTryLock() succeeds with a table lock held")
+	}
+
+	tl.Unlock()
+	if err := ConfigDBTryLock(noSessionToken); err != nil {
+		t.Fatalf("ConfigDBTryLock() fails: %v", err)
+	}
+	expectTableLocked(t, tl.TryLock(tlSpec(tlTs2, ReadLock)), tlerr.DBLockGeneric)
+}
+
+// TestTableLockLease: A lock with an expired lease is taken over.
+func TestTableLockLease(t *testing.T) {
+	a := newTestTableLocks(t, "a", 0)
+	b := newTestTableLocks(t, "b", 0)
+
+	a.TTL = 500 * time.Millisecond
+	if err := a.TryLock(tlSpec(tlTs, WriteLock)); err != nil {
+		t.Fatalf("a.TryLock() fails: %v", err)
+	}
+	time.Sleep(time.Second)
+	expectTableLocked(t, b.TryLock(tlSpec(tlTs, WriteLock)), tlerr.DBLockConfigSession)
+
+	// Simulate a crash of a, by stopping the lease renewal.
+	a.mu.Lock()
+	close(a.stop)
+	a.stop = nil
+	a.mu.Unlock()
+
+	holders, err := GetTableLockHolders()
+	if err != nil {
+		t.Fatalf("GetTableLockHolders() fails: %v", err)
+	}
+	found := false
+	for _, h := range holders {
+		if h.Scope == tlTs.Name && h.Id == "a" {
+			found = true
+			if h.Mode != WriteLock || h.TTL != a.TTL || h.Comm != execName ||
+				h.Since.IsZero() || h.Pid == 0 {
+				t.Errorf("Unexpected holder %+v", h)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("GetTableLockHolders() = %+v; missing a", holders)
+	}
+
+	time.Sleep(time.Second)
+	if err := b.TryLock(tlSpec(tlTs, WriteLock)); err != nil {
+		t.Errorf("b.TryLock() of stale lock fails: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// RequestTrace collects the statistics of a single northbound request. The
+// DBs opened with Options.Trace collect time and table stats, irrespective
+// of the TRANSLIB_DB|default stats config, and add them to the RequestTrace
+// on DeleteDB().
+type RequestTrace struct {
+	mu sync.Mutex
+
+	DBs   map[string]*DBStats   `json:"dbs,omitempty"`  // By DB name
+	CVL   TimeStats             `json:"cvl"`            // ValidateEditConfig()
+	Xfmr  map[string]*TimeStats `json:"xfmr,omitempty"` // By callback name
+	Prune TimeStats             `json:"prune"`          // Query Parameter prune
+	Time  time.Duration         `json:"total-time"`     // Of the request
+}
+
+// TimeStats are the number of calls, and the time taken.
+type TimeStats struct {
+	Hits uint          `json:"hits"`
+	Time time.Duration `json:"total-time"`
+	Peak time.Duration `json:"peak-time"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// NewRequestTrace returns an empty RequestTrace.
+func NewRequestTrace() *RequestTrace {
+	return &RequestTrace{
+		DBs:  make(map[string]*DBStats),
+		Xfmr: make(map[string]*TimeStats),
+	}
+}
+
+// WithRequestTrace returns a copy of the ctx carrying the RequestTrace.
+func WithRequestTrace(ctx context.Context, rt *RequestTrace) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, requestTraceKey{}, rt)
+}
+
+// RequestTraceFromContext returns the RequestTrace carried by the ctx, or nil.
+func RequestTraceFromContext(ctx context.Context) *RequestTrace {
+	if ctx == nil {
+		return nil
+	}
+	rt, _ := ctx.Value(requestTraceKey{}).(*RequestTrace)
+	return rt
+}
+
+// Trace returns the RequestTrace of the DB (Options.Trace), or nil.
+func (d *DB) Trace() *RequestTrace {
+	if d == nil || d.Opts == nil {
+		return nil
+	}
+	return d.Opts.Trace
+}
+
+// AddXfmr adds the time taken by a transformer callback.
+func (rt *RequestTrace) AddXfmr(name string, dur time.Duration) {
+	if rt == nil {
+		return
+	}
+	rt.mu.Lock()
+	ts, ok := rt.Xfmr[name]
+	if !ok {
+		ts = &TimeStats{}
+		rt.Xfmr[name] = ts
+	}
+	ts.add(dur)
+	rt.mu.Unlock()
+}
+
+// AddPrune adds the time taken by a query parameter prune.
+func (rt *RequestTrace) AddPrune(dur time.Duration) {
+	if rt == nil {
+		return
+	}
+	rt.mu.Lock()
+	rt.Prune.add(dur)
+	rt.mu.Unlock()
+}
+
+// Done records the total time of the request, which started at the start.
+func (rt *RequestTrace) Done(start time.Time) {
+	if rt == nil {
+		return
+	}
+	rt.mu.Lock()
+	rt.Time = time.Since(start)
+	rt.mu.Unlock()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+type requestTraceKey struct{}
+
+func (rt *RequestTrace) addCVL(dur time.Duration) {
+	if rt == nil {
+		return
+	}
+	rt.mu.Lock()
+	rt.CVL.add(dur)
+	rt.mu.Unlock()
+}
+
+func (rt *RequestTrace) addDBStats(name string, connStats *DBStats) {
+	if rt == nil || connStats.Empty() {
+		return
+	}
+	rt.mu.Lock()
+	dbStats, ok := rt.DBs[name]
+	if !ok {
+		dbStats = &DBStats{Name: name}
+		rt.DBs[name] = dbStats
+	}
+	dbStats.updateStats(connStats)
+	rt.mu.Unlock()
+}
+
+func (ts *TimeStats) add(dur time.Duration) {
+	ts.Hits++
+	ts.Time += dur
+	if dur > ts.Peak {
+		ts.Peak = dur
+	}
+}
@@ -0,0 +1,101 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+var TRACE_PF string = "DBTRACE_TST_" + strconv.FormatInt(int64(os.Getpid()), 10)
+
+func TestRequestTraceDBStats(t *testing.T) {
+	ts := &TableSpec{Name: TRACE_PF}
+	key := Key{Comp: []string{"KEY1"}}
+
+	wd, e := newDB(StateDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(wd, ts, t) })
+	if e = wd.SetEntry(ts, key, Value{Field: map[string]string{"f": "v"}}); e != nil {
+		t.Fatalf("SetEntry() fails e: %v", e)
+	}
+
+	rt := NewRequestTrace()
+	d, e := NewDB(Options{
+		DBNo:               StateDB,
+		TableNameSeparator: "|",
+		KeySeparator:       "|",
+		IsWriteDisabled:    true,
+		Trace:              rt,
+	})
+	if e != nil {
+		t.Fatalf("NewDB() fails e: %v", e)
+	}
+	for i := 0; i < 3; i++ {
+		if _, e = d.GetEntry(ts, key); e != nil {
+			t.Errorf("GetEntry() fails e: %v", e)
+		}
+	}
+	d.DeleteDB()
+
+	dbStats := rt.DBs[StateDB.Name()]
+	if dbStats == nil {
+		t.Fatalf("No %v stats in the RequestTrace: %+v", StateDB.Name(), rt.DBs)
+	}
+	if hits := dbStats.Tables[TRACE_PF].GetEntryHits; hits != 3 {
+		t.Errorf("GetEntryHits = %v; expected 3", hits)
+	}
+	if dbStats.Tables[TRACE_PF].Time == 0 {
+		t.Errorf("No time stats in the RequestTrace")
+	}
+}
+
+func TestRequestTraceContext(t *testing.T) {
+	if RequestTraceFromContext(context.Background()) != nil {
+		t.Errorf("RequestTraceFromContext() of empty context is not nil")
+	}
+
+	rt := NewRequestTrace()
+	ctx := WithRequestTrace(nil, rt)
+	if RequestTraceFromContext(ctx) != rt {
+		t.Fatalf("RequestTraceFromContext() does not return the RequestTrace")
+	}
+
+	rt.AddXfmr("YangToDb_test_xfmr", 2*time.Millisecond)
+	rt.AddXfmr("YangToDb_test_xfmr", 5*time.Millisecond)
+	rt.AddPrune(time.Millisecond)
+	if xs := rt.Xfmr["YangToDb_test_xfmr"]; xs == nil || xs.Hits != 2 ||
+		xs.Time != 7*time.Millisecond || xs.Peak != 5*time.Millisecond {
+		t.Errorf("Unexpected Xfmr stats: %+v", xs)
+	}
+	if rt.Prune.Hits != 1 {
+		t.Errorf("Unexpected Prune stats: %+v", rt.Prune)
+	}
+
+	// nil RequestTrace is a no-op
+	var nrt *RequestTrace
+	nrt.AddXfmr("x", time.Second)
+	nrt.AddPrune(time.Second)
+}
@@ -0,0 +1,254 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/golang/glog"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// SetEntryWithTTL sets an entry(row) in the table, like SetEntry(), and the
+// entry is deleted by redis after the ttl. Subscribers are notified of the
+// deletion with SEventExpired. Within a Transaction, the ttl starts on
+// CommitTx().
+func (d *DB) SetEntryWithTTL(ts *TableSpec, key Key, value Value, ttl time.Duration) error {
+	if glog.V(3) {
+		glog.Info("SetEntryWithTTL: Begin: ", d.Name(), ": ts: ", ts, " key: ",
+			key, " ttl: ", ttl)
+	}
+
+	if !d.IsOpen() {
+		return ConnectionClosed
+	}
+
+	if ttl <= 0 {
+		glog.Error("SetEntryWithTTL: Invalid ttl: ", ttl)
+		return tlerr.TranslibDBNotSupported{
+			Description: "SetEntryWithTTL: ttl must be positive"}
+	}
+
+	var e error
+	if d.txState == txStateNone && len(value.Field) != 0 {
+		e = d.setEntryWithTTLNoTx(ts, key, value, ttl)
+	} else {
+		e = d.setEntry(ts, key, value, false)
+		if e == nil && len(value.Field) != 0 {
+			e = d.Expire(ts, key, ttl)
+		}
+	}
+
+	if glog.V(3) {
+		glog.Info("SetEntryWithTTL: End: e: ", e)
+	}
+
+	return e
+}
+
+// setEntryWithTTLNoTx is SetEntryWithTTL() outside a Transaction. The HMSET,
+// HDEL (of the fields not in the value), and PEXPIRE are sent in a single
+// MULTI/EXEC, so that the entry is never left without its ttl.
+func (d *DB) setEntryWithTTLNoTx(ts *TableSpec, key Key, value Value, ttl time.Duration) error {
+	if e := d.checkWrite(); e != nil {
+		return e
+	}
+
+	redisKey := d.key2redis(ts, key)
+
+	var hdel []string
+	if cur, e := d.GetEntry(ts, key); e == nil {
+		for k := range cur.Field {
+			if _, ok := value.Field[k]; !ok {
+				hdel = append(hdel, k)
+			}
+		}
+	}
+
+	vintf := make(map[string]interface{}, len(value.Field))
+	for k, v := range value.Field {
+		vintf[k] = v
+	}
+
+	glog.Info("SetEntryWithTTL: RedisCmd: ", d.Name(), ": MULTI HMSET ",
+		redisKey, " ", value.Field, " HDEL ", hdel, " PEXPIRE ", ttl, " EXEC")
+
+	ctx := context.Background()
+	pipe := d.client.TxPipeline()
+	pipe.HMSet(ctx, redisKey, vintf)
+	if len(hdel) != 0 {
+		pipe.HDel(ctx, redisKey, hdel...)
+	}
+	pipe.PExpire(ctx, redisKey, ttl)
+	_, e := pipe.Exec(ctx)
+	if e != nil {
+		glog.Error("SetEntryWithTTL: ", d.Name(), ": EXEC: ", redisKey, " e: ", e)
+	}
+
+	d.invalidateGlobalCache(ts, key)
+
+	// Only update the config-timestamp, as in doWrite()
+	if d.Opts.DBNo == ConfigDB && e == nil {
+		d.markConfigDBUpdated()
+	}
+
+	return e
+}
+
+// Expire sets the ttl of an existing entry(row) in the table, after which it
+// is deleted by redis. A ttl of 0 removes the ttl, and the entry persists.
+// Within a Transaction, the ttl starts on CommitTx().
+func (d *DB) Expire(ts *TableSpec, key Key, ttl time.Duration) error {
+	var e error
+	var ok bool
+
+	if glog.V(3) {
+		glog.Info("Expire: Begin: ", d.Name(), ": ts: ", ts, " key: ", key,
+			" ttl: ", ttl)
+	}
+
+	if !d.IsOpen() {
+		return ConnectionClosed
+	}
+
+	if ttl < 0 {
+		glog.Error("Expire: Invalid ttl: ", ttl)
+		e = tlerr.TranslibDBNotSupported{Description: "Expire: ttl is negative"}
+		goto ExpireExit
+	}
+
+	if e = d.checkWrite(); e != nil {
+		goto ExpireExit
+	}
+
+	switch d.txState {
+	case txStateNone:
+		if glog.V(3) {
+			glog.Info("Expire: No Transaction.")
+		}
+	case txStateWatch:
+		if glog.V(2) {
+			glog.Info("Expire: Change to txStateSet, txState: ", d.txState)
+		}
+		d.txState = txStateSet
+	case txStateSet:
+		if glog.V(5) {
+			glog.Info("Expire: Remain in txStateSet, txState: ", d.txState)
+		}
+	default:
+		glog.Error("Expire: Incorrect State, txState: ", d.txState)
+		e = errors.New("Cannot issue Expire in txState " + string(rune(d.txState)))
+	}
+
+	if e != nil {
+		goto ExpireExit
+	}
+
+	// No Transaction case.
+	if d.txState == txStateNone {
+		redisKey := d.key2redis(ts, key)
+		glog.Info("Expire: RedisCmd: ", d.Name(), ": PEXPIRE ", redisKey, " ", ttl)
+
+		if ttl > 0 {
+			ok, e = d.client.PExpire(context.Background(), redisKey, ttl).Result()
+		} else {
+			// PERSIST is false if there is no ttl as well, so check existence
+			if _, e = d.client.Persist(context.Background(), redisKey).Result(); e == nil {
+				var n int64
+				n, e = d.client.Exists(context.Background(), redisKey).Result()
+				ok = (n != 0)
+			}
+		}
+
+		if e == nil && !ok {
+			e = tlerr.TranslibRedisClientEntryNotExist{Entry: redisKey}
+		}
+		goto ExpireExit
+	}
+
+	// Transaction case. The entry should exist in the Transaction's view.
+	if _, e = d.GetEntry(ts, key); e != nil {
+		goto ExpireExit
+	}
+
+	// Record the entry, as read before the first write, for the
+	// CheckTxConflicts(). (See doWrite)
+	if e = d.txReadEntry(ts, key); e != nil {
+		goto ExpireExit
+	}
+
+	if d.Opts.IsSession && (d.Opts.TxCmdsLim != 0) &&
+		(len(d.txCmds) >= d.Opts.TxCmdsLim) {
+
+		glog.Infof("Expire: TxCmdsLim exceeded %d >= %d", len(d.txCmds),
+			d.Opts.TxCmdsLim)
+		e = tlerr.TranslibDBTxCmdsLim{}
+		goto ExpireExit
+	}
+
+	key = key.Copy()
+	d.txCmds = append(d.txCmds, _txCmd{
+		ts:  ts,
+		op:  txOpExpire,
+		key: &key,
+		ttl: ttl,
+	})
+	d.stats.AllTables.TxCmdsLen = uint(len(d.txCmds))
+
+ExpireExit:
+
+	if glog.V(3) {
+		glog.Info("Expire: End: e: ", e)
+	}
+
+	return e
+}
+
+// TTL returns the remaining time to live of an entry(row) in the table. It is
+// 0 if the entry does not have a ttl. Returns error if the entry does not
+// exist. Uncommitted Expire() in the Transaction is not considered.
+func (d *DB) TTL(ts *TableSpec, key Key) (time.Duration, error) {
+	if !d.IsOpen() {
+		return 0, ConnectionClosed
+	}
+
+	redisKey := d.key2redis(ts, key)
+	ttl, e := d.client.PTTL(context.Background(), redisKey).Result()
+	if e != nil {
+		glog.Error("TTL: ", d.Name(), ": PTTL ", redisKey, " e: ", e)
+		return 0, e
+	}
+
+	// PTTL returns -2 if the key does not exist, and -1 if it has no ttl.
+	switch {
+	case ttl == -2:
+		return 0, tlerr.TranslibRedisClientEntryNotExist{Entry: redisKey}
+	case ttl < 0:
+		return 0, nil
+	}
+
+	return ttl, nil
+}
@@ -0,0 +1,159 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+)
+
+var TTL_PF string = "DBTTL_TST_" + strconv.FormatInt(int64(os.Getpid()), 10)
+
+func TestSetEntryWithTTL(t *testing.T) {
+	ts := &TableSpec{Name: TTL_PF}
+	key := Key{Comp: []string{"KEY1"}}
+	value := Value{Field: map[string]string{"status": "pending"}}
+
+	d, e := newDB(StateDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(d, ts, t) })
+
+	if e = d.SetEntry(ts, key, Value{Field: map[string]string{"old": "1"}}); e != nil {
+		t.Fatalf("SetEntry() fails e: %v", e)
+	}
+	if e = d.SetEntryWithTTL(ts, key, value, time.Minute); e != nil {
+		t.Fatalf("SetEntryWithTTL() fails e: %v", e)
+	}
+	if ttl, e := d.TTL(ts, key); e != nil || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL() = %v, %v; expected (0, 1m]", ttl, e)
+	}
+	if v, e := d.GetEntry(ts, key); e != nil || !v.Equals(&value) {
+		t.Errorf("GetEntry() = %v, %v; expected %v", v, e, value)
+	}
+
+	// Remove the ttl
+	if e = d.Expire(ts, key, 0); e != nil {
+		t.Errorf("Expire(0) fails e: %v", e)
+	}
+	if ttl, e := d.TTL(ts, key); e != nil || ttl != 0 {
+		t.Errorf("TTL() after Expire(0) = %v, %v; expected 0", ttl, e)
+	}
+
+	// Short ttl, the entry should be gone.
+	if e = d.Expire(ts, key, 200*time.Millisecond); e != nil {
+		t.Errorf("Expire(200ms) fails e: %v", e)
+	}
+	time.Sleep(time.Second)
+	if _, e = d.GetEntry(ts, key); e == nil {
+		t.Errorf("GetEntry() succeeds after expiry")
+	}
+
+	// Non existing entry
+	if _, ok := d.Expire(ts, key, time.Minute).(tlerr.TranslibRedisClientEntryNotExist); !ok {
+		t.Errorf("Expire() on missing entry: Expecting TranslibRedisClientEntryNotExist")
+	}
+	if _, e = d.TTL(ts, key); e == nil {
+		t.Errorf("TTL() on missing entry succeeds")
+	}
+
+	if e = d.SetEntryWithTTL(ts, key, value, 0); e == nil {
+		t.Errorf("SetEntryWithTTL() with 0 ttl succeeds")
+	}
+}
+
+func TestSetEntryWithTTLTx(t *testing.T) {
+	ts := &TableSpec{Name: TTL_PF + "_TX"}
+	key := Key{Comp: []string{"KEY1"}}
+	value := Value{Field: map[string]string{"status": "pending"}}
+
+	d, e := newDB(ConfigDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(d, ts, t) })
+
+	if e = d.StartTx(nil, []*TableSpec{ts}); e != nil {
+		t.Fatalf("StartTx() fails e: %v", e)
+	}
+	if e = d.SetEntryWithTTL(ts, key, value, time.Minute); e != nil {
+		t.Fatalf("SetEntryWithTTL() fails e: %v", e)
+	}
+	// Expire() on an entry absent in the Transaction
+	if e = d.Expire(ts, Key{Comp: []string{"KEY2"}}, time.Minute); e == nil {
+		t.Errorf("Expire() on missing entry succeeds")
+	}
+	if e = d.CommitTx(); e != nil {
+		t.Fatalf("CommitTx() fails e: %v", e)
+	}
+
+	if ttl, e := d.TTL(ts, key); e != nil || ttl <= 0 || ttl > time.Minute {
+		t.Errorf("TTL() = %v, %v; expected (0, 1m]", ttl, e)
+	}
+}
+
+func TestSubscribeExpired(t *testing.T) {
+	ts := &TableSpec{Name: TTL_PF + "_SUB"}
+	key := Key{Comp: []string{"KEY1"}}
+	value := Value{Field: map[string]string{"status": "pending"}}
+
+	var expiredCalled, delCalled atomic.Bool
+
+	d, e := newDB(StateDB)
+	if e != nil {
+		t.Fatalf("newDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { deleteTableAndDb(d, ts, t) })
+
+	s, e := SubscribeDB(Options{
+		DBNo:               StateDB,
+		TableNameSeparator: "|",
+		KeySeparator:       "|",
+	}, []*SKey{{Ts: ts, Key: &key}}, func(s *DB, skey *SKey, k *Key, event SEvent) error {
+		switch event {
+		case SEventExpired:
+			expiredCalled.Store(true)
+		case SEventDel:
+			delCalled.Store(true)
+		}
+		return nil
+	})
+	if e != nil {
+		t.Fatalf("SubscribeDB() fails e: %v", e)
+	}
+	t.Cleanup(func() { s.UnsubscribeDB() })
+
+	if e = d.SetEntryWithTTL(ts, key, value, 100*time.Millisecond); e != nil {
+		t.Fatalf("SetEntryWithTTL() fails e: %v", e)
+	}
+
+	time.Sleep(2 * time.Second)
+
+	if !expiredCalled.Load() || delCalled.Load() {
+		t.Errorf("Expecting only SEventExpired: expired: %v del: %v",
+			expiredCalled.Load(), delCalled.Load())
+	}
+}
@@ -0,0 +1,390 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/sonic-mgmt-common/cvl"
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"inet.af/netaddr"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// TypedValue gives schema aware access to the fields of a Value. Field types
+// are looked up from the sonic yang of the table, as loaded by CVL. Getters
+// parse the field as per its yang type, and setters validate the value and
+// store it in the canonical format. Type mismatches are reported as
+// tlerr.TranslibSyntaxValidationError.
+type TypedValue struct {
+	*Value
+	table string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// Typed returns a TypedValue for accessing the fields of this Value, which
+// is an entry of the table ts.
+func (v *Value) Typed(ts *TableSpec) TypedValue {
+	return TypedValue{Value: v, table: ts.Name}
+}
+
+// FieldType returns the yang type of a field. Leaf-list field names can be
+// given with or without the "@" suffix.
+func (tv TypedValue) FieldType(name string) (cvl.CVLFieldType, error) {
+	ft, ok := cvl.GetFieldType(tv.table, name)
+	if !ok {
+		return ft, typeError("%s: unknown field %s", tv.table, name)
+	}
+	return ft, nil
+}
+
+// GetUint returns the value of an unsigned integer (uint8 to uint64) field.
+// Schema default value is returned if the field does not exist, or 0 if
+// there is no default.
+func (tv TypedValue) GetUint(name string) (uint64, error) {
+	ft, data, err := tv.getLeaf(name, "uint8", "uint16", "uint32", "uint64")
+	if err != nil || len(data) == 0 {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(data, 10, baseTypeBits(ft.Base))
+	if err != nil {
+		return 0, typeError("%s: field %s: invalid %s value %q", tv.table,
+			name, ft.Base, data)
+	}
+	return n, nil
+}
+
+// SetUint sets the value of an unsigned integer (uint8 to uint64) field.
+// Returns error if the value is out of the range of the field's base type.
+func (tv TypedValue) SetUint(name string, value uint64) error {
+	return tv.setLeaf(name, strconv.FormatUint(value, 10), "uint8", "uint16",
+		"uint32", "uint64")
+}
+
+// GetInt returns the value of a signed integer (int8 to int64) field.
+// Schema default value is returned if the field does not exist, or 0 if
+// there is no default.
+func (tv TypedValue) GetInt(name string) (int64, error) {
+	ft, data, err := tv.getLeaf(name, "int8", "int16", "int32", "int64")
+	if err != nil || len(data) == 0 {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(data, 10, baseTypeBits(ft.Base))
+	if err != nil {
+		return 0, typeError("%s: field %s: invalid %s value %q", tv.table,
+			name, ft.Base, data)
+	}
+	return n, nil
+}
+
+// SetInt sets the value of a signed integer (int8 to int64) field.
+// Returns error if the value is out of the range of the field's base type.
+func (tv TypedValue) SetInt(name string, value int64) error {
+	return tv.setLeaf(name, strconv.FormatInt(value, 10), "int8", "int16",
+		"int32", "int64")
+}
+
+// GetBool returns the value of a boolean field. Schema default value is
+// returned if the field does not exist, or false if there is no default.
+func (tv TypedValue) GetBool(name string) (bool, error) {
+	_, data, err := tv.getLeaf(name, "boolean")
+	if err != nil || len(data) == 0 {
+		return false, err
+	}
+	switch data {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, typeError("%s: field %s: invalid boolean value %q",
+		tv.table, name, data)
+}
+
+// SetBool sets the value of a boolean field.
+func (tv TypedValue) SetBool(name string, value bool) error {
+	return tv.setLeaf(name, strconv.FormatBool(value), "boolean")
+}
+
+// GetIPPrefix returns the value of an ip-prefix, ipv4-prefix or ipv6-prefix
+// field. A zero IPPrefix is returned if the field does not exist.
+func (tv TypedValue) GetIPPrefix(name string) (netaddr.IPPrefix, error) {
+	ft, err := tv.FieldType(name)
+	if err != nil {
+		return netaddr.IPPrefix{}, err
+	}
+	if ft.IsLeafList || !isIPPrefixType(ft) {
+		return netaddr.IPPrefix{}, typeError("%s: field %s is not an ip prefix",
+			tv.table, name)
+	}
+	data, ok := tv.Field[name]
+	if !ok {
+		data = ft.Default
+	}
+	if len(data) == 0 {
+		return netaddr.IPPrefix{}, nil
+	}
+	if _, err = canonicalFieldValue(ft, data); err != nil {
+		return netaddr.IPPrefix{}, typeError("%s: field %s: %v", tv.table,
+			name, err)
+	}
+	return netaddr.ParseIPPrefix(data)
+}
+
+// SetIPPrefix sets the value of an ip-prefix, ipv4-prefix or ipv6-prefix
+// field. Host bits of the prefix are retained.
+func (tv TypedValue) SetIPPrefix(name string, value netaddr.IPPrefix) error {
+	ft, err := tv.FieldType(name)
+	if err != nil {
+		return err
+	}
+	if ft.IsLeafList || !isIPPrefixType(ft) {
+		return typeError("%s: field %s is not an ip prefix", tv.table, name)
+	}
+	return tv.SetString(name, value.String())
+}
+
+// GetLeafList returns the instances of a leaf-list field. The "@" suffix
+// is added to the field name if not present. Schema default instances are
+// returned if the field does not exist.
+func (tv TypedValue) GetLeafList(name string) ([]string, error) {
+	ft, err := tv.FieldType(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ft.IsLeafList {
+		return nil, typeError("%s: field %s is not a leaf-list", tv.table, name)
+	}
+	if !strings.HasSuffix(name, "@") {
+		name += "@"
+	}
+	data, ok := tv.Field[name]
+	if !ok {
+		data = ft.Default
+	}
+	if len(data) == 0 {
+		return []string{}, nil
+	}
+	return strings.Split(data, ","), nil
+}
+
+// SetLeafList validates each of the items as per the leaf-list field's type,
+// and sets them in the canonical format. The field is removed if items is
+// empty.
+func (tv TypedValue) SetLeafList(name string, items []string) error {
+	ft, err := tv.FieldType(name)
+	if err != nil {
+		return err
+	}
+	if !ft.IsLeafList {
+		return typeError("%s: field %s is not a leaf-list", tv.table, name)
+	}
+	canonItems := make([]string, len(items))
+	for i, item := range items {
+		if canonItems[i], err = canonicalFieldValue(ft, item); err != nil {
+			return typeError("%s: field %s: %v", tv.table, name, err)
+		}
+	}
+	tv.SetList(name, canonItems)
+	return nil
+}
+
+// SetString validates the string value as per the field's yang type, and
+// sets it in the canonical format. Eg: "007" is stored as "7" for integer
+// types, and IPv6 addresses are compressed. Leaf-list values are given as
+// comma separated instances; an empty value is an empty leaf-list, and
+// removes the field.
+func (tv TypedValue) SetString(name, value string) error {
+	ft, err := tv.FieldType(name)
+	if err != nil {
+		return err
+	}
+	if ft.IsLeafList {
+		if len(value) == 0 {
+			return tv.SetLeafList(name, nil)
+		}
+		return tv.SetLeafList(name, strings.Split(value, ","))
+	}
+	if value, err = canonicalFieldValue(ft, value); err != nil {
+		return typeError("%s: field %s: %v", tv.table, name, err)
+	}
+	tv.Set(name, value)
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// getLeaf returns the type and value (or default value) of a leaf field, after
+// checking that the field's base type is one of the given types.
+func (tv TypedValue) getLeaf(name string, bases ...string) (cvl.CVLFieldType, string, error) {
+	ft, err := tv.FieldType(name)
+	if err != nil {
+		return ft, "", err
+	}
+	if ft.IsLeafList || !isBaseType(ft, bases...) {
+		return ft, "", typeError("%s: field %s of type %s is not %s", tv.table,
+			name, ft.Base, strings.Join(bases, "|"))
+	}
+	if data, ok := tv.Field[name]; ok {
+		return ft, data, nil
+	}
+	return ft, ft.Default, nil
+}
+
+// setLeaf validates and sets the value of a leaf field, after checking that
+// the field's base type is one of the given types.
+func (tv TypedValue) setLeaf(name, value string, bases ...string) error {
+	ft, err := tv.FieldType(name)
+	if err != nil {
+		return err
+	}
+	if ft.IsLeafList || !isBaseType(ft, bases...) {
+		return typeError("%s: field %s of type %s is not %s", tv.table, name,
+			ft.Base, strings.Join(bases, "|"))
+	}
+	return tv.SetString(name, value)
+}
+
+// canonicalFieldValue validates a (leaf-list instance) value against the base
+// type of the field, and returns it in the canonical format. Restrictions like
+// range, length, pattern are not checked here; CVL validates them on write.
+func canonicalFieldValue(ft cvl.CVLFieldType, value string) (string, error) {
+	switch ft.Base {
+	case "int8", "int16", "int32", "int64":
+		n, err := strconv.ParseInt(value, 10, baseTypeBits(ft.Base))
+		if err != nil {
+			return value, fmt.Errorf("invalid %s value %q", ft.Base, value)
+		}
+		return strconv.FormatInt(n, 10), nil
+
+	case "uint8", "uint16", "uint32", "uint64":
+		n, err := strconv.ParseUint(value, 10, baseTypeBits(ft.Base))
+		if err != nil {
+			return value, fmt.Errorf("invalid %s value %q", ft.Base, value)
+		}
+		return strconv.FormatUint(n, 10), nil
+
+	case "decimal64":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return value, fmt.Errorf("invalid %s value %q", ft.Base, value)
+		}
+		return value, nil
+
+	case "boolean":
+		if value != "true" && value != "false" {
+			return value, fmt.Errorf("invalid %s value %q", ft.Base, value)
+		}
+		return value, nil
+
+	case "empty":
+		if len(value) != 0 {
+			return value, fmt.Errorf("invalid %s value %q", ft.Base, value)
+		}
+		return value, nil
+	}
+
+	// Inet types are derived from string, or a union of string types.
+	switch {
+	case hasTypedef(ft, "ipv4-prefix", "ipv6-prefix", "ip-prefix"):
+		prefix, err := netaddr.ParseIPPrefix(value)
+		if err != nil || !ipFamilyMatch(ft, prefix.IP(), "ipv4-prefix",
+			"ipv6-prefix") {
+			return value, fmt.Errorf("invalid %s value %q", ft.Typedefs[0],
+				value)
+		}
+		return prefix.String(), nil
+
+	case hasTypedef(ft, "ipv4-address", "ipv6-address", "ip-address",
+		"ipv4-address-no-zone", "ipv6-address-no-zone", "ip-address-no-zone"):
+		ip, err := netaddr.ParseIP(value)
+		if err != nil || !ipFamilyMatch(ft, ip, "ipv4-address",
+			"ipv6-address") {
+			return value, fmt.Errorf("invalid %s value %q", ft.Typedefs[0],
+				value)
+		}
+		return ip.String(), nil
+	}
+
+	return value, nil
+}
+
+// ipFamilyMatch checks the address family of the ip against the v4/v6 typedef
+// names, if the field is derived from one of them.
+func ipFamilyMatch(ft cvl.CVLFieldType, ip netaddr.IP, v4, v6 string) bool {
+	switch {
+	case hasTypedef(ft, v4, v4+"-no-zone"):
+		return ip.Is4()
+	case hasTypedef(ft, v6, v6+"-no-zone"):
+		return ip.Is6() && !ip.Is4in6()
+	}
+	return true
+}
+
+func isIPPrefixType(ft cvl.CVLFieldType) bool {
+	return hasTypedef(ft, "ipv4-prefix", "ipv6-prefix", "ip-prefix")
+}
+
+func isBaseType(ft cvl.CVLFieldType, bases ...string) bool {
+	for _, base := range bases {
+		if ft.Base == base {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTypedef(ft cvl.CVLFieldType, names ...string) bool {
+	for _, typedef := range ft.Typedefs {
+		for _, name := range names {
+			if typedef == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// baseTypeBits returns the bit size of the yang integer types.
+func baseTypeBits(base string) int {
+	switch strings.TrimPrefix(strings.TrimPrefix(base, "u"), "int") {
+	case "8":
+		return 8
+	case "16":
+		return 16
+	case "32":
+		return 32
+	}
+	return 64
+}
+
+func typeError(format string, args ...interface{}) error {
+	return tlerr.TranslibSyntaxValidationError{StatusCode: 400,
+		ErrorStr: fmt.Errorf(format, args...)}
+}
@@ -0,0 +1,159 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"inet.af/netaddr"
+)
+
+var aclRuleTs = &TableSpec{Name: "ACL_RULE"}
+var aclTableTs = &TableSpec{Name: "ACL_TABLE"}
+var portTs = &TableSpec{Name: "PORT"}
+
+func verifyTypeError(t *testing.T, err error) {
+	t.Helper()
+	if _, ok := err.(tlerr.TranslibSyntaxValidationError); !ok {
+		t.Errorf("Expected TranslibSyntaxValidationError; found %T(%v)", err, err)
+	}
+}
+
+func TestTypedValueFieldType(t *testing.T) {
+	v := Value{Field: map[string]string{}}
+	ft, err := v.Typed(aclRuleTs).FieldType("PRIORITY")
+	if err != nil || ft.Base != "uint16" || ft.IsLeafList {
+		t.Errorf("FieldType(PRIORITY) = %v, %v", ft, err)
+	}
+	ft, err = v.Typed(aclTableTs).FieldType("ports@")
+	if err != nil || !ft.IsLeafList {
+		t.Errorf("FieldType(ports@) = %v, %v", ft, err)
+	}
+	_, err = v.Typed(aclRuleTs).FieldType("UNKNOWN")
+	verifyTypeError(t, err)
+	_, err = v.Typed(&TableSpec{Name: "UNKNOWN_TABLE"}).FieldType("PRIORITY")
+	verifyTypeError(t, err)
+}
+
+func TestTypedValueUint(t *testing.T) {
+	v := Value{Field: map[string]string{"PRIORITY": "10"}}
+	tv := v.Typed(aclRuleTs)
+	if n, err := tv.GetUint("PRIORITY"); n != 10 || err != nil {
+		t.Errorf("GetUint(PRIORITY) = %v, %v", n, err)
+	}
+	if err := tv.SetUint("PRIORITY", 65535); err != nil || v.Get("PRIORITY") != "65535" {
+		t.Errorf("SetUint(PRIORITY, 65535) = %v; field=%q", err, v.Get("PRIORITY"))
+	}
+	verifyTypeError(t, tv.SetUint("PRIORITY", 65536))
+	if err := tv.SetString("PRIORITY", "007"); err != nil || v.Get("PRIORITY") != "7" {
+		t.Errorf("SetString(PRIORITY, 007) = %v; field=%q", err, v.Get("PRIORITY"))
+	}
+	verifyTypeError(t, tv.SetString("PRIORITY", "high"))
+	verifyTypeError(t, tv.SetInt("PRIORITY", 1))
+	if v.Get("PRIORITY") != "7" {
+		t.Errorf("PRIORITY modified by failed setters; found %q", v.Get("PRIORITY"))
+	}
+
+	v.Set("PRIORITY", "-1")
+	_, err := tv.GetUint("PRIORITY")
+	verifyTypeError(t, err)
+
+	v.Remove("PRIORITY")
+	if n, err := tv.GetUint("PRIORITY"); n != 0 || err != nil {
+		t.Errorf("GetUint(PRIORITY) on missing field = %v, %v", n, err)
+	}
+
+	pv := Value{Field: map[string]string{"mtu": "9100"}}
+	if n, err := pv.Typed(portTs).GetUint("mtu"); n != 9100 || err != nil {
+		t.Errorf("GetUint(mtu) = %v, %v", n, err)
+	}
+}
+
+func TestTypedValueDefault(t *testing.T) {
+	v := Value{Field: map[string]string{}}
+	tv := v.Typed(portTs)
+	if n, err := tv.GetUint("mtu"); n != 9100 || err != nil {
+		t.Errorf("GetUint(mtu) on missing field = %v, %v; expected default 9100", n, err)
+	}
+	if err := tv.SetString("admin_status", "up"); err != nil || v.Get("admin_status") != "up" {
+		t.Errorf("SetString(admin_status, up) = %v; field=%q", err, v.Get("admin_status"))
+	}
+	_, err := tv.GetBool("admin_status")
+	verifyTypeError(t, err)
+}
+
+func TestTypedValueIPPrefix(t *testing.T) {
+	v := Value{Field: map[string]string{"SRC_IP": "10.1.1.1/24"}}
+	tv := v.Typed(aclRuleTs)
+	p, err := tv.GetIPPrefix("SRC_IP")
+	if err != nil || p != netaddr.MustParseIPPrefix("10.1.1.1/24") {
+		t.Errorf("GetIPPrefix(SRC_IP) = %v, %v", p, err)
+	}
+
+	verifyTypeError(t, tv.SetString("SRC_IP", "2001::1/64"))
+	verifyTypeError(t, tv.SetString("SRC_IP", "10.1.1.1"))
+	verifyTypeError(t, tv.SetIPPrefix("SRC_IP", netaddr.MustParseIPPrefix("2001::/64")))
+	verifyTypeError(t, tv.SetIPPrefix("PRIORITY", netaddr.MustParseIPPrefix("1.1.1.0/24")))
+
+	if err = tv.SetString("SRC_IPV6", "2001:0db8:0000::0001/64"); err != nil ||
+		v.Get("SRC_IPV6") != "2001:db8::1/64" {
+		t.Errorf("SetString(SRC_IPV6) = %v; field=%q", err, v.Get("SRC_IPV6"))
+	}
+	verifyTypeError(t, tv.SetString("SRC_IPV6", "10.1.1.0/24"))
+
+	v.Set("SRC_IP", "10.1.1.256/24")
+	_, err = tv.GetIPPrefix("SRC_IP")
+	verifyTypeError(t, err)
+}
+
+func TestTypedValueLeafList(t *testing.T) {
+	v := Value{Field: map[string]string{"ports@": "Ethernet0,Ethernet4"}}
+	tv := v.Typed(aclTableTs)
+	items, err := tv.GetLeafList("ports")
+	if err != nil || !reflect.DeepEqual(items, []string{"Ethernet0", "Ethernet4"}) {
+		t.Errorf("GetLeafList(ports) = %v, %v", items, err)
+	}
+
+	if err = tv.SetLeafList("ports@", []string{"Ethernet8"}); err != nil ||
+		v.Get("ports@") != "Ethernet8" {
+		t.Errorf("SetLeafList(ports@) = %v; field=%q", err, v.Get("ports@"))
+	}
+	if err = tv.SetLeafList("ports", nil); err != nil || v.Has("ports@") {
+		t.Errorf("SetLeafList(ports, nil) = %v; fields=%v", err, v.Field)
+	}
+	if items, err = tv.GetLeafList("ports"); err != nil || len(items) != 0 {
+		t.Errorf("GetLeafList(ports) on missing field = %v, %v", items, err)
+	}
+
+	if err = tv.SetString("ports", "Ethernet0,Ethernet4"); err != nil ||
+		v.Get("ports@") != "Ethernet0,Ethernet4" {
+		t.Errorf("SetString(ports) = %v; field=%q", err, v.Get("ports@"))
+	}
+	if err = tv.SetString("ports", ""); err != nil || v.Has("ports@") {
+		t.Errorf("SetString(ports, \"\") = %v; fields=%v", err, v.Field)
+	}
+
+	_, err = tv.GetLeafList("policy_desc")
+	verifyTypeError(t, err)
+	_, err = tv.GetUint("ports")
+	verifyTypeError(t, err)
+}
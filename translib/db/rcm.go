@@ -23,6 +23,7 @@ var initMu = &sync.Mutex{}
 type redisClientManager struct {
 	// clients holds one Redis Client for each DBNum
 	clients                            [MaxDB + 1]*redis.Client
+	nsClients                          map[string]*[MaxDB + 1]*redis.Client // Other than DefaultNamespace
 	mu                                 *sync.RWMutex
 	curTransactionalClients            atomic.Int32
 	totalPoolClientsRequested          atomic.Uint64
@@ -33,7 +34,7 @@ type RedisCounters struct {
 	CurTransactionalClients            uint32                      // The number of transactional clients currently opened.
 	TotalPoolClientsRequested          uint64                      // The total number of Redis Clients using a connection pool requested.
 	TotalTransactionalClientsRequested uint64                      // The total number of Transactional Redis Clients requested.
-	PoolStatsPerDB                     map[string]*redis.PoolStats // The pool counters for each Redis Client in the cache.
+	PoolStatsPerDB                     map[string]*redis.PoolStats // The pool counters for each Redis Client in the cache, keyed by DB name (<namespace>/<DB name> for a namespace).
 }
 
 func init() {
@@ -49,6 +50,7 @@ func initializeRedisClientManager() {
 	}
 	rcm = &redisClientManager{
 		clients:                            [MaxDB + 1]*redis.Client{},
+		nsClients:                          map[string]*[MaxDB + 1]*redis.Client{},
 		mu:                                 &sync.RWMutex{},
 		curTransactionalClients:            atomic.Int32{},
 		totalPoolClientsRequested:          atomic.Uint64{},
@@ -66,11 +68,15 @@ func initializeRedisClientManager() {
 }
 
 func createRedisClient(db DBNum, poolSize int) *redis.Client {
-	opts := adjustRedisOpts(&Options{DBNo: db})
+	return createNsRedisClient(DefaultNamespace, db, poolSize)
+}
+
+func createNsRedisClient(ns string, db DBNum, poolSize int) *redis.Client {
+	opts := adjustRedisOpts(&Options{DBNo: db, Namespace: ns})
 	opts.PoolSize = poolSize
 	client := redis.NewClient(opts)
 	if _, err := client.Ping(context.Background()).Result(); err != nil {
-		log.V(0).Infof("RCM error during Redis Client creation for DBNum=%v: %v", db, err)
+		log.V(0).Infof("RCM error during Redis Client creation for Namespace=%v DBNum=%v: %v", ns, db, err)
 	}
 	return client
 }
@@ -83,37 +89,66 @@ func createRedisClientWithOpts(opts *redis.Options) *redis.Client {
 	return client
 }
 
-func getClient(db DBNum) *redis.Client {
+func getClient(ns string, db DBNum) *redis.Client {
 	rcm.mu.RLock()
 	defer rcm.mu.RUnlock()
-	return rcm.clients[int(db)]
+	return rcm.nsClientsOf(ns)[int(db)]
+}
+
+// nsClientsOf returns the Redis Clients of the namespace. Caller holds rcm.mu,
+// and the write lock if a namespace may be added.
+func (m *redisClientManager) nsClientsOf(ns string) *[MaxDB + 1]*redis.Client {
+	if ns == DefaultNamespace {
+		return &m.clients
+	}
+	if clients, ok := m.nsClients[ns]; ok {
+		return clients
+	}
+	return &[MaxDB + 1]*redis.Client{}
 }
 
 // RedisClient will return a Redis Client that can be used for non-transactional Redis operations.
 // The client returned by this function is shared among many DB readers/writers and uses
 // a connection pool. For transactional Redis operations, please use GetRedisClientForTransaction().
 func RedisClient(db DBNum) *redis.Client {
+	return NamespaceRedisClient(DefaultNamespace, db)
+}
+
+// NamespaceRedisClient is RedisClient() for a DB in the namespace. The
+// pooled clients of a namespace, other than the DefaultNamespace, are
+// created on first use.
+func NamespaceRedisClient(ns string, db DBNum) *redis.Client {
 	if rcm == nil {
 		initializeRedisClientManager()
 	}
 	if !(*usePools) { // Connection Pooling is disabled.
-		return TransactionalRedisClient(db)
+		return NamespaceTransactionalRedisClient(ns, db)
 	}
 	if len(getDBInstName(db)) == 0 {
 		log.V(0).Infof("Invalid DBNum requested: %v", db)
 		return nil
 	}
+	if !IsNamespacePresent(ns) {
+		log.V(0).Infof("Invalid Namespace requested: %v", ns)
+		return nil
+	}
 	rcm.totalPoolClientsRequested.Add(1)
-	rc := getClient(db)
+	rc := getClient(ns, db)
 	if rc == nil {
-		log.V(0).Infof("RCM Redis client for DBNum=%v is nil!", db)
+		if ns == DefaultNamespace {
+			log.V(0).Infof("RCM Redis client for DBNum=%v is nil!", db)
+		}
 		rcm.mu.Lock()
 		defer rcm.mu.Unlock()
-		if rc = rcm.clients[int(db)]; rc != nil {
+		clients := rcm.nsClientsOf(ns)
+		if rc = clients[int(db)]; rc != nil {
 			return rc
 		}
-		rc = createRedisClient(db, POOL_SIZE)
-		rcm.clients[int(db)] = rc
+		rc = createNsRedisClient(ns, db, POOL_SIZE)
+		clients[int(db)] = rc
+		if ns != DefaultNamespace {
+			rcm.nsClients[ns] = clients
+		}
 	}
 	return rc
 }
@@ -122,6 +157,12 @@ func RedisClient(db DBNum) *redis.Client {
 // for transactional operations. These operations include MULTI, PSUBSCRIBE (PubSub), and SCAN. This
 // client must be closed using CloseRedisClient when it is no longer needed.
 func TransactionalRedisClient(db DBNum) *redis.Client {
+	return NamespaceTransactionalRedisClient(DefaultNamespace, db)
+}
+
+// NamespaceTransactionalRedisClient is TransactionalRedisClient() for a DB in
+// the namespace.
+func NamespaceTransactionalRedisClient(ns string, db DBNum) *redis.Client {
 	if rcm == nil {
 		initializeRedisClientManager()
 	}
@@ -129,8 +170,12 @@ func TransactionalRedisClient(db DBNum) *redis.Client {
 		log.V(0).Infof("Invalid DBNum requested: %v", db)
 		return nil
 	}
+	if !IsNamespacePresent(ns) {
+		log.V(0).Infof("Invalid Namespace requested: %v", ns)
+		return nil
+	}
 	rcm.totalTransactionalClientsRequested.Add(1)
-	client := createRedisClient(db, 1)
+	client := createNsRedisClient(ns, db, 1)
 	rcm.curTransactionalClients.Add(1)
 	return client
 }
@@ -193,5 +238,14 @@ func RedisClientManagerCounters() *RedisCounters {
 		}
 		counters.PoolStatsPerDB[dbName] = client.PoolStats()
 	}
+	for ns, clients := range rcm.nsClients {
+		for db, client := range clients {
+			dbName := getDBInstName(DBNum(db))
+			if dbName == "" || client == nil {
+				continue
+			}
+			counters.PoolStatsPerDB[ns+"/"+dbName] = client.PoolStats()
+		}
+	}
 	return counters
 }
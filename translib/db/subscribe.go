@@ -25,8 +25,11 @@ package db
 import (
 	"context"
 	"errors"
+	"net"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
 	"github.com/golang/glog"
@@ -38,6 +41,22 @@ const (
 	channelDepthWarnThreshold = 100
 )
 
+// Reconnection of the subscription on a loss of the redis connection.
+var (
+	subscribeHealthCheckInterval = 5 * time.Second
+	subscribeMinBackoff          = 100 * time.Millisecond
+	subscribeMaxBackoff          = 10 * time.Second
+	subscribeMaxAttempts         = 30
+)
+
+// subscribeResyncMsg is queued on the message channel of a subscription,
+// after it is re-established.
+var subscribeResyncMsg = &redis.Message{}
+
+// subscribeMutex serializes the replacement of the sPubSub of a subscription
+// on reconnection, with its UnsubscribeDB.
+var subscribeMutex sync.Mutex
+
 // SKey is (TableSpec, Key, []SEvent) 3-tuples to be watched in a Transaction.
 type SKey struct {
 	Ts     *TableSpec
@@ -52,19 +71,40 @@ const (
 	SEventNone  SEvent = iota // No Op
 	SEventHSet                // HSET, HMSET, and its variants
 	SEventHDel                // HDEL, also SEventDel generated, if HASH is becomes empty
-	SEventDel                 // DEL, & also if key gets deleted (empty HASH, expire,..)
+	SEventDel                 // DEL, & also if key gets deleted (empty HASH,..)
 	SEventOther               // Some other command not covered above.
 
 	// The below two are always sent regardless of SEMap.
 	SEventClose // Close requested due to Unsubscribe() called.
 	SEventErr   // Error condition. Call Unsubscribe, after return.
+
+	SEventExpired // Key deleted on expiry of its TTL.
+
+	// The below two mark the boundary of the events of a committed
+	// Transaction. They are only sent if present in the SEMap. The Tx ID
+	// is given by SubscribeTxID() of the subscribe DB, in the handler.
+	SEventTxBegin // Events from a committed Transaction follow.
+	SEventTxEnd   // End of events from the committed Transaction.
+
+	// Sent for each SKey that has it in the SEMap, when the subscription
+	// is re-established after a loss of the redis connection (Eg: redis
+	// restart). Notifications may have been lost in between; the handler
+	// should re-read the current state of the SKey.
+	SEventResync
+)
+
+const (
+	txEventChannelPfx = "__translibtx@"
+	txEventBegin      = "begin"
+	txEventEnd        = "end"
 )
 
 var redisPayload2sEventMap map[string]SEvent = map[string]SEvent{
-	"":     SEventNone,
-	"hset": SEventHSet,
-	"hdel": SEventHDel,
-	"del":  SEventDel,
+	"":        SEventNone,
+	"hset":    SEventHSet,
+	"hdel":    SEventHDel,
+	"del":     SEventDel,
+	"expired": SEventExpired,
 }
 
 var txOp2sEventMap map[_txOp]SEvent = map[_txOp]SEvent{
@@ -137,9 +177,11 @@ func iSubscribeDB(opt Options, skeys []*SKey, handler interface{}) (*DB, error)
 			" skeys: ", skeys, " handler: ", handler)
 	}
 
-	patterns := make([]string, 0, len(skeys))
+	patterns := make([]string, 0, len(skeys)+1)
 	patMap := make(map[string]([]int), len(skeys))
-	var s string
+	txSkeys := make([]*SKey, 0)
+	var txChannel string
+	var pschan chan *redis.Message
 
 	if !opt.IsWriteDisabled {
 		glog.Info("SubscribeDB: Setting IsWriteDisabled")
@@ -156,12 +198,7 @@ func iSubscribeDB(opt Options, skeys []*SKey, handler interface{}) (*DB, error)
 		goto SubscribeDBExit
 	}
 
-	// Make sure that the DB is configured for key space notifications
-	// Optimize with LUA scripts to atomically add "Kgshxe".
-	s, e = d.client.ConfigSet(context.Background(), "notify-keyspace-events", "AKE").Result()
-
-	if e != nil {
-		glog.Error("SubscribeDB: ConfigSet(): e: ", e, " s: ", s)
+	if e = d.configKeyspaceEvents(); e != nil {
 		goto SubscribeDBExit
 	}
 
@@ -173,6 +210,16 @@ func iSubscribeDB(opt Options, skeys []*SKey, handler interface{}) (*DB, error)
 		}
 		patMap[pattern] = append(patMap[pattern], i)
 
+		if skeys[i].SEMap[SEventTxBegin] || skeys[i].SEMap[SEventTxEnd] {
+			txSkeys = append(txSkeys, skeys[i])
+		}
+	}
+
+	// Tx boundary events are published in the MULTI/EXEC. Subscribing on the
+	// same PubSub maintains their order w.r.t. the keyspace notifications.
+	if len(txSkeys) != 0 {
+		txChannel = d.txEventChannel()
+		patterns = append(patterns, txChannel)
 	}
 
 	glog.Info("SubscribeDB: patterns: ", patterns)
@@ -199,10 +246,13 @@ func iSubscribeDB(opt Options, skeys []*SKey, handler interface{}) (*DB, error)
 	// Register
 	d.registerSubscribeDB(isSA, skeys, handler)
 
+	// Start a goroutine to receive the messages, which also reconnects.
+	pschan = make(chan *redis.Message, subscriptionChannelSize)
+	go d.receiveSubscribe(d.sPubSub, patterns, pschan)
+
 	// Start a goroutine to read messages and call handler.
 	go func() {
 		maxchandepth := 0
-		pschan := d.sPubSub.Channel(redis.WithChannelSize(subscriptionChannelSize))
 		for msg := range pschan {
 			glog.V(3).Info("SubscribeDB: msg: ", msg)
 			curdepth := len(pschan)
@@ -213,6 +263,43 @@ func iSubscribeDB(opt Options, skeys []*SKey, handler interface{}) (*DB, error)
 				}
 			}
 
+			if msg == subscribeResyncMsg {
+				glog.Info("SubscribeDB: SEventResync: patterns: ", patterns)
+				d.sTxID = ""
+				for _, skey := range skeys {
+					if !skey.SEMap[SEventResync] {
+						continue
+					}
+					if isSA {
+						hFuncSA(d, RunningConfigNotif, "", skey, &Key{}, SEventResync)
+					} else {
+						hFunc(d, skey, &Key{}, SEventResync)
+					}
+				}
+				continue
+			}
+
+			if len(txChannel) != 0 && msg.Channel == txChannel {
+				sevent, txID := d.txEventPayload2sEvent(msg.Payload)
+				if sevent == SEventTxBegin {
+					d.sTxID = txID
+				}
+				for _, skey := range txSkeys {
+					if !skey.SEMap[sevent] {
+						continue
+					}
+					if isSA {
+						hFuncSA(d, RunningConfigNotif, "", skey, &Key{}, sevent)
+					} else {
+						hFunc(d, skey, &Key{}, sevent)
+					}
+				}
+				if sevent == SEventTxEnd {
+					d.sTxID = ""
+				}
+				continue
+			}
+
 			// Should this be a goroutine, in case each notification CB
 			// takes a long time to run ?
 			for _, skeyIndex := range patMap[msg.Pattern] {
@@ -238,7 +325,7 @@ func iSubscribeDB(opt Options, skeys []*SKey, handler interface{}) (*DB, error)
 
 		// Send the Close|Err notification.
 		var sEvent = SEventClose
-		if !d.sCIP {
+		if !d.isUnsubscribed() {
 			sEvent = SEventErr
 		}
 		glog.Info("SubscribeDB: SEventClose|Err: ", sEvent)
@@ -279,17 +366,17 @@ func (d *DB) UnsubscribeDB() error {
 		glog.Info("UnsubscribeDB: d:", d)
 	}
 
+	// Mark close in progress, and do the close, ch gets closed too.
+	subscribeMutex.Lock()
 	if d.sCIP {
+		subscribeMutex.Unlock()
 		glog.Error("UnsubscribeDB: Close in Progress")
 		e = errors.New("UnsubscribeDB: Close in Progress")
 		goto UnsubscribeDBExit
 	}
-
-	// Mark close in progress.
 	d.sCIP = true
-
-	// Do the close, ch gets closed too.
 	d.sPubSub.Close()
+	subscribeMutex.Unlock()
 
 	// Wait for the goroutine to complete ? TBD
 	// Should not this happen because of the range statement on ch?
@@ -309,6 +396,112 @@ UnsubscribeDBExit:
 	return e
 }
 
+// configKeyspaceEvents makes sure that the DB is configured for key space
+// notifications. (The configuration is lost on a redis restart.)
+func (d *DB) configKeyspaceEvents() error {
+	// Optimize with LUA scripts to atomically add "Kgshxe".
+	s, e := d.client.ConfigSet(context.Background(), "notify-keyspace-events", "AKE").Result()
+	if e != nil {
+		glog.Error("SubscribeDB: ConfigSet(): e: ", e, " s: ", s)
+	}
+	return e
+}
+
+// receiveSubscribe receives the messages of the PubSub ps into the pschan,
+// until the subscription is closed by UnsubscribeDB. On a loss of the redis
+// connection (detected by an error, or an unanswered PING), it resubscribes
+// to the patterns with backoff, and queues the subscribeResyncMsg. If the
+// resubscribe gives up, the pschan is closed with the subscription still open,
+// so that the handler is notified with SEventErr.
+func (d *DB) receiveSubscribe(ps *redis.PubSub, patterns []string, pschan chan<- *redis.Message) {
+	defer close(pschan)
+
+	ctx := context.Background()
+	var pingPending bool
+
+	for {
+		msg, e := ps.ReceiveTimeout(ctx, subscribeHealthCheckInterval)
+		if e == nil {
+			pingPending = false
+			if m, ok := msg.(*redis.Message); ok {
+				pschan <- m
+			}
+			continue
+		}
+
+		if d.isUnsubscribed() {
+			return
+		}
+
+		if netErr, ok := e.(net.Error); ok && netErr.Timeout() && !pingPending {
+			if e = ps.Ping(ctx); e == nil {
+				pingPending = true
+				continue
+			}
+		}
+
+		glog.Warningf("SubscribeDB: %s: connection lost: %v", d.Name(), e)
+		if ps = d.resubscribe(patterns); ps == nil {
+			return
+		}
+		pingPending = false
+		pschan <- subscribeResyncMsg
+	}
+}
+
+// resubscribe replaces the sPubSub with a new subscription to the patterns,
+// retrying with exponential backoff. Returns nil, if the subscription is
+// closed by UnsubscribeDB in the meantime, or after subscribeMaxAttempts
+// failed attempts.
+func (d *DB) resubscribe(patterns []string) *redis.PubSub {
+	ctx := context.Background()
+	backoff := subscribeMinBackoff
+
+	for attempt := 1; attempt <= subscribeMaxAttempts; attempt++ {
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > subscribeMaxBackoff {
+			backoff = subscribeMaxBackoff
+		}
+
+		subscribeMutex.Lock()
+		if d.sCIP {
+			subscribeMutex.Unlock()
+			return nil
+		}
+
+		var ps *redis.PubSub
+		d.sPubSub.Close()
+		e := d.configKeyspaceEvents()
+		if e == nil {
+			ps = d.client.PSubscribe(ctx, patterns...)
+			if _, e = ps.ReceiveTimeout(ctx, subscribeHealthCheckInterval); e == nil {
+				d.sPubSub = ps
+			} else {
+				ps.Close()
+			}
+		}
+		subscribeMutex.Unlock()
+
+		if e == nil {
+			glog.Infof("SubscribeDB: %s: resubscribed after %d attempt(s)",
+				d.Name(), attempt)
+			return ps
+		}
+		glog.Warningf("SubscribeDB: %s: resubscribe attempt %d failed: %v",
+			d.Name(), attempt, e)
+	}
+
+	glog.Errorf("SubscribeDB: %s: resubscribe failed after %d attempts",
+		d.Name(), subscribeMaxAttempts)
+	return nil
+}
+
+func (d *DB) isUnsubscribed() bool {
+	subscribeMutex.Lock()
+	defer subscribeMutex.Unlock()
+	return d.sCIP
+}
+
 func (d *DB) key2redisChannel(ts *TableSpec, key Key) string {
 
 	if glog.V(5) {
@@ -358,3 +551,31 @@ func (d *DB) redisPayload2sEvent(redisPayload string) SEvent {
 func (d *DB) txOp2sEvent(op _txOp) SEvent {
 	return txOp2sEventMap[op]
 }
+
+// SubscribeTxID returns the ID of the committed Transaction, whose events are
+// being notified, i.e. between SEventTxBegin and SEventTxEnd. It is empty for
+// events outside of a Transaction. Only valid in the HFunc of the SubscribeDB.
+func (d *DB) SubscribeTxID() string {
+	return d.sTxID
+}
+
+func (d *DB) txEventChannel() string {
+	return txEventChannelPfx + strconv.Itoa(d.Opts.DBNo.ID()) + "__"
+}
+
+// txEventArgs returns the PUBLISH args for the Tx boundary event.
+func (d *DB) txEventArgs(event string, txID string) []interface{} {
+	return []interface{}{"PUBLISH", d.txEventChannel(), event + ":" + txID}
+}
+
+func (d *DB) txEventPayload2sEvent(payload string) (SEvent, string) {
+	event, txID, _ := strings.Cut(payload, ":")
+	switch event {
+	case txEventBegin:
+		return SEventTxBegin, txID
+	case txEventEnd:
+		return SEventTxEnd, txID
+	}
+	glog.Warning("txEventPayload2sEvent: Unknown payload: ", payload)
+	return SEventOther, txID
+}
@@ -0,0 +1,267 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+/*
+Package metrics exposes the DB, CVL, transformer and translib API statistics
+in the Prometheus text exposition format.
+
+The host process mounts the Handler, Eg:
+
+	http.Handle("/metrics", metrics.Handler())
+*/
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// API names of the translib requests, used as the "api" label value.
+const (
+	APIGet       = "get"
+	APICreate    = "create"
+	APIUpdate    = "update"
+	APIReplace   = "replace"
+	APIDelete    = "delete"
+	APIAction    = "action"
+	APIBulk      = "bulk"
+	APISubscribe = "subscribe"
+	APIStream    = "stream"
+)
+
+// ContentType is the Content-Type of the Handler response.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds (in seconds) of the request latency
+// histogram buckets.
+var DefaultBuckets = []float64{
+	.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60,
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// ObserveRequest records a translib API request that started at the given
+// time, and completed with the err.
+func ObserveRequest(api string, start time.Time, err error) {
+	apiStats.observe(api, time.Since(start), err)
+}
+
+// Handler returns the http.Handler which serves all the metrics.
+func Handler() http.Handler {
+	return http.HandlerFunc(serveMetrics)
+}
+
+// ClearRequestStats clears the translib API request counts and histograms.
+func ClearRequestStats() {
+	apiStats.clear()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+type requestStats struct {
+	okCount  uint64
+	errCount uint64
+	sum      time.Duration
+	buckets  []uint64 // Non cumulative count, per DefaultBuckets (+Inf last)
+}
+
+type apiStatsMap struct {
+	mu  sync.Mutex
+	api map[string]*requestStats
+}
+
+var apiStats = &apiStatsMap{api: make(map[string]*requestStats)}
+
+func (m *apiStatsMap) observe(api string, dur time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rs, ok := m.api[api]
+	if !ok {
+		rs = &requestStats{buckets: make([]uint64, len(DefaultBuckets)+1)}
+		m.api[api] = rs
+	}
+
+	if err != nil {
+		rs.errCount++
+	} else {
+		rs.okCount++
+	}
+	rs.sum += dur
+	rs.buckets[sort.SearchFloat64s(DefaultBuckets, dur.Seconds())]++
+}
+
+func (m *apiStatsMap) clear() {
+	m.mu.Lock()
+	m.api = make(map[string]*requestStats)
+	m.mu.Unlock()
+}
+
+// snapshot returns a copy of the stats, and the sorted API names.
+func (m *apiStatsMap) snapshot() (map[string]requestStats, []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]requestStats, len(m.api))
+	apis := make([]string, 0, len(m.api))
+	for api, rs := range m.api {
+		rsCopy := *rs
+		rsCopy.buckets = append([]uint64(nil), rs.buckets...)
+		stats[api] = rsCopy
+		apis = append(apis, api)
+	}
+	sort.Strings(apis)
+	return stats, apis
+}
+
+func (m *apiStatsMap) write(w *metricWriter) {
+	stats, apis := m.snapshot()
+
+	w.family("translib_requests_total", "counter",
+		"Number of translib API requests.")
+	for _, api := range apis {
+		w.sample("translib_requests_total", stats[api].okCount,
+			"api", api, "result", "ok")
+		w.sample("translib_requests_total", stats[api].errCount,
+			"api", api, "result", "error")
+	}
+
+	w.family("translib_request_duration_seconds", "histogram",
+		"Latency of translib API requests.")
+	for _, api := range apis {
+		rs := stats[api]
+		var cumulative uint64
+		for i, le := range DefaultBuckets {
+			cumulative += rs.buckets[i]
+			w.sample("translib_request_duration_seconds_bucket", cumulative,
+				"api", api, "le", formatFloat(le))
+		}
+		cumulative += rs.buckets[len(DefaultBuckets)]
+		w.sample("translib_request_duration_seconds_bucket", cumulative,
+			"api", api, "le", "+Inf")
+		w.sample("translib_request_duration_seconds_sum", rs.sum,
+			"api", api)
+		w.sample("translib_request_duration_seconds_count", cumulative,
+			"api", api)
+	}
+}
+
+func serveMetrics(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		rw.Header().Set("Allow", "GET, HEAD")
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rw.Header().Set("Content-Type", ContentType)
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	w := &metricWriter{w: bufio.NewWriter(rw)}
+	apiStats.write(w)
+	writeDBStats(w)
+	writeCVLStats(w)
+	writePruneStats(w)
+	writeRedisCounters(w)
+
+	if e := w.w.Flush(); e != nil {
+		glog.Warning("metrics: write: e: ", e)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Text Exposition Format                                                    //
+////////////////////////////////////////////////////////////////////////////////
+
+type metricWriter struct {
+	w *bufio.Writer
+}
+
+// family writes the HELP and TYPE lines of a metric.
+func (w *metricWriter) family(name, typ, help string) {
+	fmt.Fprintf(w.w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes a sample line. The labels are name, value pairs. The value is
+// a number, or a time.Duration which is written in seconds.
+func (w *metricWriter) sample(name string, value interface{}, labels ...string) {
+	w.w.WriteString(name)
+	if len(labels) != 0 {
+		w.w.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i != 0 {
+				w.w.WriteByte(',')
+			}
+			w.w.WriteString(labels[i])
+			w.w.WriteString(`="`)
+			w.w.WriteString(labelValueEscaper.Replace(labels[i+1]))
+			w.w.WriteByte('"')
+		}
+		w.w.WriteByte('}')
+	}
+	w.w.WriteByte(' ')
+	w.w.WriteString(formatValue(value))
+	w.w.WriteByte('\n')
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Duration:
+		return formatFloat(v.Seconds())
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return formatFloat(v)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	return fmt.Sprint(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
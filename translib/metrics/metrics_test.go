@@ -0,0 +1,146 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/sonic-mgmt-common/translib/db"
+)
+
+func TestObserveRequest(t *testing.T) {
+	ClearRequestStats()
+	defer ClearRequestStats()
+
+	now := time.Now()
+	ObserveRequest(APIGet, now.Add(-2*time.Millisecond), nil)
+	ObserveRequest(APIGet, now.Add(-2*time.Second), nil)
+	ObserveRequest(APIGet, now.Add(-2*time.Minute), errors.New("failed"))
+	ObserveRequest(APICreate, now, nil)
+
+	var b strings.Builder
+	w := newTestWriter(&b)
+	apiStats.write(w)
+	w.w.Flush()
+	out := b.String()
+
+	for _, line := range []string{
+		"# TYPE translib_requests_total counter",
+		`translib_requests_total{api="get",result="ok"} 2`,
+		`translib_requests_total{api="get",result="error"} 1`,
+		`translib_requests_total{api="create",result="ok"} 1`,
+		"# TYPE translib_request_duration_seconds histogram",
+		`translib_request_duration_seconds_bucket{api="get",le="0.001"} 0`,
+		`translib_request_duration_seconds_bucket{api="get",le="0.005"} 1`,
+		`translib_request_duration_seconds_bucket{api="get",le="2.5"} 2`,
+		`translib_request_duration_seconds_bucket{api="get",le="60"} 2`,
+		`translib_request_duration_seconds_bucket{api="get",le="+Inf"} 3`,
+		`translib_request_duration_seconds_count{api="get"} 3`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Missing %q in:\n%s", line, out)
+		}
+	}
+}
+
+func TestMetricWriterEscape(t *testing.T) {
+	var b strings.Builder
+	w := newTestWriter(&b)
+	w.sample("m", 1.5, "l", "a\"b\\c\nd")
+	w.sample("d", 1500*time.Millisecond)
+	w.w.Flush()
+
+	expected := "m{l=\"a\\\"b\\\\c\\nd\"} 1.5\nd 1.5\n"
+	if b.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, b.String())
+	}
+}
+
+func TestGlobalCacheStatsFamilies(t *testing.T) {
+	var b strings.Builder
+	w := newTestWriter(&b)
+	writeGlobalCacheStats(w, []db.DBStats{
+		{Name: "CONFIG_DB", GlobalCache: &db.GlobalCacheStats{Entries: 2}},
+		{Name: "APPL_DB"},
+		{Name: "STATE_DB", GlobalCache: &db.GlobalCacheStats{Entries: 3}},
+	})
+	w.w.Flush()
+	out := b.String()
+
+	if n := strings.Count(out, "# TYPE translib_db_global_cache_entries "); n != 1 {
+		t.Errorf("TYPE written %d times in:\n%s", n, out)
+	}
+	if n := strings.Count(out, "# HELP translib_db_global_cache_lookups_total "); n != 1 {
+		t.Errorf("HELP written %d times in:\n%s", n, out)
+	}
+	for _, line := range []string{
+		`translib_db_global_cache_entries{db="CONFIG_DB"} 2`,
+		`translib_db_global_cache_entries{db="STATE_DB"} 3`,
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("Missing %q in:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "APPL_DB") {
+		t.Errorf("APPL_DB without a global cache in:\n%s", out)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ObserveRequest(APIBulk, time.Now(), nil)
+	defer ClearRequestStats()
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatal("Unexpected status: ", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Error("Unexpected Content-Type: ", ct)
+	}
+	for _, name := range []string{
+		`translib_requests_total{api="bulk",result="ok"} 1`,
+		"# TYPE translib_db_new_total counter",
+		"# TYPE cvl_validations_total counter",
+		"# TYPE translib_xfmr_prune_total counter",
+		"# TYPE translib_redis_transactional_clients gauge",
+	} {
+		if !strings.Contains(rec.Body.String(), name) {
+			t.Errorf("Missing %q", name)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Error("POST: Unexpected status: ", rec.Code)
+	}
+}
+
+func newTestWriter(b *strings.Builder) *metricWriter {
+	return &metricWriter{w: bufio.NewWriter(b)}
+}
@@ -0,0 +1,274 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package metrics
+
+import (
+	"sort"
+
+	"github.com/Azure/sonic-mgmt-common/cvl"
+	"github.com/Azure/sonic-mgmt-common/translib/db"
+	"github.com/Azure/sonic-mgmt-common/translib/transformer"
+	"github.com/golang/glog"
+)
+
+// writeDBStats writes the db.GetDBStats(). The per DB, and per table
+// statistics are only collected when enabled in the TRANSLIB_DB|default.
+func writeDBStats(w *metricWriter) {
+	stats, e := db.GetDBStats()
+	if e != nil {
+		glog.Warning("metrics: GetDBStats: e: ", e)
+		return
+	}
+
+	w.family("translib_db_new_total", "counter", "Number of DBs opened.")
+	w.sample("translib_db_new_total", stats.New)
+	w.family("translib_db_delete_total", "counter", "Number of DBs closed.")
+	w.sample("translib_db_delete_total", stats.Delete)
+	w.family("translib_db_peak_open", "gauge", "Peak number of open DBs.")
+	w.sample("translib_db_peak_open", stats.PeakOpen)
+	w.family("translib_db_new_seconds_total", "counter",
+		"Time spent opening DBs.")
+	w.sample("translib_db_new_seconds_total", stats.NewTime)
+	w.family("translib_db_new_peak_seconds", "gauge",
+		"Peak time spent opening a DB.")
+	w.sample("translib_db_new_peak_seconds", stats.NewPeak)
+	w.family("translib_db_zero_get_total", "counter",
+		"Number of DBs closed without any get operation.")
+	w.sample("translib_db_zero_get_total", stats.ZeroGetHits)
+
+	hits, total, peak := db.GetDBStatsTotals()
+	w.family("translib_db_ops_total", "counter",
+		"Number of DB get operations, across all DBs.")
+	w.sample("translib_db_ops_total", hits)
+	w.family("translib_db_ops_seconds_total", "counter",
+		"Time spent in DB get operations, across all DBs.")
+	w.sample("translib_db_ops_seconds_total", total)
+	w.family("translib_db_ops_peak_seconds", "gauge",
+		"Peak time spent in a DB get operation, across all DBs.")
+	w.sample("translib_db_ops_peak_seconds", peak)
+
+	dbs := make([]db.DBStats, 0, len(stats.Databases))
+	for _, dbStats := range stats.Databases {
+		if dbStats.Name != "" {
+			dbs = append(dbs, dbStats)
+		}
+	}
+
+	w.family("translib_db_table_ops_total", "counter",
+		"Number of DB table get operations (table=\"\" for the DB total).")
+	for _, dbStats := range dbs {
+		w.sample("translib_db_table_ops_total", dbStats.AllTables.Hits,
+			"db", dbStats.Name, "table", "")
+		for _, name := range sortedKeys(dbStats.Tables) {
+			w.sample("translib_db_table_ops_total", dbStats.Tables[name].Hits,
+				"db", dbStats.Name, "table", name)
+		}
+	}
+
+	w.family("translib_db_table_ops_seconds_total", "counter",
+		"Time spent in DB table get operations (table=\"\" for the DB total).")
+	for _, dbStats := range dbs {
+		w.sample("translib_db_table_ops_seconds_total",
+			dbStats.AllTables.Time, "db", dbStats.Name, "table", "")
+		for _, name := range sortedKeys(dbStats.Tables) {
+			w.sample("translib_db_table_ops_seconds_total",
+				dbStats.Tables[name].Time, "db", dbStats.Name, "table", name)
+		}
+	}
+
+	w.family("translib_db_cache_hits_total", "counter",
+		"Number of DB get entry operations served from the cache.")
+	for _, dbStats := range dbs {
+		w.sample("translib_db_cache_hits_total",
+			dbStats.AllTables.GetEntryCacheHits, "db", dbStats.Name)
+	}
+
+	w.family("translib_db_global_cache_hits_total", "counter",
+		"Number of DB get entry operations served from the global cache.")
+	for _, dbStats := range dbs {
+		w.sample("translib_db_global_cache_hits_total",
+			dbStats.AllTables.GetEntryGlobalCacheHits, "db", dbStats.Name)
+	}
+
+	writeGlobalCacheStats(w, dbs)
+
+	w.family("translib_db_map_ops_total", "counter",
+		"Number of DB map get operations.")
+	for _, dbStats := range dbs {
+		w.sample("translib_db_map_ops_total", dbStats.AllMaps.Hits,
+			"db", dbStats.Name)
+	}
+
+	w.family("translib_redis_pool_hits_total", "counter",
+		"Number of times a free connection was found in the pool.")
+	for _, dbStats := range dbs {
+		w.sample("translib_redis_pool_hits_total",
+			dbStats.RedisPoolStats.Hits, "db", dbStats.Name)
+	}
+	w.family("translib_redis_pool_misses_total", "counter",
+		"Number of times a free connection was not found in the pool.")
+	for _, dbStats := range dbs {
+		w.sample("translib_redis_pool_misses_total",
+			dbStats.RedisPoolStats.Misses, "db", dbStats.Name)
+	}
+	w.family("translib_redis_pool_timeouts_total", "counter",
+		"Number of pool wait timeouts.")
+	for _, dbStats := range dbs {
+		w.sample("translib_redis_pool_timeouts_total",
+			dbStats.RedisPoolStats.Timeouts, "db", dbStats.Name)
+	}
+	w.family("translib_redis_pool_conns", "gauge",
+		"Number of connections in the pool.")
+	for _, dbStats := range dbs {
+		w.sample("translib_redis_pool_conns",
+			dbStats.RedisPoolStats.TotalConns, "db", dbStats.Name)
+	}
+	w.family("translib_redis_pool_idle_conns", "gauge",
+		"Number of idle connections in the pool.")
+	for _, dbStats := range dbs {
+		w.sample("translib_redis_pool_idle_conns",
+			dbStats.RedisPoolStats.IdleConns, "db", dbStats.Name)
+	}
+}
+
+// writeGlobalCacheStats writes the global (process wide) cache stats of the
+// DBs, which have the global cache enabled.
+func writeGlobalCacheStats(w *metricWriter, dbs []db.DBStats) {
+	gcDBs := make([]db.DBStats, 0, len(dbs))
+	for _, dbStats := range dbs {
+		if dbStats.GlobalCache != nil {
+			gcDBs = append(gcDBs, dbStats)
+		}
+	}
+	if len(gcDBs) == 0 {
+		return
+	}
+
+	w.family("translib_db_global_cache_subscribed", "gauge",
+		"Whether the global cache keyspace subscription is up.")
+	for _, dbStats := range gcDBs {
+		w.sample("translib_db_global_cache_subscribed",
+			dbStats.GlobalCache.Subscribed, "db", dbStats.Name)
+	}
+
+	w.family("translib_db_global_cache_entries", "gauge",
+		"Number of entries in the global cache.")
+	for _, dbStats := range gcDBs {
+		w.sample("translib_db_global_cache_entries",
+			dbStats.GlobalCache.Entries, "db", dbStats.Name)
+	}
+
+	w.family("translib_db_global_cache_bytes", "gauge",
+		"Approximate size of the entries in the global cache.")
+	for _, dbStats := range gcDBs {
+		w.sample("translib_db_global_cache_bytes",
+			dbStats.GlobalCache.Bytes, "db", dbStats.Name)
+	}
+
+	w.family("translib_db_global_cache_lookups_total", "counter",
+		"Number of global cache lookups, by result.")
+	for _, dbStats := range gcDBs {
+		w.sample("translib_db_global_cache_lookups_total",
+			dbStats.GlobalCache.Hits, "db", dbStats.Name, "result", "hit")
+		w.sample("translib_db_global_cache_lookups_total",
+			dbStats.GlobalCache.Misses, "db", dbStats.Name, "result", "miss")
+	}
+
+	w.family("translib_db_global_cache_hit_ratio", "gauge",
+		"Ratio of the global cache lookups that were hits.")
+	for _, dbStats := range gcDBs {
+		w.sample("translib_db_global_cache_hit_ratio",
+			dbStats.GlobalCache.HitRatio, "db", dbStats.Name)
+	}
+
+	w.family("translib_db_global_cache_evictions_total", "counter",
+		"Number of LRU evictions from the global cache.")
+	for _, dbStats := range gcDBs {
+		w.sample("translib_db_global_cache_evictions_total",
+			dbStats.GlobalCache.Evictions, "db", dbStats.Name)
+	}
+
+	w.family("translib_db_global_cache_invalidations_total", "counter",
+		"Number of global cache entries invalidated by DB changes.")
+	for _, dbStats := range gcDBs {
+		w.sample("translib_db_global_cache_invalidations_total",
+			dbStats.GlobalCache.Invalidations, "db", dbStats.Name)
+	}
+}
+
+// writeCVLStats writes the cvl.GetValidationTimeStats().
+func writeCVLStats(w *metricWriter) {
+	stats := cvl.GetValidationTimeStats()
+
+	w.family("cvl_validations_total", "counter",
+		"Number of CVL config validations.")
+	w.sample("cvl_validations_total", stats.Hits)
+	w.family("cvl_validation_seconds_total", "counter",
+		"Time spent in CVL config validations.")
+	w.sample("cvl_validation_seconds_total", stats.Time)
+	w.family("cvl_validation_peak_seconds", "gauge",
+		"Peak time spent in a CVL config validation.")
+	w.sample("cvl_validation_peak_seconds", stats.Peak)
+}
+
+// writePruneStats writes the transformer.GetPruneQPStats().
+func writePruneStats(w *metricWriter) {
+	stats := *transformer.GetPruneQPStats()
+
+	w.family("translib_xfmr_prune_total", "counter",
+		"Number of query parameter prunes.")
+	w.sample("translib_xfmr_prune_total", stats.Hits)
+	w.family("translib_xfmr_prune_seconds_total", "counter",
+		"Time spent in query parameter prunes.")
+	w.sample("translib_xfmr_prune_seconds_total", stats.Time)
+	w.family("translib_xfmr_prune_peak_seconds", "gauge",
+		"Peak time spent in a query parameter prune.")
+	w.sample("translib_xfmr_prune_peak_seconds", stats.Peak)
+	w.family("translib_xfmr_prune_last_seconds", "gauge",
+		"Time spent in the last query parameter prune.")
+	w.sample("translib_xfmr_prune_last_seconds", stats.Last)
+}
+
+// writeRedisCounters writes the db.RedisClientManagerCounters().
+func writeRedisCounters(w *metricWriter) {
+	counters := db.RedisClientManagerCounters()
+
+	w.family("translib_redis_transactional_clients", "gauge",
+		"Number of transactional redis clients currently open.")
+	w.sample("translib_redis_transactional_clients",
+		counters.CurTransactionalClients)
+	w.family("translib_redis_pool_clients_requested_total", "counter",
+		"Number of pooled redis clients requested.")
+	w.sample("translib_redis_pool_clients_requested_total",
+		counters.TotalPoolClientsRequested)
+	w.family("translib_redis_transactional_clients_requested_total", "counter",
+		"Number of transactional redis clients requested.")
+	w.sample("translib_redis_transactional_clients_requested_total",
+		counters.TotalTransactionalClientsRequested)
+}
+
+func sortedKeys(m map[string]db.Stats) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
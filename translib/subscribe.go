@@ -23,9 +23,11 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Azure/sonic-mgmt-common/translib/db"
 	"github.com/Azure/sonic-mgmt-common/translib/internal/apis"
+	"github.com/Azure/sonic-mgmt-common/translib/metrics"
 	"github.com/Azure/sonic-mgmt-common/translib/path"
 	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
 	"github.com/Workiva/go-datastructures/queue"
@@ -168,7 +170,11 @@ func init() {
 }
 
 // Subscribe - Subscribes to the paths requested and sends notifications when the data changes in DB
-func Subscribe(req SubscribeRequest) error {
+func Subscribe(req SubscribeRequest) (err error) {
+	defer func(start time.Time) {
+		metrics.ObserveRequest(metrics.APISubscribe, start, err)
+	}(time.Now())
+
 	sid := subscribeContextId(req.Session)
 	paths := req.Paths
 	log.Infof("[%v] Subscribe: paths = %v", sid, paths)
@@ -216,7 +222,11 @@ func Subscribe(req SubscribeRequest) error {
 // Function will block until all values are returned. This can be used for
 // handling "Sample" subscriptions (NotificationType.Sample).
 // Client should be authorized to perform "subscribe" operation.
-func Stream(req SubscribeRequest) error {
+func Stream(req SubscribeRequest) (err error) {
+	defer func(start time.Time) {
+		metrics.ObserveRequest(metrics.APIStream, start, err)
+	}(time.Now())
+
 	sid := subscribeContextId(req.Session)
 	log.Infof("[%v] Stream: paths = %v", sid, req.Paths)
 
@@ -666,6 +676,15 @@ func (ng *notificationGroup) add(nInfo *notificationInfo) {
 	}
 }
 
+// notificationSEMap lists the DB events handled by notificationHandler.
+var notificationSEMap = map[db.SEvent]bool{
+	db.SEventHSet:    true,
+	db.SEventHDel:    true,
+	db.SEventDel:     true,
+	db.SEventExpired: true,
+	db.SEventResync:  true,
+}
+
 // toSKeys prepares DB subscribe keys for the notificationGroup
 func (ng *notificationGroup) toSKeys() []*db.SKey {
 	skeys := make([]*db.SKey, 0, len(ng.nInfos))
@@ -677,6 +696,7 @@ func (ng *notificationGroup) toSKeys() []*db.SKey {
 		skeys = append(skeys, &db.SKey{
 			Ts:     nInfo.table,
 			Key:    nInfo.key,
+			SEMap:  notificationSEMap,
 			Opaque: ng,
 		})
 	}
@@ -61,7 +61,7 @@ func notificationHandler(d *db.DB, sKey *db.SKey, key *db.Key, event db.SEvent)
 	defer sMutex.Unlock()
 
 	switch event {
-	case db.SEventHSet, db.SEventHDel, db.SEventDel:
+	case db.SEventHSet, db.SEventHDel, db.SEventDel, db.SEventExpired:
 		if nGrup, ok := sKey.Opaque.(*notificationGroup); ok {
 			n := notificationEvent{
 				id:    nid,
@@ -74,6 +74,16 @@ func notificationHandler(d *db.DB, sKey *db.SKey, key *db.Key, event db.SEvent)
 			log.Warningf("[%v] notificationHandler: SKey corrupted; nil opaque. %v", nid, *sKey)
 		}
 
+	case db.SEventResync:
+		// Notifications may have been lost while the db connection was down.
+		if nGrup, ok := sKey.Opaque.(*notificationGroup); ok {
+			n := notificationEvent{
+				id:    nid,
+				nGrup: nGrup,
+			}
+			n.resync(sKey)
+		}
+
 	case db.SEventClose:
 		// Close event would have been triggered due to unsubscribe on stop request
 		delete(cleanupMap, d)
@@ -322,6 +332,53 @@ func (ne *notificationEvent) process() {
 	}
 }
 
+// resync re-reads the db entries matching the SKey, and notifies their
+// differences from the OnChange cache. This includes the deletes of the
+// cached entries which no longer exist in the db.
+func (ne *notificationEvent) resync(sKey *db.SKey) {
+	var nInfo *notificationInfo
+	for _, n := range ne.nGrup.nInfos {
+		nInfo = n[0]
+		break
+	}
+	if nInfo == nil {
+		return
+	}
+
+	d := nInfo.sInfo.dbs[nInfo.dbno]
+	if d == nil {
+		log.V(2).Infof("[%s] defunct subscription", ne.id)
+		return
+	}
+
+	keys, err := d.GetKeysPattern(sKey.Ts, *sKey.Key)
+	if err != nil {
+		log.Warningf("[%s] resync: failed to read keys of %s/%v; err=%v",
+			ne.id, sKey.Ts.Name, sKey.Key.Comp, err)
+		return
+	}
+
+	log.Infof("[%s] resync: %s/%v has %d keys", ne.id, sKey.Ts.Name,
+		sKey.Key.Comp, len(keys))
+
+	exists := make(map[string]bool, len(keys))
+	for i := range keys {
+		exists[keys[i].String()] = true
+		ne.event = db.SEventHSet
+		ne.key = &keys[i]
+		ne.process()
+	}
+
+	for _, k := range d.OnChangeCacheKeys(sKey.Ts, *sKey.Key) {
+		if !exists[k.String()] {
+			k := k
+			ne.event = db.SEventDel
+			ne.key = &k
+			ne.process()
+		}
+	}
+}
+
 // DiffAndMergeOnChangeCache Compare modified entry with cached entry and
 // return modified fields. Also update the cache with changes.
 func (ne *notificationEvent) DiffAndMergeOnChangeCache() (*apis.EntryDiff, error) {
@@ -342,7 +399,7 @@ func (ne *notificationEvent) DiffAndMergeOnChangeCache() (*apis.EntryDiff, error
 	if d == nil {
 		return nil, defunct
 	}
-	if ne.event == db.SEventDel {
+	if ne.event == db.SEventDel || ne.event == db.SEventExpired {
 		oldValue, err = d.OnChangeCacheDelete(ts, *ne.key)
 	} else {
 		oldValue, newValue, err = d.OnChangeCacheUpdate(ts, *ne.key)
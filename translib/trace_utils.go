@@ -0,0 +1,44 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package translib
+
+import (
+	"context"
+
+	"github.com/Azure/sonic-mgmt-common/translib/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startRequestSpan starts the span of a translib API request, as a child of
+// the span in the northbound request context (if any). Returns the context
+// for the child spans of the request.
+func startRequestSpan(ctxt context.Context, name, path string) (context.Context, trace.Span) {
+	if len(path) == 0 {
+		return tracing.Start(ctxt, name)
+	}
+	return tracing.Start(ctxt, name, attribute.String("path", path))
+}
+
+// startSpan starts a child span of the request.
+func startSpan(ctxt context.Context, name string) trace.Span {
+	_, span := tracing.Start(ctxt, name)
+	return span
+}
@@ -0,0 +1,257 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+/*
+Package tracing configures OpenTelemetry tracing of the management framework
+requests, and provides the span helpers used by translib, transformer and db.
+
+Tracing is disabled (no-op) by default. It is configured with Configure(), or
+from the standard OpenTelemetry environment variables on init:
+
+	OTEL_TRACES_EXPORTER                "none" (default), "console", or "otlp"
+	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT  OTLP/HTTP traces URL (Eg: http://localhost:4318/v1/traces)
+	OTEL_EXPORTER_OTLP_ENDPOINT         OTLP/HTTP base URL, if the above is not set
+	OTEL_SERVICE_NAME                   Service name (default "sonic-mgmt-framework")
+	OTEL_TRACES_SAMPLER_ARG             Sampling ratio, 0.0 to 1.0 (default 1.0)
+
+Spans are children of the span in the northbound request context.Context.
+*/
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/golang/glog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Types                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+// Exporter names
+const (
+	ExporterNone    = "none"
+	ExporterConsole = "console" // stdout
+	ExporterOTLP    = "otlp"    // OTLP/HTTP, protobuf encoding
+)
+
+// DefaultServiceName is the service.name resource attribute of the spans.
+const DefaultServiceName = "sonic-mgmt-framework"
+
+// DefaultOTLPEndpoint is the default OTLP/HTTP traces URL.
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/traces"
+
+// Config is the tracing configuration.
+type Config struct {
+	Exporter    string            // ExporterNone, ExporterConsole, or ExporterOTLP
+	Endpoint    string            // OTLP/HTTP traces URL
+	Headers     map[string]string // OTLP/HTTP request headers
+	ServiceName string            // service.name resource attribute
+	SampleRatio float64           // Ratio of root spans sampled (0 < r <= 1)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Exported Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+// Configure replaces the tracing configuration. The spans of the previous
+// configuration are flushed.
+func Configure(cfg Config) error {
+	var exporter sdktrace.SpanExporter
+	var e error
+
+	switch strings.ToLower(cfg.Exporter) {
+	case "", ExporterNone:
+	case ExporterConsole, "stdout":
+		exporter, e = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterOTLP:
+		exporter, e = newOTLPExporter(cfg.Endpoint, cfg.Headers)
+	default:
+		e = fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	if e != nil {
+		glog.Error("tracing: Configure: ", e)
+		return e
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if provider != nil {
+		if e := provider.Shutdown(context.Background()); e != nil {
+			glog.Warning("tracing: Shutdown: ", e)
+		}
+		provider = nil
+	}
+
+	if exporter == nil {
+		enabled.Store(false)
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		glog.V(1).Info("tracing: Disabled")
+		return nil
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+	enabled.Store(true)
+
+	glog.Infof("tracing: Enabled: exporter %v, service %v, ratio %v",
+		cfg.Exporter, serviceName, ratio)
+	return nil
+}
+
+// ConfigFromEnv returns the Config from the OpenTelemetry environment
+// variables.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Exporter:    os.Getenv("OTEL_TRACES_EXPORTER"),
+		Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"),
+		ServiceName: os.Getenv("OTEL_SERVICE_NAME"),
+		SampleRatio: 1,
+	}
+
+	if cfg.Endpoint == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			cfg.Endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+
+	if arg := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); arg != "" {
+		if r, e := strconv.ParseFloat(arg, 64); e == nil {
+			cfg.SampleRatio = r
+		}
+	}
+
+	if hdrs := os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"); hdrs != "" {
+		cfg.Headers = make(map[string]string)
+		for _, kv := range strings.Split(hdrs, ",") {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				cfg.Headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+
+	return cfg
+}
+
+// Shutdown flushes the pending spans, and disables tracing.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	enabled.Store(false)
+	if provider == nil {
+		return nil
+	}
+	e := provider.Shutdown(ctx)
+	provider = nil
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	return e
+}
+
+// Enabled returns true if a trace exporter is configured.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Start starts a span, as a child of the span in the ctx (if any). When
+// tracing is disabled, it returns the ctx, and a no-op span. The ctx may be
+// nil.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !enabled.Load() {
+		return ctx, noopSpan
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithAttributes(attrs...))
+}
+
+// End ends the span, recording the err (if any) in the span status.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+//  Internal Functions                                                        //
+////////////////////////////////////////////////////////////////////////////////
+
+const instrumentationName = "github.com/Azure/sonic-mgmt-common/translib"
+
+var mu sync.Mutex
+var provider *sdktrace.TracerProvider
+var enabled atomic.Bool
+
+var noopSpan = trace.SpanFromContext(context.Background())
+
+// newOTLPExporter returns an OTLP/HTTP exporter, which posts the spans to
+// the endpoint URL (DefaultOTLPEndpoint if empty).
+func newOTLPExporter(endpoint string, headers map[string]string) (sdktrace.SpanExporter, error) {
+	if endpoint == "" {
+		endpoint = DefaultOTLPEndpoint
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(endpoint)}
+	if len(headers) != 0 {
+		opts = append(opts, otlptracehttp.WithHeaders(headers))
+	}
+	return otlptracehttp.New(context.Background(), opts...)
+}
+
+func init() {
+	cfg := ConfigFromEnv()
+	if cfg.Exporter != "" && cfg.Exporter != ExporterNone {
+		Configure(cfg)
+	}
+}
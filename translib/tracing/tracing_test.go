@@ -0,0 +1,129 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestStartDisabled(t *testing.T) {
+	if e := Configure(Config{Exporter: ExporterNone}); e != nil {
+		t.Fatalf("Configure() fails e: %v", e)
+	}
+
+	ctx, span := Start(nil, "test")
+	if ctx == nil {
+		t.Errorf("Start() returned nil context")
+	}
+	if span.IsRecording() || span.SpanContext().IsValid() {
+		t.Errorf("Start() returned a recording span when disabled")
+	}
+	End(span, errors.New("ignored"))
+}
+
+func TestConfigureUnknownExporter(t *testing.T) {
+	if e := Configure(Config{Exporter: "zipkin"}); e == nil {
+		t.Errorf("Configure() with unknown exporter succeeds")
+	}
+	if Enabled() {
+		t.Errorf("Enabled() after a failed Configure()")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-protobuf" {
+			t.Errorf("Unexpected Content-Type %s", ct)
+		}
+		if r.Header.Get("X-Test") != "1" {
+			t.Errorf("Missing header X-Test")
+		}
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	e := Configure(Config{
+		Exporter:    ExporterOTLP,
+		Endpoint:    server.URL,
+		Headers:     map[string]string{"X-Test": "1"},
+		ServiceName: "tracing-test",
+	})
+	if e != nil {
+		t.Fatalf("Configure() fails e: %v", e)
+	}
+	if !Enabled() {
+		t.Fatalf("Enabled() false after Configure()")
+	}
+
+	ctx, parent := Start(context.Background(), "parent",
+		attribute.String("path", "/openconfig-interfaces:interfaces"))
+	_, child := Start(ctx, "child")
+	End(child, errors.New("child failed"))
+	End(parent, nil)
+
+	if e = Shutdown(context.Background()); e != nil {
+		t.Fatalf("Shutdown() fails e: %v", e)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// The strings of the (protobuf encoded) request are not transformed.
+	all := bytes.Join(bodies, nil)
+	for _, s := range []string{"tracing-test", "parent", "child",
+		"child failed", "path", "/openconfig-interfaces:interfaces"} {
+		if !bytes.Contains(all, []byte(s)) {
+			t.Errorf("%q not exported", s)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+	t.Setenv("OTEL_TRACES_SAMPLER_ARG", "0.25")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "a=1, b = 2")
+
+	cfg := ConfigFromEnv()
+	if cfg.Exporter != ExporterOTLP ||
+		cfg.Endpoint != "http://collector:4318/v1/traces" ||
+		cfg.SampleRatio != 0.25 ||
+		cfg.Headers["a"] != "1" || cfg.Headers["b"] != "2" {
+		t.Errorf("Unexpected Config: %+v", cfg)
+	}
+}
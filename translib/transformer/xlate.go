@@ -25,10 +25,12 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/Azure/sonic-mgmt-common/translib/db"
 	"github.com/Azure/sonic-mgmt-common/translib/ocbinds"
 	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/Azure/sonic-mgmt-common/translib/tracing"
 	log "github.com/golang/glog"
 	"github.com/openconfig/goyang/pkg/yang"
 	"github.com/openconfig/ygot/ygot"
@@ -76,6 +78,16 @@ func XlateFuncCall(name string, params ...interface{}) (result []reflect.Value,
 	for k, param := range params {
 		in[k] = reflect.ValueOf(param)
 	}
+	ctxt, rt := xlateFuncContext(params)
+	_, span := tracing.Start(ctxt, "xfmr."+name)
+	defer span.End()
+
+	if rt != nil {
+		ts := time.Now()
+		result = XlateFuncs[name].Call(in)
+		rt.AddXfmr(name, time.Since(ts))
+		return result, nil
+	}
 	result = XlateFuncs[name].Call(in)
 	return result, nil
 }
@@ -897,6 +909,55 @@ func SortSncTableDbKeys(tableName string, dbKeyMap map[string]db.Value) []string
 	return ordDbKey
 }
 
+// GetDbYangPaths returns the SONiC YANG path of the table, key, and (optional)
+// field, and the OpenConfig YANG xpaths (without the list keys) mapped to the
+// table, or the field. The "@" suffix of a leaf-list field is ignored.
+func GetDbYangPaths(tableName string, key db.Key, field string) (string, []string, error) {
+	tblSpecInfo, ok := xDbSpecMap[tableName]
+	if !ok || tblSpecInfo == nil || tblSpecInfo.module == "" {
+		log.Warning("xDbSpecMap data not found for ", tableName)
+		return "", nil, tlerr.NotFound("No YANG found for table %s", tableName)
+	}
+
+	var path strings.Builder
+	path.WriteString("/" + tblSpecInfo.module + ":" + tblSpecInfo.module + "/" + tableName)
+
+	listNm := ""
+	for _, lNm := range tblSpecInfo.listName {
+		if lSpecInfo, ok := xDbSpecMap[tableName+"/"+lNm]; ok && lSpecInfo != nil &&
+			len(lSpecInfo.keyList) == key.Len() {
+			listNm = lNm
+			break
+		}
+	}
+
+	if listNm != "" {
+		escaper := strings.NewReplacer(`\`, `\\`, `]`, `\]`)
+		path.WriteString("/" + listNm)
+		for i, keyNm := range xDbSpecMap[tableName+"/"+listNm].keyList {
+			path.WriteString("[" + keyNm + "=" + escaper.Replace(key.Get(i)) + "]")
+		}
+	} else if _, ok := xDbSpecMap[tableName+"/"+strings.Join(key.Comp, "|")]; ok {
+		// Singleton (container) table
+		path.WriteString("/" + strings.Join(key.Comp, "|"))
+	} else {
+		log.Warningf("No list in table %s for key %v", tableName, key)
+		return "", nil, tlerr.NotFound("No YANG list found for %s key %v", tableName, key)
+	}
+
+	ocXpaths := tblSpecInfo.yangXpath
+	if field = strings.TrimSuffix(field, "@"); field != "" {
+		path.WriteString("/" + field)
+		if fldSpecInfo, ok := xDbSpecMap[tableName+"/"+field]; ok && fldSpecInfo != nil {
+			ocXpaths = fldSpecInfo.yangXpath
+		} else {
+			ocXpaths = nil
+		}
+	}
+
+	return path.String(), append([]string(nil), ocXpaths...), nil
+}
+
 func IsDependentChildTable(depChildTbl string, parentTbl string, uriModuleNm string) bool {
 	/* This function checks if the input table(depChildTbl) is a dependent child of parentTbl
 	   across sonic modules based on CVL provided dependency info.
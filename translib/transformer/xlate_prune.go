@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Azure/sonic-mgmt-common/translib/db"
 	"github.com/Azure/sonic-mgmt-common/translib/ocbinds"
 	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
 	log "github.com/golang/glog"
@@ -130,6 +131,7 @@ func xfmrPruneQP(ygRoot *ygot.GoStruct, queryParams QueryParams, uri string,
 
 	tt := time.Since(ts)
 	GetPruneQPStats().add(tt, uri)
+	db.RequestTraceFromContext(ctxt).AddPrune(tt)
 	log.Infof("xfmrPruneQP: URI %v, requestUri %v, TimeTaken %s",
 		uri, requestUri, tt)
 	log.Infof("xfmrPruneQP: Totals: %s", GetPruneQPStats())
@@ -0,0 +1,80 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package transformer
+
+import (
+	"context"
+
+	"github.com/Azure/sonic-mgmt-common/translib/db"
+)
+
+// xlateFuncContext returns the request context, and the db.RequestTrace of
+// the request for which the transformer callback is being invoked. They are
+// found from the request context, or the DBs in the params. The trace is nil
+// if the request is not traced.
+func xlateFuncContext(params []interface{}) (context.Context, *db.RequestTrace) {
+	for _, param := range params {
+		switch p := param.(type) {
+		case XfmrParams:
+			return xfmrParamsContext(&p)
+		case *XfmrParams:
+			return xfmrParamsContext(p)
+		case XfmrSubscInParams:
+			return dbsContext(p.dbs)
+		case XfmrDbToYgPathParams:
+			return dbsContext(p.dbs)
+		case XfmrDbTblCbkParams:
+			return dbContext(p.d)
+		case [db.MaxDB]*db.DB:
+			return dbsContext(p)
+		}
+	}
+	return nil, nil
+}
+
+func xfmrParamsContext(inParams *XfmrParams) (context.Context, *db.RequestTrace) {
+	ctxt, rt := dbContext(inParams.d)
+	if inParams.d == nil {
+		ctxt, rt = dbsContext(inParams.dbs)
+	}
+	if inParams.ctxt != nil {
+		ctxt = inParams.ctxt
+		if ctxtRt := db.RequestTraceFromContext(ctxt); ctxtRt != nil {
+			rt = ctxtRt
+		}
+	}
+	return ctxt, rt
+}
+
+func dbsContext(dbs [db.MaxDB]*db.DB) (context.Context, *db.RequestTrace) {
+	for _, d := range dbs {
+		if d != nil {
+			return dbContext(d)
+		}
+	}
+	return nil, nil
+}
+
+func dbContext(d *db.DB) (context.Context, *db.RequestTrace) {
+	if d == nil || d.Opts == nil {
+		return nil, nil
+	}
+	return d.Opts.Ctxt, d.Opts.Trace
+}
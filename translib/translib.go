@@ -36,9 +36,13 @@ package translib
 import (
 	"context"
 	"sync"
+	"time"
 
+	"github.com/Azure/sonic-mgmt-common/translib/cs"
 	"github.com/Azure/sonic-mgmt-common/translib/db"
+	"github.com/Azure/sonic-mgmt-common/translib/metrics"
 	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+	"github.com/Azure/sonic-mgmt-common/translib/tracing"
 	"github.com/Workiva/go-datastructures/queue"
 	log "github.com/golang/glog"
 	"github.com/openconfig/ygot/ygot"
@@ -73,11 +77,16 @@ type SetRequest struct {
 	AuthEnabled      bool
 	ClientVersion    Version
 	DeleteEmptyEntry bool
+	Trace            bool            // Return the RequestTrace in the SetResponse (Not for Bulk)
+	Ctxt             context.Context // Northbound request context (Not for Bulk)
+	SessionToken     string          // Config Session token; Edit its candidate config (Not for Bulk)
+	Namespace        string          // DB namespace (Multi-ASIC); Default if empty (Not for Bulk)
 }
 
 type SetResponse struct {
 	ErrSrc ErrSource
 	Err    error
+	Trace  *db.RequestTrace // Stats of the request, if SetRequest.Trace
 }
 
 type QueryParameters struct {
@@ -94,12 +103,16 @@ type GetRequest struct {
 	ClientVersion Version
 	QueryParams   QueryParameters
 	Ctxt          context.Context
+	Trace         bool   // Return the RequestTrace in the GetResponse
+	SessionToken  string // Config Session token; Read its candidate config
+	Namespace     string // DB namespace (Multi-ASIC); Default if empty
 }
 
 type GetResponse struct {
 	Payload   []byte
 	ValueTree ygot.ValidatedGoStruct
 	ErrSrc    ErrSource
+	Trace     *db.RequestTrace // Stats of the request, if GetRequest.Trace
 }
 
 type ActionRequest struct {
@@ -108,6 +121,8 @@ type ActionRequest struct {
 	User          UserRoles
 	AuthEnabled   bool
 	ClientVersion Version
+	Ctxt          context.Context
+	Namespace     string // DB namespace (Multi-ASIC); Default if empty
 }
 
 type ActionResponse struct {
@@ -128,6 +143,9 @@ type BulkRequest struct {
 	User          UserRoles
 	AuthEnabled   bool
 	ClientVersion Version
+	Ctxt          context.Context
+	SessionToken  string // Config Session token; Edit its candidate config
+	Namespace     string // DB namespace (Multi-ASIC); Default if empty
 }
 
 // BulkResponseEntry - Entry for BulkResponse
@@ -153,9 +171,23 @@ func init() {
 }
 
 // Create - Creates entries in the redis DB pertaining to the path and payload
-func Create(req SetRequest) (SetResponse, error) {
+func Create(req SetRequest) (resp SetResponse, err error) {
+	ctxt, span := startRequestSpan(req.Ctxt, "translib.Create", req.Path)
+	defer func(start time.Time) {
+		tracing.End(span, err)
+		metrics.ObserveRequest(metrics.APICreate, start, err)
+	}(time.Now())
+
+	var trace *db.RequestTrace
+	if req.Trace {
+		trace = db.NewRequestTrace()
+		defer func(start time.Time) {
+			trace.Done(start)
+			resp.Trace = trace
+		}(time.Now())
+	}
+
 	var keys []db.WatchKeys
-	var resp SetResponse
 	path := req.Path
 	payload := req.Payload
 	if !isAuthorizedForSet(req) {
@@ -168,7 +200,9 @@ func Create(req SetRequest) (SetResponse, error) {
 	log.Info("Create request received with path =", path)
 	log.Info("Create request received with payload =", string(payload))
 
+	appSpan := startSpan(ctxt, "getAppModule")
 	app, appInfo, err := getAppModule(path, req.ClientVersion)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp.ErrSrc = ProtoErr
@@ -185,16 +219,20 @@ func Create(req SetRequest) (SetResponse, error) {
 	writeMutex.Lock()
 	defer writeMutex.Unlock()
 
-	d, err := db.NewDB(getDBOptions(db.ConfigDB, withForceNewRedisConnection))
+	d, closeDB, err := getConfigDB(req.SessionToken, req.User, true,
+		withForceNewRedisConnection, withUser(req.User.Name), withTrace(trace),
+		withContext(ctxt), withNamespace(req.Namespace))
 
 	if err != nil {
 		resp.ErrSrc = ProtoErr
 		return resp, err
 	}
 
-	defer d.DeleteDB()
+	defer closeDB()
 
+	appSpan = startSpan(ctxt, "translateCreate")
 	keys, err = (*app).translateCreate(d)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp.ErrSrc = AppErr
@@ -208,7 +246,9 @@ func Create(req SetRequest) (SetResponse, error) {
 		return resp, err
 	}
 
+	appSpan = startSpan(ctxt, "processCreate")
 	resp, err = (*app).processCreate(d)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		d.AbortTx()
@@ -226,9 +266,23 @@ func Create(req SetRequest) (SetResponse, error) {
 }
 
 // Update - Updates entries in the redis DB pertaining to the path and payload
-func Update(req SetRequest) (SetResponse, error) {
+func Update(req SetRequest) (resp SetResponse, err error) {
+	ctxt, span := startRequestSpan(req.Ctxt, "translib.Update", req.Path)
+	defer func(start time.Time) {
+		tracing.End(span, err)
+		metrics.ObserveRequest(metrics.APIUpdate, start, err)
+	}(time.Now())
+
+	var trace *db.RequestTrace
+	if req.Trace {
+		trace = db.NewRequestTrace()
+		defer func(start time.Time) {
+			trace.Done(start)
+			resp.Trace = trace
+		}(time.Now())
+	}
+
 	var keys []db.WatchKeys
-	var resp SetResponse
 	path := req.Path
 	payload := req.Payload
 	if !isAuthorizedForSet(req) {
@@ -241,7 +295,9 @@ func Update(req SetRequest) (SetResponse, error) {
 	log.Info("Update request received with path =", path)
 	log.Info("Update request received with payload =", string(payload))
 
+	appSpan := startSpan(ctxt, "getAppModule")
 	app, appInfo, err := getAppModule(path, req.ClientVersion)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp.ErrSrc = ProtoErr
@@ -258,16 +314,20 @@ func Update(req SetRequest) (SetResponse, error) {
 	writeMutex.Lock()
 	defer writeMutex.Unlock()
 
-	d, err := db.NewDB(getDBOptions(db.ConfigDB, withForceNewRedisConnection))
+	d, closeDB, err := getConfigDB(req.SessionToken, req.User, true,
+		withForceNewRedisConnection, withUser(req.User.Name), withTrace(trace),
+		withContext(ctxt), withNamespace(req.Namespace))
 
 	if err != nil {
 		resp.ErrSrc = ProtoErr
 		return resp, err
 	}
 
-	defer d.DeleteDB()
+	defer closeDB()
 
+	appSpan = startSpan(ctxt, "translateUpdate")
 	keys, err = (*app).translateUpdate(d)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp.ErrSrc = AppErr
@@ -281,7 +341,9 @@ func Update(req SetRequest) (SetResponse, error) {
 		return resp, err
 	}
 
+	appSpan = startSpan(ctxt, "processUpdate")
 	resp, err = (*app).processUpdate(d)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		d.AbortTx()
@@ -299,10 +361,23 @@ func Update(req SetRequest) (SetResponse, error) {
 }
 
 // Replace - Replaces entries in the redis DB pertaining to the path and payload
-func Replace(req SetRequest) (SetResponse, error) {
-	var err error
+func Replace(req SetRequest) (resp SetResponse, err error) {
+	ctxt, span := startRequestSpan(req.Ctxt, "translib.Replace", req.Path)
+	defer func(start time.Time) {
+		tracing.End(span, err)
+		metrics.ObserveRequest(metrics.APIReplace, start, err)
+	}(time.Now())
+
+	var trace *db.RequestTrace
+	if req.Trace {
+		trace = db.NewRequestTrace()
+		defer func(start time.Time) {
+			trace.Done(start)
+			resp.Trace = trace
+		}(time.Now())
+	}
+
 	var keys []db.WatchKeys
-	var resp SetResponse
 	path := req.Path
 	payload := req.Payload
 	if !isAuthorizedForSet(req) {
@@ -315,7 +390,9 @@ func Replace(req SetRequest) (SetResponse, error) {
 	log.Info("Replace request received with path =", path)
 	log.Info("Replace request received with payload =", string(payload))
 
+	appSpan := startSpan(ctxt, "getAppModule")
 	app, appInfo, err := getAppModule(path, req.ClientVersion)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp.ErrSrc = ProtoErr
@@ -332,16 +409,20 @@ func Replace(req SetRequest) (SetResponse, error) {
 	writeMutex.Lock()
 	defer writeMutex.Unlock()
 
-	d, err := db.NewDB(getDBOptions(db.ConfigDB, withForceNewRedisConnection))
+	d, closeDB, err := getConfigDB(req.SessionToken, req.User, true,
+		withForceNewRedisConnection, withUser(req.User.Name), withTrace(trace),
+		withContext(ctxt), withNamespace(req.Namespace))
 
 	if err != nil {
 		resp.ErrSrc = ProtoErr
 		return resp, err
 	}
 
-	defer d.DeleteDB()
+	defer closeDB()
 
+	appSpan = startSpan(ctxt, "translateReplace")
 	keys, err = (*app).translateReplace(d)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp.ErrSrc = AppErr
@@ -355,7 +436,9 @@ func Replace(req SetRequest) (SetResponse, error) {
 		return resp, err
 	}
 
+	appSpan = startSpan(ctxt, "processReplace")
 	resp, err = (*app).processReplace(d)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		d.AbortTx()
@@ -373,10 +456,23 @@ func Replace(req SetRequest) (SetResponse, error) {
 }
 
 // Delete - Deletes entries in the redis DB pertaining to the path
-func Delete(req SetRequest) (SetResponse, error) {
-	var err error
+func Delete(req SetRequest) (resp SetResponse, err error) {
+	ctxt, span := startRequestSpan(req.Ctxt, "translib.Delete", req.Path)
+	defer func(start time.Time) {
+		tracing.End(span, err)
+		metrics.ObserveRequest(metrics.APIDelete, start, err)
+	}(time.Now())
+
+	var trace *db.RequestTrace
+	if req.Trace {
+		trace = db.NewRequestTrace()
+		defer func(start time.Time) {
+			trace.Done(start)
+			resp.Trace = trace
+		}(time.Now())
+	}
+
 	var keys []db.WatchKeys
-	var resp SetResponse
 	path := req.Path
 	if !isAuthorizedForSet(req) {
 		return resp, tlerr.AuthorizationError{
@@ -387,7 +483,9 @@ func Delete(req SetRequest) (SetResponse, error) {
 
 	log.Info("Delete request received with path =", path)
 
+	appSpan := startSpan(ctxt, "getAppModule")
 	app, appInfo, err := getAppModule(path, req.ClientVersion)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp.ErrSrc = ProtoErr
@@ -405,16 +503,20 @@ func Delete(req SetRequest) (SetResponse, error) {
 	writeMutex.Lock()
 	defer writeMutex.Unlock()
 
-	d, err := db.NewDB(getDBOptions(db.ConfigDB, withForceNewRedisConnection))
+	d, closeDB, err := getConfigDB(req.SessionToken, req.User, true,
+		withForceNewRedisConnection, withUser(req.User.Name), withTrace(trace),
+		withContext(ctxt), withNamespace(req.Namespace))
 
 	if err != nil {
 		resp.ErrSrc = ProtoErr
 		return resp, err
 	}
 
-	defer d.DeleteDB()
+	defer closeDB()
 
+	appSpan = startSpan(ctxt, "translateDelete")
 	keys, err = (*app).translateDelete(d)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp.ErrSrc = AppErr
@@ -428,7 +530,9 @@ func Delete(req SetRequest) (SetResponse, error) {
 		return resp, err
 	}
 
+	appSpan = startSpan(ctxt, "processDelete")
 	resp, err = (*app).processDelete(d)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		d.AbortTx()
@@ -446,9 +550,23 @@ func Delete(req SetRequest) (SetResponse, error) {
 }
 
 // Get - Gets data from the redis DB and converts it to northbound format
-func Get(req GetRequest) (GetResponse, error) {
+func Get(req GetRequest) (resp GetResponse, err error) {
+	ctxt, span := startRequestSpan(req.Ctxt, "translib.Get", req.Path)
+	defer func(start time.Time) {
+		tracing.End(span, err)
+		metrics.ObserveRequest(metrics.APIGet, start, err)
+	}(time.Now())
+
+	var trace *db.RequestTrace
+	if req.Trace {
+		trace = db.NewRequestTrace()
+		defer func(start time.Time) {
+			trace.Done(start)
+			resp.Trace = trace
+		}(time.Now())
+	}
+
 	var payload []byte
-	var resp GetResponse
 	path := req.Path
 	if !isAuthorizedForGet(req) {
 		return resp, tlerr.AuthorizationError{
@@ -459,14 +577,19 @@ func Get(req GetRequest) (GetResponse, error) {
 
 	log.Info("Received Get request for path = ", path)
 
+	appSpan := startSpan(ctxt, "getAppModule")
 	app, appInfo, err := getAppModule(path, req.ClientVersion)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp = GetResponse{Payload: payload, ErrSrc: ProtoErr}
 		return resp, err
 	}
 
-	opts := appOptions{depth: req.QueryParams.Depth, content: req.QueryParams.Content, fields: req.QueryParams.Fields, ctxt: req.Ctxt}
+	opts := appOptions{depth: req.QueryParams.Depth, content: req.QueryParams.Content, fields: req.QueryParams.Fields, ctxt: ctxt}
+	if trace != nil {
+		opts.ctxt = db.WithRequestTrace(ctxt, trace)
+	}
 	err = appInitialize(app, appInfo, path, nil, &opts, GET)
 
 	if err != nil {
@@ -474,7 +597,14 @@ func Get(req GetRequest) (GetResponse, error) {
 		return resp, err
 	}
 
-	dbs, err := getAllDbs(withWriteDisable, withForceNewRedisConnection)
+	if len(req.SessionToken) != 0 {
+		// The candidate config is shared with the writes to the session.
+		writeMutex.Lock()
+		defer writeMutex.Unlock()
+	}
+
+	dbs, err := getAllDbsForNamespace(req.Namespace, withWriteDisable,
+		withForceNewRedisConnection, withTrace(trace), withContext(ctxt))
 
 	if err != nil {
 		resp = GetResponse{Payload: payload, ErrSrc: ProtoErr}
@@ -483,21 +613,46 @@ func Get(req GetRequest) (GetResponse, error) {
 
 	defer closeAllDbs(dbs[:])
 
+	if len(req.SessionToken) != 0 {
+		var closeDB func()
+		dbs[db.ConfigDB].DeleteDB()
+		dbs[db.ConfigDB], closeDB, err = getConfigDB(req.SessionToken, req.User, false,
+			withNamespace(req.Namespace))
+		if err != nil {
+			resp = GetResponse{Payload: payload, ErrSrc: ProtoErr}
+			return resp, err
+		}
+		defer func() {
+			// The session's candidate config is not to be closed.
+			dbs[db.ConfigDB] = nil
+			closeDB()
+		}()
+	}
+
+	appSpan = startSpan(ctxt, "translateGet")
 	err = (*app).translateGet(dbs)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp = GetResponse{Payload: payload, ErrSrc: AppErr}
 		return resp, err
 	}
 
+	appSpan = startSpan(ctxt, "processGet")
 	resp, err = (*app).processGet(dbs, req.FmtType)
+	tracing.End(appSpan, err)
 
 	return resp, err
 }
 
-func Action(req ActionRequest) (ActionResponse, error) {
+func Action(req ActionRequest) (resp ActionResponse, err error) {
+	ctxt, span := startRequestSpan(req.Ctxt, "translib.Action", req.Path)
+	defer func(start time.Time) {
+		tracing.End(span, err)
+		metrics.ObserveRequest(metrics.APIAction, start, err)
+	}(time.Now())
+
 	var payload []byte
-	var resp ActionResponse
 	path := req.Path
 
 	if !isAuthorizedForAction(req) {
@@ -509,7 +664,9 @@ func Action(req ActionRequest) (ActionResponse, error) {
 
 	log.Info("Received Action request for path = ", path)
 
+	appSpan := startSpan(ctxt, "getAppModule")
 	app, appInfo, err := getAppModule(path, req.ClientVersion)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp = ActionResponse{Payload: payload, ErrSrc: ProtoErr}
@@ -530,7 +687,8 @@ func Action(req ActionRequest) (ActionResponse, error) {
 	writeMutex.Lock()
 	defer writeMutex.Unlock()
 
-	dbs, err := getAllDbs(withForceNewRedisConnection)
+	dbs, err := getAllDbsForNamespace(req.Namespace, withForceNewRedisConnection,
+		withUser(req.User.Name), withContext(ctxt))
 
 	if err != nil {
 		resp = ActionResponse{Payload: payload, ErrSrc: ProtoErr}
@@ -539,14 +697,18 @@ func Action(req ActionRequest) (ActionResponse, error) {
 
 	defer closeAllDbs(dbs[:])
 
+	appSpan = startSpan(ctxt, "translateAction")
 	err = (*app).translateAction(dbs)
+	tracing.End(appSpan, err)
 
 	if err != nil {
 		resp = ActionResponse{Payload: payload, ErrSrc: AppErr}
 		return resp, err
 	}
 
+	appSpan = startSpan(ctxt, "processAction")
 	resp, err = (*app).processAction(dbs)
+	tracing.End(appSpan, err)
 
 	return resp, err
 }
@@ -554,13 +716,18 @@ func Action(req ActionRequest) (ActionResponse, error) {
 // Bulk - BULK Request API for northbounds
 // Processes the request in received order
 // Transaction based
-func Bulk(req BulkRequest) (BulkResponse, error) {
-	var err error
+func Bulk(req BulkRequest) (resp BulkResponse, err error) {
+	ctxt, span := startRequestSpan(req.Ctxt, "translib.Bulk", "")
+	defer func(start time.Time) {
+		tracing.End(span, err)
+		metrics.ObserveRequest(metrics.APIBulk, start, err)
+	}(time.Now())
+
 	var keys []db.WatchKeys
 	var errSrc ErrSource
 	var appResp SetResponse
 
-	resp := BulkResponse{}
+	resp = BulkResponse{}
 
 	if !isAuthorizedForBulk(req) {
 		return resp, tlerr.AuthorizationError{
@@ -571,13 +738,15 @@ func Bulk(req BulkRequest) (BulkResponse, error) {
 	writeMutex.Lock()
 	defer writeMutex.Unlock()
 
-	d, err := db.NewDB(getDBOptions(db.ConfigDB, withForceNewRedisConnection))
+	d, closeDB, err := getConfigDB(req.SessionToken, req.User, true,
+		withForceNewRedisConnection, withUser(req.User.Name), withContext(ctxt),
+		withNamespace(req.Namespace))
 
 	if err != nil {
 		return resp, err
 	}
 
-	defer d.DeleteDB()
+	defer closeDB()
 
 	//Start the transaction without any keys or tables to watch will be added later using AppendWatchTx
 	err = d.StartTx(nil, nil)
@@ -757,6 +926,63 @@ func getAllDbs(opts ...func(*db.Options)) ([db.MaxDB]*db.DB, error) {
 	return dbs, err
 }
 
+// getConfigDB opens the CONFIG_DB with the opts, or returns the candidate
+// config of the Config Session of the token, if not empty. The session must
+// be owned by the user, and be editable forWrite. The Config Sessions are
+// only of the default namespace. The returned func closes the DB.
+func getConfigDB(token string, user UserRoles, forWrite bool,
+	opts ...func(*db.Options)) (*db.DB, func(), error) {
+	dbOpts := getDBOptions(db.ConfigDB, opts...)
+	if len(token) == 0 {
+		d, err := db.NewDB(dbOpts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return d, func() { d.DeleteDB() }, nil
+	}
+
+	if dbOpts.Namespace != db.DefaultNamespace {
+		log.Warningf("getConfigDB: Session %s: Namespace %s", token,
+			dbOpts.Namespace)
+		return nil, nil, tlerr.NotSupported(
+			"Config Session in the namespace %s", dbOpts.Namespace)
+	}
+
+	sess, err := cs.GetSession("", token, user.Name, user.Roles, 0)
+	if err != nil {
+		log.Warningf("getConfigDB: Session %s: %v", token, err)
+		return nil, nil, err
+	}
+
+	if !sess.IsConfigSession() {
+		log.Warningf("getConfigDB: Session %s: Not found", token)
+		return nil, nil, cs.CsStatusInvalidSession{Tag: cs.ErrTagTokenNotFound}
+	}
+
+	if sess.Username() != user.Name {
+		log.Warningf("getConfigDB: Session %s: User %s != %s", token,
+			user.Name, sess.Username())
+		return nil, nil, cs.CsStatusInvalidSession{Tag: cs.ErrTagInvalidUser}
+	}
+
+	if forWrite && !sess.IsEditable() {
+		log.Warningf("getConfigDB: Session %s: %s", token, sess.GetState())
+		return nil, nil, cs.CsStatusInvalidSession{Tag: cs.ErrTagInvalidState}
+	}
+
+	d, _, closeDB, err := sess.GetConfigDB(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, closeDB, nil
+}
+
+// getAllDbsForNamespace opens all the DBs of the namespace (See
+// db.GetNamespaces()).
+func getAllDbsForNamespace(ns string, opts ...func(*db.Options)) ([db.MaxDB]*db.DB, error) {
+	return getAllDbs(append(opts, withNamespace(ns))...)
+}
+
 // Closes the dbs, and nils out the arr.
 func closeAllDbs(dbs []*db.DB) {
 	for dbsi, d := range dbs {
@@ -805,6 +1031,30 @@ func withForceNewRedisConnection(o *db.Options) {
 	o.ForceNewRedisConnection = true
 }
 
+func withUser(name string) func(*db.Options) {
+	return func(o *db.Options) {
+		o.User = name
+	}
+}
+
+func withTrace(trace *db.RequestTrace) func(*db.Options) {
+	return func(o *db.Options) {
+		o.Trace = trace
+	}
+}
+
+func withContext(ctxt context.Context) func(*db.Options) {
+	return func(o *db.Options) {
+		o.Ctxt = ctxt
+	}
+}
+
+func withNamespace(ns string) func(*db.Options) {
+	return func(o *db.Options) {
+		o.Namespace = ns
+	}
+}
+
 func getAppModule(path string, clientVer Version) (*appInterface, *appInfo, error) {
 	var app appInterface
 
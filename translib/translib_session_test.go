@@ -0,0 +1,80 @@
+////////////////////////////////////////////////////////////////////////////////
+//                                                                            //
+//  Copyright 2023 Broadcom. The term Broadcom refers to Broadcom Inc. and/or //
+//  its subsidiaries.                                                         //
+//                                                                            //
+//  Licensed under the Apache License, Version 2.0 (the "License");           //
+//  you may not use this file except in compliance with the License.          //
+//  You may obtain a copy of the License at                                   //
+//                                                                            //
+//     http://www.apache.org/licenses/LICENSE-2.0                             //
+//                                                                            //
+//  Unless required by applicable law or agreed to in writing, software       //
+//  distributed under the License is distributed on an "AS IS" BASIS,         //
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.  //
+//  See the License for the specific language governing permissions and       //
+//  limitations under the License.                                            //
+//                                                                            //
+////////////////////////////////////////////////////////////////////////////////
+
+package translib
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Azure/sonic-mgmt-common/translib/cs"
+	"github.com/Azure/sonic-mgmt-common/translib/tlerr"
+)
+
+func Test_getConfigDBSession(t *testing.T) {
+	user := UserRoles{Name: "sonicbld", Roles: []string{"admin"}}
+	pid := int32(os.Getpid())
+
+	sess, err := cs.GetSession("", "", user.Name, user.Roles, pid, cs.GSOstrict{}, cs.GSOname{})
+	if err != nil {
+		t.Fatalf("GetSession() fails: %v", err)
+	}
+	token, success, status := sess.StartOrResume(pid)
+	if !success {
+		t.Fatalf("StartOrResume() fails: %v", status)
+	}
+	t.Cleanup(func() {
+		if sess, err := cs.GetSession("", token, user.Name, user.Roles, pid); err == nil {
+			sess.Abort()
+		}
+	})
+
+	d, closeDB, err := getConfigDB("", user, true)
+	if err != nil {
+		t.Fatalf("getConfigDB() fails: %v", err)
+	}
+	if d.Opts.IsSession {
+		t.Errorf("getConfigDB() without token returns the candidate config")
+	}
+	closeDB()
+
+	d, closeDB, err = getConfigDB(token, user, true)
+	if err != nil {
+		t.Fatalf("getConfigDB(%s) fails: %v", token, err)
+	}
+	if !d.Opts.IsSession {
+		t.Errorf("getConfigDB(%s) returns the running config", token)
+	}
+	closeDB()
+
+	_, _, err = getConfigDB(token, UserRoles{Name: "someone"}, false)
+	if _, isInvalid := err.(cs.CsStatusInvalidSession); !isInvalid {
+		t.Errorf("getConfigDB(%s) of another user: %v", token, err)
+	}
+
+	_, _, err = getConfigDB(token, user, false, withNamespace("asic0"))
+	if _, notSupported := err.(tlerr.NotSupportedError); !notSupported {
+		t.Errorf("getConfigDB(%s) in namespace asic0: %v", token, err)
+	}
+
+	_, _, err = getConfigDB("0-0", user, false)
+	if _, isInvalid := err.(cs.CsStatusInvalidSession); !isInvalid {
+		t.Errorf("getConfigDB(0-0): %v", err)
+	}
+}